@@ -84,6 +84,8 @@ type SubmarinerStatus struct {
 	RouteAgentDaemonSetStatus DaemonSetStatus         `json:"routeAgentDaemonSetStatus,omitempty"`
 	GlobalnetDaemonSetStatus  DaemonSetStatus         `json:"globalnetDaemonSetStatus,omitempty"`
 	Gateways                  *[]submv1.GatewayStatus `json:"gateways,omitempty"`
+	// The generation of the Submariner resource last processed by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make manifests" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
@@ -137,6 +139,7 @@ type BrokerSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	BrokerNamespace             string   `json:"brokerNamespace,omitempty"`
 	Components                  []string `json:"components,omitempty"`
 	DefaultCustomDomains        []string `json:"defaultCustomDomains,omitempty"`
 	GlobalnetCIDRRange          string   `json:"globalnetCIDRRange,omitempty"`
@@ -84,9 +84,14 @@ func (r *BrokerReconciler) Reconcile(ctx context.Context, request ctrl.Request)
 		return ctrl.Result{}, err
 	}
 
+	brokerNamespace := instance.Spec.BrokerNamespace
+	if brokerNamespace == "" {
+		brokerNamespace = broker.SubmarinerBrokerNamespace
+	}
+
 	// Globalnet
 	err = broker.CreateGlobalnetConfigMap(r.Config, instance.Spec.GlobalnetEnabled, instance.Spec.GlobalnetCIDRRange,
-		instance.Spec.DefaultGlobalnetClusterSize, broker.SubmarinerBrokerNamespace)
+		instance.Spec.DefaultGlobalnetClusterSize, brokerNamespace, nil)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -166,6 +166,7 @@ func (r *SubmarinerReconciler) Reconcile(ctx context.Context, request reconcile.
 	instance.Status.ClusterID = instance.Spec.ClusterID
 	instance.Status.GlobalCIDR = instance.Spec.GlobalCIDR
 	instance.Status.Gateways = &gatewayStatuses
+	instance.Status.ObservedGeneration = instance.Generation
 
 	err = r.updateDaemonSetStatus(ctx, gatewayDaemonSet, &instance.Status.GatewayDaemonSetStatus, request.Namespace)
 	if err != nil {
@@ -297,6 +297,23 @@ func testReconciliation() {
 		})
 	})
 
+	When("the Submariner resource has been updated", func() {
+		BeforeEach(func() {
+			submariner.Generation = 3
+		})
+
+		It("should record the observed generation in its status", func() {
+			Expect(reconcileErr).To(Succeed())
+
+			updated := &submariner_v1.Submariner{}
+			err := fakeClient.Get(ctx, types.NamespacedName{Name: submarinerName, Namespace: submarinerNamespace}, updated)
+			Expect(err).To(Succeed())
+
+			Expect(updated.Status.ObservedGeneration).To(Equal(updated.Generation))
+			Expect(updated.Status.ObservedGeneration).To(Equal(int64(3)))
+		})
+	})
+
 	When("DaemonSet creation fails", func() {
 		BeforeEach(func() {
 			fakeClient = &failingClient{Client: newClient(), onCreate: reflect.TypeOf(&appsv1.DaemonSet{})}
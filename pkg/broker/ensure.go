@@ -38,7 +38,13 @@ import (
 	crdutils "github.com/submariner-io/submariner-operator/pkg/utils/crds"
 )
 
-func Ensure(config *rest.Config, componentArr []string, crds bool) error {
+// DefaultClientTokenWaitTimeout is the default time to wait for the token of a broker service account
+const DefaultClientTokenWaitTimeout = 2 * time.Minute
+
+// Ensure sets up the broker resources, and waits for the broker admin token to be ready, checking it every
+// waitInterval until waitTimeout expires
+func Ensure(config *rest.Config, componentArr []string, crds bool, brokerNamespace string, waitInterval,
+	waitTimeout time.Duration) error {
 	if crds {
 		crdCreator, err := crdutils.NewFromRestConfig(config)
 		if err != nil {
@@ -73,39 +79,39 @@ func Ensure(config *rest.Config, componentArr []string, crds bool) error {
 	}
 
 	// Create the namespace
-	_, err = CreateNewBrokerNamespace(clientset)
+	_, err = CreateNewBrokerNamespace(clientset, brokerNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return fmt.Errorf("error creating the broker namespace %s", err)
 	}
 
 	// Create administrator SA, Role, and bind them
-	if err := createBrokerAdministratorRoleAndSA(clientset); err != nil {
+	if err := createBrokerAdministratorRoleAndSA(clientset, brokerNamespace); err != nil {
 		return err
 	}
 
 	// Create cluster Role, and a default account for backwards compatibility, also bind it
-	if err := createBrokerClusterRoleAndDefaultSA(clientset); err != nil {
+	if err := createBrokerClusterRoleAndDefaultSA(clientset, brokerNamespace); err != nil {
 		return err
 	}
-	_, err = WaitForClientToken(clientset, SubmarinerBrokerAdminSA)
+	_, err = WaitForClientToken(clientset, SubmarinerBrokerAdminSA, brokerNamespace, waitInterval, waitTimeout)
 	return err
 }
 
-func createBrokerClusterRoleAndDefaultSA(clientset *kubernetes.Clientset) error {
+func createBrokerClusterRoleAndDefaultSA(clientset *kubernetes.Clientset, brokerNamespace string) error {
 	// Create the a default SA for cluster access (backwards compatibility with documentation)
-	_, err := CreateNewBrokerSA(clientset, submarinerBrokerClusterDefaultSA)
+	_, err := CreateNewBrokerSA(clientset, submarinerBrokerClusterDefaultSA, brokerNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return fmt.Errorf("error creating the default broker service account: %s", err)
 	}
 
 	// Create the broker cluster role, which will also be used by any new enrolled cluster
-	_, err = CreateOrUpdateClusterBrokerRole(clientset)
+	_, err = CreateOrUpdateClusterBrokerRole(clientset, brokerNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return fmt.Errorf("error creating broker role: %s", err)
 	}
 
 	// Create the role binding
-	_, err = CreateNewBrokerRoleBinding(clientset, submarinerBrokerClusterDefaultSA, submarinerBrokerClusterRole)
+	_, err = CreateNewBrokerRoleBinding(clientset, submarinerBrokerClusterDefaultSA, submarinerBrokerClusterRole, brokerNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return fmt.Errorf("error creating the broker rolebinding: %s", err)
 	}
@@ -113,40 +119,42 @@ func createBrokerClusterRoleAndDefaultSA(clientset *kubernetes.Clientset) error
 }
 
 // CreateSAForCluster creates a new SA, and binds it to the submariner cluster role
-func CreateSAForCluster(clientset *kubernetes.Clientset, clusterID string) (*v1.Secret, error) {
+// and waits for its token, checking it every waitInterval until waitTimeout expires
+func CreateSAForCluster(clientset *kubernetes.Clientset, clusterID, brokerNamespace string, waitInterval,
+	waitTimeout time.Duration) (*v1.Secret, error) {
 	saName := fmt.Sprintf(submarinerBrokerClusterSAFmt, clusterID)
-	_, err := CreateNewBrokerSA(clientset, saName)
+	_, err := CreateNewBrokerSA(clientset, saName, brokerNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return nil, fmt.Errorf("error creating cluster sa: %s", err)
 	}
 
-	_, err = CreateNewBrokerRoleBinding(clientset, saName, submarinerBrokerClusterRole)
+	_, err = CreateNewBrokerRoleBinding(clientset, saName, submarinerBrokerClusterRole, brokerNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return nil, fmt.Errorf("error binding sa to cluster role: %s", err)
 	}
 
-	clientToken, err := WaitForClientToken(clientset, saName)
+	clientToken, err := WaitForClientToken(clientset, saName, brokerNamespace, waitInterval, waitTimeout)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return nil, fmt.Errorf("error getting cluster sa token: %s", err)
 	}
 	return clientToken, nil
 }
 
-func createBrokerAdministratorRoleAndSA(clientset *kubernetes.Clientset) error {
+func createBrokerAdministratorRoleAndSA(clientset *kubernetes.Clientset, brokerNamespace string) error {
 	// Create the SA we need for the managing the broker (from subctl, etc..)
-	_, err := CreateNewBrokerSA(clientset, SubmarinerBrokerAdminSA)
+	_, err := CreateNewBrokerSA(clientset, SubmarinerBrokerAdminSA, brokerNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return fmt.Errorf("error creating the broker admin service account: %s", err)
 	}
 
 	// Create the broker admin role
-	_, err = CreateOrUpdateBrokerAdminRole(clientset)
+	_, err = CreateOrUpdateBrokerAdminRole(clientset, brokerNamespace)
 	if err != nil {
 		return fmt.Errorf("error creating subctl role: %s", err)
 	}
 
 	// Create the role binding
-	_, err = CreateNewBrokerRoleBinding(clientset, SubmarinerBrokerAdminSA, submarinerBrokerAdminRole)
+	_, err = CreateNewBrokerRoleBinding(clientset, SubmarinerBrokerAdminSA, submarinerBrokerAdminRole, brokerNamespace)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return fmt.Errorf("error creating the broker rolebinding: %s", err)
 	}
@@ -154,21 +162,13 @@ func createBrokerAdministratorRoleAndSA(clientset *kubernetes.Clientset) error {
 	return nil
 }
 
-func WaitForClientToken(clientset *kubernetes.Clientset, submarinerBrokerSA string) (secret *v1.Secret, err error) {
-	// wait for the client token to be ready, while implementing
-	// exponential backoff pattern, it will wait a total of:
-	// sum(n=0..9, 1.2^n * 5) seconds, = 130 seconds
-
-	backoff := wait.Backoff{
-		Steps:    10,
-		Duration: 5 * time.Second,
-		Factor:   1.2,
-		Jitter:   1,
-	}
-
+// WaitForClientToken waits for the token of the service account to be ready, checking it every interval until the
+// timeout expires
+func WaitForClientToken(clientset *kubernetes.Clientset, submarinerBrokerSA, brokerNamespace string, interval,
+	timeout time.Duration) (secret *v1.Secret, err error) {
 	var lastErr error
-	err = wait.ExponentialBackoff(backoff, func() (bool, error) {
-		secret, lastErr = GetClientTokenSecret(clientset, SubmarinerBrokerNamespace, submarinerBrokerSA)
+	err = wait.PollImmediate(interval, timeout, func() (bool, error) {
+		secret, lastErr = GetClientTokenSecret(clientset, brokerNamespace, submarinerBrokerSA)
 		if lastErr != nil {
 			return false, nil
 		}
@@ -181,25 +181,26 @@ func WaitForClientToken(clientset *kubernetes.Clientset, submarinerBrokerSA stri
 	return secret, err
 }
 
-func CreateNewBrokerNamespace(clientset *kubernetes.Clientset) (brokernamespace *v1.Namespace, err error) {
-	return clientset.CoreV1().Namespaces().Create(context.TODO(), NewBrokerNamespace(), metav1.CreateOptions{})
+func CreateNewBrokerNamespace(clientset *kubernetes.Clientset, namespace string) (brokernamespace *v1.Namespace, err error) {
+	return clientset.CoreV1().Namespaces().Create(context.TODO(), NewBrokerNamespace(namespace), metav1.CreateOptions{})
 }
 
-func CreateOrUpdateClusterBrokerRole(clientset *kubernetes.Clientset) (created bool, err error) {
-	return utils.CreateOrUpdateRole(context.TODO(), clientset, SubmarinerBrokerNamespace, NewBrokerClusterRole())
+func CreateOrUpdateClusterBrokerRole(clientset *kubernetes.Clientset, namespace string) (created bool, err error) {
+	return utils.CreateOrUpdateRole(context.TODO(), clientset, namespace, NewBrokerClusterRole())
 }
 
-func CreateOrUpdateBrokerAdminRole(clientset *kubernetes.Clientset) (created bool, err error) {
-	return utils.CreateOrUpdateRole(context.TODO(), clientset, SubmarinerBrokerNamespace, NewBrokerAdminRole())
+func CreateOrUpdateBrokerAdminRole(clientset *kubernetes.Clientset, namespace string) (created bool, err error) {
+	return utils.CreateOrUpdateRole(context.TODO(), clientset, namespace, NewBrokerAdminRole())
 }
 
-func CreateNewBrokerRoleBinding(clientset *kubernetes.Clientset, serviceAccount, role string) (brokerRoleBinding *rbac.RoleBinding,
-	err error) {
-	return clientset.RbacV1().RoleBindings(SubmarinerBrokerNamespace).Create(
-		context.TODO(), NewBrokerRoleBinding(serviceAccount, role), metav1.CreateOptions{})
+func CreateNewBrokerRoleBinding(clientset *kubernetes.Clientset, serviceAccount, role, namespace string) (
+	brokerRoleBinding *rbac.RoleBinding, err error) {
+	return clientset.RbacV1().RoleBindings(namespace).Create(
+		context.TODO(), NewBrokerRoleBinding(serviceAccount, role, namespace), metav1.CreateOptions{})
 }
 
-func CreateNewBrokerSA(clientset *kubernetes.Clientset, submarinerBrokerSA string) (brokerSA *v1.ServiceAccount, err error) {
-	return clientset.CoreV1().ServiceAccounts(SubmarinerBrokerNamespace).Create(
+func CreateNewBrokerSA(clientset *kubernetes.Clientset, submarinerBrokerSA, namespace string) (brokerSA *v1.ServiceAccount,
+	err error) {
+	return clientset.CoreV1().ServiceAccounts(namespace).Create(
 		context.TODO(), NewBrokerSA(submarinerBrokerSA), metav1.CreateOptions{})
 }
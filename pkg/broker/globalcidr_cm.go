@@ -21,6 +21,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -42,27 +45,64 @@ type ClusterInfo struct {
 	GlobalCidr []string `json:"global_cidr"`
 }
 
+// CreateGlobalnetConfigMap creates the globalnet config map, seeding its cluster info with the given entries; an
+// existing config map is left as is
 func CreateGlobalnetConfigMap(config *rest.Config, globalnetEnabled bool, defaultGlobalCidrRange string,
-	defaultGlobalClusterSize uint, namespace string) error {
+	defaultGlobalClusterSize uint, namespace string, clusterInfo []ClusterInfo) error {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return fmt.Errorf("error creating the core kubernetes clientset: %s", err)
 	}
 
-	gnConfigMap, err := NewGlobalnetConfigMap(globalnetEnabled, defaultGlobalCidrRange, defaultGlobalClusterSize, namespace)
+	gnConfigMap, err := NewGlobalnetConfigMap(globalnetEnabled, defaultGlobalCidrRange, defaultGlobalClusterSize, namespace,
+		clusterInfo)
 	if err != nil {
 		return fmt.Errorf("error creating config map: %s", err)
 	}
 
 	_, err = clientset.CoreV1().ConfigMaps(namespace).Create(context.TODO(), gnConfigMap, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) && len(clusterInfo) > 0 {
+		return checkSeededClusterInfo(clientset, namespace, clusterInfo)
+	}
+
 	if err == nil || errors.IsAlreadyExists(err) {
 		return nil
 	}
 	return err
 }
 
+// checkSeededClusterInfo checks that an existing globalnet config map contains the seeded cluster info entries, e.g.
+// when the broker is deployed again with the same entries
+func checkSeededClusterInfo(clientset kubernetes.Interface, namespace string, seeded []ClusterInfo) error {
+	configMap, err := GetGlobalnetConfigMap(clientset, namespace)
+	if err != nil {
+		return err
+	}
+
+	var clusterInfo []ClusterInfo
+	if err := json.Unmarshal([]byte(configMap.Data[ClusterInfoKey]), &clusterInfo); err != nil {
+		return fmt.Errorf("invalid %s: %s", ClusterInfoKey, err)
+	}
+
+	existing := map[string][]string{}
+	for _, info := range clusterInfo {
+		existing[info.ClusterID] = info.GlobalCidr
+	}
+
+	for _, info := range seeded {
+		if strings.Join(existing[info.ClusterID], ",") != strings.Join(info.GlobalCidr, ",") {
+			return fmt.Errorf("the globalnet config map already exists and allocates %v to cluster %q instead of the"+
+				" seeded %v", existing[info.ClusterID], info.ClusterID, info.GlobalCidr)
+		}
+	}
+
+	return nil
+}
+
+// NewGlobalnetConfigMap returns the globalnet config map, with its cluster info seeded with the given entries. The
+// seeded entries must be valid, non-overlapping and, with globalnet enabled, within the globalnet CIDR range.
 func NewGlobalnetConfigMap(globalnetEnabled bool, defaultGlobalCidrRange string,
-	defaultGlobalClusterSize uint, namespace string) (*v1.ConfigMap, error) {
+	defaultGlobalClusterSize uint, namespace string, clusterInfo []ClusterInfo) (*v1.ConfigMap, error) {
 	labels := map[string]string{
 		"component": "submariner-globalnet",
 	}
@@ -72,18 +112,28 @@ func NewGlobalnetConfigMap(globalnetEnabled bool, defaultGlobalCidrRange string,
 		return nil, err
 	}
 
+	seededClusterInfo := "[]"
+	if len(clusterInfo) > 0 {
+		encoded, err := json.MarshalIndent(clusterInfo, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+
+		seededClusterInfo = string(encoded)
+	}
+
 	var data map[string]string
 	if globalnetEnabled {
 		data = map[string]string{
 			GlobalnetStatusKey:   "true",
 			GlobalnetCidrRange:   string(cidrRange),
 			GlobalnetClusterSize: fmt.Sprint(defaultGlobalClusterSize),
-			ClusterInfoKey:       "[]",
+			ClusterInfoKey:       seededClusterInfo,
 		}
 	} else {
 		data = map[string]string{
 			GlobalnetStatusKey: "false",
-			ClusterInfoKey:     "[]",
+			ClusterInfoKey:     seededClusterInfo,
 		}
 	}
 
@@ -95,6 +145,17 @@ func NewGlobalnetConfigMap(globalnetEnabled bool, defaultGlobalCidrRange string,
 		},
 		Data: data,
 	}
+
+	if len(clusterInfo) > 0 {
+		problems := ValidateGlobalnetConfigMap(cm)
+		allocationProblems, overlaps, _ := ValidateGlobalnetAllocations(cm)
+		problems = append(problems, allocationProblems...)
+
+		if problems = append(problems, overlaps...); len(problems) > 0 {
+			return nil, fmt.Errorf("invalid seeded cluster info: %s", problems[0])
+		}
+	}
+
 	return cm, nil
 }
 
@@ -103,9 +164,11 @@ func UpdateGlobalnetConfigMap(k8sClientset *kubernetes.Clientset, namespace stri
 	var clusterInfo []ClusterInfo
 	err := json.Unmarshal([]byte(configMap.Data[ClusterInfoKey]), &clusterInfo)
 	if err != nil {
-		return err
+		return invalidJSONError(ClusterInfoKey, configMap.Data[ClusterInfoKey], err)
 	}
 
+	clusterInfo, _ = deduplicateClusterInfo(clusterInfo)
+
 	exists := false
 	for k, value := range clusterInfo {
 		if value.ClusterID == newCluster.ClusterID {
@@ -131,10 +194,306 @@ func UpdateGlobalnetConfigMap(k8sClientset *kubernetes.Clientset, namespace stri
 	return err
 }
 
-func GetGlobalnetConfigMap(k8sClientset *kubernetes.Clientset, namespace string) (*v1.ConfigMap, error) {
+// GetGlobalnetConfigMap retrieves the globalnet config map, migrating its legacy encodings and removing the duplicate
+// cluster info entries on the broker
+func GetGlobalnetConfigMap(k8sClientset kubernetes.Interface, namespace string) (*v1.ConfigMap, error) {
 	cm, err := k8sClientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), GlobalCIDRConfigMapName, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
+
+	migrated, err := MigrateGlobalnetConfigMap(cm)
+	if err != nil {
+		return nil, fmt.Errorf("error migrating the globalnet config map: %s", err)
+	}
+
+	duplicates, err := DeduplicateGlobalnetConfigMap(cm)
+	if err != nil {
+		return nil, fmt.Errorf("error deduplicating the globalnet config map: %s", err)
+	}
+
+	if migrated || len(duplicates) > 0 {
+		return k8sClientset.CoreV1().ConfigMaps(namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	}
+
 	return cm, nil
 }
+
+// ReadGlobalnetConfigMap retrieves the globalnet config map as stored, without migrating it, for the callers which
+// mustn't modify the broker
+func ReadGlobalnetConfigMap(k8sClientset kubernetes.Interface, namespace string) (*v1.ConfigMap, error) {
+	return k8sClientset.CoreV1().ConfigMaps(namespace).Get(context.TODO(), GlobalCIDRConfigMapName, metav1.GetOptions{})
+}
+
+// ValidateGlobalnetConfigMap returns the problems found in the contents of the globalnet config map
+func ValidateGlobalnetConfigMap(configMap *v1.ConfigMap) []error {
+	problems := []error{}
+
+	globalnetEnabled, err := strconv.ParseBool(configMap.Data[GlobalnetStatusKey])
+	if err != nil {
+		problems = append(problems, fmt.Errorf("invalid %s %q: %s", GlobalnetStatusKey, configMap.Data[GlobalnetStatusKey], err))
+	}
+
+	var clusterInfo []ClusterInfo
+	if strings.TrimSpace(configMap.Data[ClusterInfoKey]) == "" {
+		problems = append(problems, fmt.Errorf("%s is missing or empty", ClusterInfoKey))
+	} else if err := json.Unmarshal([]byte(configMap.Data[ClusterInfoKey]), &clusterInfo); err != nil {
+		problems = append(problems, invalidJSONError(ClusterInfoKey, configMap.Data[ClusterInfoKey], err))
+	}
+
+	for _, clusterID := range duplicateClusterIDs(clusterInfo) {
+		problems = append(problems, fmt.Errorf("cluster %q has more than one entry in %s", clusterID, ClusterInfoKey))
+	}
+
+	for _, info := range clusterInfo {
+		for _, globalCIDR := range info.GlobalCidr {
+			if _, _, err := net.ParseCIDR(globalCIDR); err != nil {
+				problems = append(problems, fmt.Errorf("invalid global CIDR %q for cluster %q: %s", globalCIDR, info.ClusterID, err))
+			}
+		}
+	}
+
+	if !globalnetEnabled {
+		return problems
+	}
+
+	var cidrRange string
+	if strings.TrimSpace(configMap.Data[GlobalnetCidrRange]) == "" {
+		problems = append(problems, fmt.Errorf("%s is missing or empty", GlobalnetCidrRange))
+	} else if err := json.Unmarshal([]byte(configMap.Data[GlobalnetCidrRange]), &cidrRange); err != nil {
+		problems = append(problems, invalidJSONError(GlobalnetCidrRange, configMap.Data[GlobalnetCidrRange], err))
+	} else if _, _, err := net.ParseCIDR(cidrRange); err != nil {
+		problems = append(problems, fmt.Errorf("invalid %s %q: %s", GlobalnetCidrRange, cidrRange, err))
+	}
+
+	if _, err := strconv.ParseUint(configMap.Data[GlobalnetClusterSize], 10, 0); err != nil {
+		problems = append(problems, fmt.Errorf("invalid %s %q: %s", GlobalnetClusterSize, configMap.Data[GlobalnetClusterSize], err))
+	}
+
+	return problems
+}
+
+// The number of bytes shown on each side of the location of a JSON decoding error
+const jsonErrorContext = 20
+
+// invalidJSONError describes the error decoding the JSON stored under the key of the config map; syntax and type
+// errors are located by line and column, with an excerpt of the data around them
+func invalidJSONError(key, data string, err error) error {
+	var offset int64
+
+	switch jsonErr := err.(type) {
+	case *json.SyntaxError:
+		offset = jsonErr.Offset
+	case *json.UnmarshalTypeError:
+		offset = jsonErr.Offset
+	default:
+		return fmt.Errorf("invalid %s: %s", key, err)
+	}
+
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	before := data[:offset]
+	line := strings.Count(before, "\n") + 1
+	column := len(before) - strings.LastIndex(before, "\n") - 1
+
+	start := offset - jsonErrorContext
+	if start < 0 {
+		start = 0
+	}
+
+	end := offset + jsonErrorContext
+	if end > int64(len(data)) {
+		end = int64(len(data))
+	}
+
+	return fmt.Errorf("invalid %s: %s at line %d, column %d, near %q", key, err, line, column, data[start:end])
+}
+
+// ValidateGlobalnetAllocations checks the global CIDRs allocated to the clusters against the current globalnet CIDR
+// range and cluster size, and returns the allocations which are misaligned or outside the range, and separately those
+// which overlap each other. Since clusters can request their own size when joining, allocations whose size differs
+// from the current cluster size are returned as warnings. Malformed entries are ignored, they are reported by
+// ValidateGlobalnetConfigMap.
+func ValidateGlobalnetAllocations(configMap *v1.ConfigMap) (problems, overlaps, warnings []error) {
+	if enabled, err := strconv.ParseBool(configMap.Data[GlobalnetStatusKey]); err != nil || !enabled {
+		return nil, nil, nil
+	}
+
+	var cidrRange string
+	if err := json.Unmarshal([]byte(configMap.Data[GlobalnetCidrRange]), &cidrRange); err != nil {
+		return nil, nil, nil
+	}
+
+	_, globalRange, err := net.ParseCIDR(cidrRange)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	// Globalnet only allocates IPv4 addresses, the sizes below are only computed for IPv4 CIDRs
+	if globalRange.IP.To4() == nil {
+		return []error{fmt.Errorf("the globalnet CIDR range %q isn't an IPv4 CIDR", cidrRange)}, nil, nil
+	}
+
+	clusterSize, err := strconv.ParseUint(configMap.Data[GlobalnetClusterSize], 10, 0)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	var clusterInfo []ClusterInfo
+	if err := json.Unmarshal([]byte(configMap.Data[ClusterInfoKey]), &clusterInfo); err != nil {
+		return nil, nil, nil
+	}
+
+	type allocation struct {
+		clusterID string
+		network   *net.IPNet
+	}
+
+	allocations := []allocation{}
+	rangeOnes, _ := globalRange.Mask.Size()
+
+	for _, info := range clusterInfo {
+		for _, globalCIDR := range info.GlobalCidr {
+			ip, network, err := net.ParseCIDR(globalCIDR)
+			if err != nil {
+				continue
+			}
+
+			if network.IP.To4() == nil {
+				problems = append(problems, fmt.Errorf("the global CIDR %q of cluster %q isn't an IPv4 CIDR", globalCIDR,
+					info.ClusterID))
+				continue
+			}
+
+			ones, bits := network.Mask.Size()
+
+			if !ip.Equal(network.IP) {
+				problems = append(problems, fmt.Errorf("the global CIDR %q of cluster %q isn't aligned on its prefix length",
+					globalCIDR, info.ClusterID))
+			}
+
+			if ones < rangeOnes || !globalRange.Contains(network.IP) {
+				problems = append(problems, fmt.Errorf("the global CIDR %q of cluster %q is outside the globalnet CIDR range %q",
+					globalCIDR, info.ClusterID, cidrRange))
+			}
+
+			if size := uint64(1) << uint(bits-ones); size != clusterSize {
+				warnings = append(warnings, fmt.Errorf("the global CIDR %q of cluster %q has %d addresses but the globalnet"+
+					" cluster size is %d", globalCIDR, info.ClusterID, size, clusterSize))
+			}
+
+			for _, other := range allocations {
+				if other.network.Contains(network.IP) || network.Contains(other.network.IP) {
+					overlaps = append(overlaps, fmt.Errorf("the global CIDR %q of cluster %q overlaps the global CIDR %q of"+
+						" cluster %q", globalCIDR, info.ClusterID, other.network, other.clusterID))
+				}
+			}
+
+			allocations = append(allocations, allocation{clusterID: info.ClusterID, network: network})
+		}
+	}
+
+	return problems, overlaps, warnings
+}
+
+// DeduplicateGlobalnetConfigMap removes the entries of clusters which appear more than once in the cluster info,
+// keeping the first entry of each cluster. It returns the IDs of the deduplicated clusters; the config map is only
+// modified if there are any.
+func DeduplicateGlobalnetConfigMap(configMap *v1.ConfigMap) ([]string, error) {
+	if strings.TrimSpace(configMap.Data[ClusterInfoKey]) == "" {
+		return nil, nil
+	}
+
+	var clusterInfo []ClusterInfo
+	if err := json.Unmarshal([]byte(configMap.Data[ClusterInfoKey]), &clusterInfo); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", ClusterInfoKey, err)
+	}
+
+	clusterInfo, duplicates := deduplicateClusterInfo(clusterInfo)
+	if len(duplicates) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.MarshalIndent(clusterInfo, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	configMap.Data[ClusterInfoKey] = string(data)
+	return duplicates, nil
+}
+
+// deduplicateClusterInfo returns the cluster info keeping only the first entry of each cluster, and the IDs of the
+// clusters which had more than one
+func deduplicateClusterInfo(clusterInfo []ClusterInfo) ([]ClusterInfo, []string) {
+	duplicates := duplicateClusterIDs(clusterInfo)
+	if len(duplicates) == 0 {
+		return clusterInfo, nil
+	}
+
+	seen := map[string]bool{}
+	deduplicated := []ClusterInfo{}
+
+	for _, info := range clusterInfo {
+		if !seen[info.ClusterID] {
+			seen[info.ClusterID] = true
+			deduplicated = append(deduplicated, info)
+		}
+	}
+
+	return deduplicated, duplicates
+}
+
+// duplicateClusterIDs returns the IDs of the clusters with more than one entry, in order of appearance
+func duplicateClusterIDs(clusterInfo []ClusterInfo) []string {
+	entries := map[string]int{}
+	duplicates := []string{}
+
+	for _, info := range clusterInfo {
+		entries[info.ClusterID]++
+		if entries[info.ClusterID] == 2 {
+			duplicates = append(duplicates, info.ClusterID)
+		}
+	}
+
+	return duplicates
+}
+
+// DeleteGlobalnetConfigMap deletes the globalnet config map; a missing config map isn't an error
+func DeleteGlobalnetConfigMap(k8sClientset kubernetes.Interface, namespace string) error {
+	err := k8sClientset.CoreV1().ConfigMaps(namespace).Delete(context.TODO(), GlobalCIDRConfigMapName, metav1.DeleteOptions{})
+	if err == nil || errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// MigrateGlobalnetConfigMap rewrites entries stored by older versions using a legacy encoding into
+// their canonical (JSON-marshaled) form. It returns true if the config map was modified.
+func MigrateGlobalnetConfigMap(configMap *v1.ConfigMap) (bool, error) {
+	cidrRange, found := configMap.Data[GlobalnetCidrRange]
+	if !found || cidrRange == "" {
+		return false, nil
+	}
+
+	var decoded string
+	if err := json.Unmarshal([]byte(cidrRange), &decoded); err == nil {
+		return false, nil
+	}
+
+	// Older versions stored the range as a bare string
+	cidrRange = strings.TrimSpace(cidrRange)
+	if _, _, err := net.ParseCIDR(cidrRange); err != nil {
+		return false, fmt.Errorf("invalid %s %q: %s", GlobalnetCidrRange, cidrRange, err)
+	}
+
+	encoded, err := json.Marshal(cidrRange)
+	if err != nil {
+		return false, err
+	}
+
+	configMap.Data[GlobalnetCidrRange] = string(encoded)
+	return true, nil
+}
@@ -0,0 +1,411 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broker
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testBrokerNamespace = "submariner-k8s-broker"
+
+var _ = Describe("Globalnet ConfigMap migration", func() {
+	var configMap *v1.ConfigMap
+
+	BeforeEach(func() {
+		var err error
+		configMap, err = NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("the globalnet CIDR range uses the canonical encoding", func() {
+		It("should not modify the ConfigMap", func() {
+			migrated, err := MigrateGlobalnetConfigMap(configMap)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(migrated).To(BeFalse())
+			Expect(configMap.Data[GlobalnetCidrRange]).To(Equal(`"169.254.0.0/16"`))
+		})
+	})
+
+	When("the globalnet CIDR range uses the legacy encoding", func() {
+		BeforeEach(func() {
+			configMap.Data[GlobalnetCidrRange] = "169.254.0.0/16"
+		})
+
+		It("should rewrite it in the canonical encoding", func() {
+			migrated, err := MigrateGlobalnetConfigMap(configMap)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(migrated).To(BeTrue())
+			Expect(configMap.Data[GlobalnetCidrRange]).To(Equal(`"169.254.0.0/16"`))
+		})
+
+		It("should be idempotent", func() {
+			_, err := MigrateGlobalnetConfigMap(configMap)
+			Expect(err).ToNot(HaveOccurred())
+
+			migrated, err := MigrateGlobalnetConfigMap(configMap)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(migrated).To(BeFalse())
+			Expect(configMap.Data[GlobalnetCidrRange]).To(Equal(`"169.254.0.0/16"`))
+		})
+
+		It("should be migrated on read", func() {
+			clientSet := fake.NewSimpleClientset(configMap)
+
+			cm, err := GetGlobalnetConfigMap(clientSet, testBrokerNamespace)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cm.Data[GlobalnetCidrRange]).To(Equal(`"169.254.0.0/16"`))
+
+			stored, err := clientSet.CoreV1().ConfigMaps(testBrokerNamespace).Get(context.TODO(),
+				GlobalCIDRConfigMapName, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored.Data[GlobalnetCidrRange]).To(Equal(`"169.254.0.0/16"`))
+		})
+
+		It("should not be migrated on a read-only read", func() {
+			clientSet := fake.NewSimpleClientset(configMap)
+
+			cm, err := ReadGlobalnetConfigMap(clientSet, testBrokerNamespace)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cm.Data[GlobalnetCidrRange]).To(Equal("169.254.0.0/16"))
+			Expect(clientSet.Actions()).To(HaveLen(1))
+			Expect(clientSet.Actions()[0].GetVerb()).To(Equal("get"))
+		})
+	})
+
+	When("the legacy globalnet CIDR range is invalid", func() {
+		It("should return an error", func() {
+			configMap.Data[GlobalnetCidrRange] = "not-a-cidr"
+			_, err := MigrateGlobalnetConfigMap(configMap)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Globalnet ConfigMap deletion", func() {
+	When("the ConfigMap exists", func() {
+		It("should delete it", func() {
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			clientSet := fake.NewSimpleClientset(configMap)
+			Expect(DeleteGlobalnetConfigMap(clientSet, testBrokerNamespace)).To(Succeed())
+
+			_, err = clientSet.CoreV1().ConfigMaps(testBrokerNamespace).Get(context.TODO(),
+				GlobalCIDRConfigMapName, metav1.GetOptions{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	When("the ConfigMap doesn't exist", func() {
+		It("should succeed", func() {
+			Expect(DeleteGlobalnetConfigMap(fake.NewSimpleClientset(), testBrokerNamespace)).To(Succeed())
+		})
+	})
+})
+
+var _ = Describe("Globalnet ConfigMap seeding", func() {
+	When("the seeded cluster info is valid", func() {
+		It("should store it in the ConfigMap", func() {
+			seeded := []ClusterInfo{
+				{ClusterID: "east", GlobalCidr: []string{"169.254.0.0/19"}},
+				{ClusterID: "west", GlobalCidr: []string{"169.254.32.0/19"}},
+			}
+
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, seeded)
+			Expect(err).ToNot(HaveOccurred())
+
+			var clusterInfo []ClusterInfo
+			Expect(json.Unmarshal([]byte(configMap.Data[ClusterInfoKey]), &clusterInfo)).To(Succeed())
+			Expect(clusterInfo).To(Equal(seeded))
+		})
+	})
+
+	When("a seeded global CIDR is outside the globalnet CIDR range", func() {
+		It("should return an error", func() {
+			_, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace,
+				[]ClusterInfo{{ClusterID: "east", GlobalCidr: []string{"10.0.0.0/19"}}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("outside the globalnet CIDR range"))
+		})
+	})
+
+	When("seeded global CIDRs overlap", func() {
+		It("should return an error", func() {
+			_, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, []ClusterInfo{
+				{ClusterID: "east", GlobalCidr: []string{"169.254.0.0/19"}},
+				{ClusterID: "west", GlobalCidr: []string{"169.254.0.0/19"}},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("overlaps"))
+		})
+	})
+
+	When("the ConfigMap already exists", func() {
+		var clientSet *fake.Clientset
+
+		BeforeEach(func() {
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace,
+				[]ClusterInfo{{ClusterID: "east", GlobalCidr: []string{"169.254.0.0/19"}}})
+			Expect(err).ToNot(HaveOccurred())
+
+			clientSet = fake.NewSimpleClientset(configMap)
+		})
+
+		It("should accept the same seeded entries", func() {
+			Expect(checkSeededClusterInfo(clientSet, testBrokerNamespace,
+				[]ClusterInfo{{ClusterID: "east", GlobalCidr: []string{"169.254.0.0/19"}}})).To(Succeed())
+		})
+
+		It("should reject different seeded entries", func() {
+			Expect(checkSeededClusterInfo(clientSet, testBrokerNamespace,
+				[]ClusterInfo{{ClusterID: "east", GlobalCidr: []string{"169.254.32.0/19"}}})).ToNot(Succeed())
+		})
+	})
+})
+
+var _ = Describe("Globalnet ConfigMap validation", func() {
+	When("the ConfigMap is well-formed", func() {
+		It("should report no problems", func() {
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ValidateGlobalnetConfigMap(configMap)).To(BeEmpty())
+
+			configMap, err = NewGlobalnetConfigMap(false, "", 0, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ValidateGlobalnetConfigMap(configMap)).To(BeEmpty())
+		})
+	})
+
+	When("the ConfigMap has invalid entries", func() {
+		It("should report each of them", func() {
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			configMap.Data[GlobalnetCidrRange] = `"not-a-cidr"`
+			configMap.Data[GlobalnetClusterSize] = "lots"
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["169.254.300.0/24"]}]`
+
+			Expect(ValidateGlobalnetConfigMap(configMap)).To(HaveLen(3))
+		})
+	})
+
+	When("the cluster info is truncated", func() {
+		It("should report the location of the error", func() {
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			configMap.Data[ClusterInfoKey] = "[\n\t{\"cluster_id\": \"east\",\n\t\"global_cidr\": [\"169.254.0.0/19\""
+
+			problems := ValidateGlobalnetConfigMap(configMap)
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Error()).To(ContainSubstring(ClusterInfoKey))
+			Expect(problems[0].Error()).To(ContainSubstring("line 3, column 33"))
+			Expect(problems[0].Error()).To(ContainSubstring(`169.254.0.0/19`))
+		})
+	})
+
+	When("the cluster info has the wrong type", func() {
+		It("should report the location of the error", func() {
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":"169.254.0.0/19"}]`
+
+			problems := ValidateGlobalnetConfigMap(configMap)
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Error()).To(ContainSubstring("line 1, column"))
+		})
+	})
+
+	When("the globalnet CIDR range is missing", func() {
+		It("should report it", func() {
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			delete(configMap.Data, GlobalnetCidrRange)
+
+			problems := ValidateGlobalnetConfigMap(configMap)
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Error()).To(ContainSubstring(GlobalnetCidrRange + " is missing"))
+		})
+	})
+
+	When("a cluster has more than one entry", func() {
+		It("should report the duplicated cluster", func() {
+			configMap, err := NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["169.254.0.0/19"]},` +
+				`{"cluster_id":"east","global_cidr":["169.254.32.0/19"]}]`
+
+			problems := ValidateGlobalnetConfigMap(configMap)
+			Expect(problems).To(HaveLen(1))
+			Expect(problems[0].Error()).To(ContainSubstring(`"east"`))
+		})
+	})
+})
+
+var _ = Describe("Globalnet ConfigMap deduplication", func() {
+	var configMap *v1.ConfigMap
+
+	BeforeEach(func() {
+		var err error
+		configMap, err = NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("a cluster has more than one entry", func() {
+		It("should keep its first entry and return its ID", func() {
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["169.254.0.0/19"]},` +
+				`{"cluster_id":"west","global_cidr":["169.254.32.0/19"]},` +
+				`{"cluster_id":"east","global_cidr":["169.254.64.0/19"]}]`
+
+			duplicates, err := DeduplicateGlobalnetConfigMap(configMap)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(duplicates).To(Equal([]string{"east"}))
+
+			var clusterInfo []ClusterInfo
+			Expect(json.Unmarshal([]byte(configMap.Data[ClusterInfoKey]), &clusterInfo)).To(Succeed())
+			Expect(clusterInfo).To(Equal([]ClusterInfo{
+				{ClusterID: "east", GlobalCidr: []string{"169.254.0.0/19"}},
+				{ClusterID: "west", GlobalCidr: []string{"169.254.32.0/19"}},
+			}))
+			Expect(ValidateGlobalnetConfigMap(configMap)).To(BeEmpty())
+		})
+	})
+
+	When("no cluster has more than one entry", func() {
+		It("should not modify the ConfigMap", func() {
+			clusterInfo := `[{"cluster_id":"east","global_cidr":["169.254.0.0/19"]}]`
+			configMap.Data[ClusterInfoKey] = clusterInfo
+
+			duplicates, err := DeduplicateGlobalnetConfigMap(configMap)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(duplicates).To(BeEmpty())
+			Expect(configMap.Data[ClusterInfoKey]).To(Equal(clusterInfo))
+		})
+	})
+
+	When("the ConfigMap with duplicate entries is read from the broker", func() {
+		It("should store it deduplicated", func() {
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["169.254.0.0/19"]},` +
+				`{"cluster_id":"east","global_cidr":["169.254.64.0/19"]}]`
+			clientSet := fake.NewSimpleClientset(configMap)
+
+			_, err := GetGlobalnetConfigMap(clientSet, testBrokerNamespace)
+			Expect(err).ToNot(HaveOccurred())
+
+			stored, err := clientSet.CoreV1().ConfigMaps(testBrokerNamespace).Get(context.TODO(),
+				GlobalCIDRConfigMapName, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			var clusterInfo []ClusterInfo
+			Expect(json.Unmarshal([]byte(stored.Data[ClusterInfoKey]), &clusterInfo)).To(Succeed())
+			Expect(clusterInfo).To(Equal([]ClusterInfo{{ClusterID: "east", GlobalCidr: []string{"169.254.0.0/19"}}}))
+		})
+	})
+})
+
+var _ = Describe("Globalnet allocations validation", func() {
+	var configMap *v1.ConfigMap
+
+	BeforeEach(func() {
+		var err error
+		configMap, err = NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("the allocations match the current range and cluster size", func() {
+		It("should report no problems", func() {
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["169.254.0.0/19"]},` +
+				`{"cluster_id":"west","global_cidr":["169.254.32.0/19"]}]`
+
+			problems, overlaps, warnings := ValidateGlobalnetAllocations(configMap)
+			Expect(problems).To(BeEmpty())
+			Expect(overlaps).To(BeEmpty())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	When("an allocation no longer matches the cluster size", func() {
+		It("should report a warning", func() {
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["169.254.0.0/20"]}]`
+
+			problems, overlaps, warnings := ValidateGlobalnetAllocations(configMap)
+			Expect(problems).To(BeEmpty())
+			Expect(overlaps).To(BeEmpty())
+			Expect(warnings).To(HaveLen(1))
+		})
+	})
+
+	When("allocations are misaligned, outside the range or overlapping", func() {
+		It("should report each of them", func() {
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["169.254.0.0/19"]},` +
+				`{"cluster_id":"west","global_cidr":["169.254.16.0/19"]},` +
+				`{"cluster_id":"north","global_cidr":["10.0.0.0/19"]}]`
+
+			problems, overlaps, _ := ValidateGlobalnetAllocations(configMap)
+			Expect(problems).To(HaveLen(2))
+			Expect(overlaps).To(HaveLen(1))
+		})
+	})
+
+	When("an allocation isn't an IPv4 CIDR", func() {
+		It("should report it without checking its size", func() {
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["fd00::/64"]}]`
+
+			problems, overlaps, warnings := ValidateGlobalnetAllocations(configMap)
+			Expect(problems).To(HaveLen(1))
+			Expect(overlaps).To(BeEmpty())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	When("the globalnet CIDR range isn't an IPv4 CIDR", func() {
+		It("should report it", func() {
+			configMap.Data[GlobalnetCidrRange] = `"fd00::/48"`
+			configMap.Data[ClusterInfoKey] = `[{"cluster_id":"east","global_cidr":["fd00::/64"]}]`
+
+			problems, overlaps, warnings := ValidateGlobalnetAllocations(configMap)
+			Expect(problems).To(HaveLen(1))
+			Expect(overlaps).To(BeEmpty())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+
+	When("globalnet is disabled", func() {
+		It("should report no problems", func() {
+			configMap, err := NewGlobalnetConfigMap(false, "", 0, testBrokerNamespace, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			problems, overlaps, warnings := ValidateGlobalnetAllocations(configMap)
+			Expect(problems).To(BeEmpty())
+			Expect(overlaps).To(BeEmpty())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+})
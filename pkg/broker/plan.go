@@ -0,0 +1,70 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package broker
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Plan returns the resources which Ensure and CreateGlobalnetConfigMap create on the broker cluster, without creating
+// them. The objects have their type and namespace set so they can be serialized and applied as-is.
+func Plan(brokerNamespace string, globalnetEnabled bool, defaultGlobalCidrRange string,
+	defaultGlobalClusterSize uint, clusterInfo []ClusterInfo) ([]runtime.Object, error) {
+	gnConfigMap, err := NewGlobalnetConfigMap(globalnetEnabled, defaultGlobalCidrRange, defaultGlobalClusterSize, brokerNamespace,
+		clusterInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error creating config map: %s", err)
+	}
+
+	namespaced := []runtime.Object{
+		NewBrokerSA(SubmarinerBrokerAdminSA),
+		NewBrokerAdminRole(),
+		NewBrokerRoleBinding(SubmarinerBrokerAdminSA, submarinerBrokerAdminRole, brokerNamespace),
+		NewBrokerSA(submarinerBrokerClusterDefaultSA),
+		NewBrokerClusterRole(),
+		NewBrokerRoleBinding(submarinerBrokerClusterDefaultSA, submarinerBrokerClusterRole, brokerNamespace),
+		gnConfigMap,
+	}
+
+	objs := []runtime.Object{NewBrokerNamespace(brokerNamespace)}
+
+	for _, obj := range namespaced {
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		objMeta.SetNamespace(brokerNamespace)
+		objs = append(objs, obj)
+	}
+
+	for _, obj := range objs {
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+
+	return objs, nil
+}
@@ -0,0 +1,61 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package broker
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+var _ = Describe("Broker plan", func() {
+	const namespace = "custom-broker"
+
+	It("should return the broker resources without creating them", func() {
+		objs, err := Plan(namespace, true, "169.254.0.0/16", 8192, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		kinds := []string{}
+		for _, obj := range objs {
+			kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+
+			objMeta, err := meta.Accessor(obj)
+			Expect(err).ToNot(HaveOccurred())
+
+			if _, ok := obj.(*v1.Namespace); ok {
+				Expect(objMeta.GetName()).To(Equal(namespace))
+			} else {
+				Expect(objMeta.GetNamespace()).To(Equal(namespace))
+			}
+		}
+
+		Expect(kinds).To(Equal([]string{"Namespace", "ServiceAccount", "Role", "RoleBinding", "ServiceAccount", "Role",
+			"RoleBinding", "ConfigMap"}))
+	})
+
+	It("should include the globalnet settings in the ConfigMap", func() {
+		objs, err := Plan(namespace, true, "169.254.0.0/16", 8192, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		configMap, ok := objs[len(objs)-1].(*v1.ConfigMap)
+		Expect(ok).To(BeTrue())
+		Expect(configMap.Data[GlobalnetStatusKey]).To(Equal("true"))
+		Expect(configMap.Data[GlobalnetCidrRange]).To(Equal(`"169.254.0.0/16"`))
+	})
+})
@@ -294,8 +294,28 @@ func GetGlobalNetworks(k8sClientset *kubernetes.Clientset, brokerNamespace strin
 		return nil, nil, fmt.Errorf("error reading configMap: %s", err)
 	}
 
+	return parseGlobalNetworks(configMap)
+}
+
+// ReadGlobalNetworks is like GetGlobalNetworks but never modifies the broker: the legacy encodings of the config map
+// are only migrated in memory
+func ReadGlobalNetworks(k8sClientset kubernetes.Interface, brokerNamespace string) (*GlobalnetInfo, *v1.ConfigMap, error) {
+	configMap, err := broker.ReadGlobalnetConfigMap(k8sClientset, brokerNamespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading configMap: %s", err)
+	}
+
+	configMap = configMap.DeepCopy()
+	if _, err := broker.MigrateGlobalnetConfigMap(configMap); err != nil {
+		return nil, nil, fmt.Errorf("error migrating the globalnet config map: %s", err)
+	}
+
+	return parseGlobalNetworks(configMap)
+}
+
+func parseGlobalNetworks(configMap *v1.ConfigMap) (*GlobalnetInfo, *v1.ConfigMap, error) {
 	globalnetInfo := GlobalnetInfo{}
-	err = json.Unmarshal([]byte(configMap.Data[broker.GlobalnetStatusKey]), &globalnetInfo.GlobalnetEnabled)
+	err := json.Unmarshal([]byte(configMap.Data[broker.GlobalnetStatusKey]), &globalnetInfo.GlobalnetEnabled)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error reading globalnetEnabled status: %s", err)
 	}
@@ -21,6 +21,9 @@ package globalnet
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/submariner-io/submariner-operator/pkg/broker"
 )
 
 var _ = Describe("IsOverlappingCidr", func() {
@@ -215,3 +218,26 @@ var _ = Describe("AllocateGlobalCIDR: Fail", func() {
 		})
 	})
 })
+
+var _ = Describe("ReadGlobalNetworks", func() {
+	When("the globalnet CIDR range uses the legacy encoding", func() {
+		It("should parse it without modifying the broker", func() {
+			configMap, err := broker.NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, "submariner-k8s-broker",
+				[]broker.ClusterInfo{{ClusterID: "east", GlobalCidr: []string{"169.254.0.0/19"}}})
+			Expect(err).ToNot(HaveOccurred())
+			configMap.Data[broker.GlobalnetCidrRange] = "169.254.0.0/16"
+
+			clientSet := fake.NewSimpleClientset(configMap)
+
+			info, read, err := ReadGlobalNetworks(clientSet, "submariner-k8s-broker")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.GlobalnetCidrRange).To(Equal("169.254.0.0/16"))
+			Expect(info.GlobalCidrInfo).To(HaveKey("east"))
+			Expect(read.Data[broker.GlobalnetCidrRange]).To(Equal(`"169.254.0.0/16"`))
+
+			for _, action := range clientSet.Actions() {
+				Expect(action.GetVerb()).To(Equal("get"))
+			}
+		})
+	})
+})
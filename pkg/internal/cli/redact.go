@@ -0,0 +1,222 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ipv4Pattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?:/\d{1,2})?\b`)
+	// IPv6 candidates are validated when they're redacted, MAC addresses and times don't parse as IPs
+	ipv6Pattern = regexp.MustCompile(`(?:[0-9A-Fa-f]{0,4}:){2,7}[0-9A-Fa-f]{0,4}(?:/\d{1,3})?`)
+)
+
+// Redactor replaces sensitive identifiers in text with stable pseudonyms. Names are replaced
+// with numbered pseudonyms in the order they were added, only where they appear as whole words
+// or DNS labels; IP addresses and CIDRs are anonymized in a prefix-preserving way, so addresses
+// sharing a prefix still share one after redaction and overlapping CIDRs still overlap.
+type Redactor struct {
+	key          []byte
+	names        map[string]string
+	namesPattern *regexp.Regexp
+}
+
+// NewRedactor returns a redactor using a random key, so that the pseudonyms can't be reversed
+func NewRedactor() (*Redactor, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error generating the redaction key: %s", err)
+	}
+
+	return newRedactorWithKey(key), nil
+}
+
+func newRedactorWithKey(key []byte) *Redactor {
+	return &Redactor{
+		key:   key,
+		names: map[string]string{},
+	}
+}
+
+// AddName registers a sensitive name, such as a cluster name or ID, to be redacted
+func (r *Redactor) AddName(name string) {
+	if name == "" {
+		return
+	}
+
+	if _, ok := r.names[name]; ok {
+		return
+	}
+
+	// The angle brackets can't appear in cluster names, so the pseudonyms can't collide with them
+	r.names[name] = fmt.Sprintf("<cluster-%d>", len(r.names)+1)
+
+	// Match longer names first so that a name containing another one is replaced as a whole
+	names := make([]string, 0, len(r.names))
+	for name := range r.names {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j]) || (len(names[i]) == len(names[j]) && names[i] < names[j])
+	})
+
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, regexp.QuoteMeta(name))
+	}
+
+	r.namesPattern = regexp.MustCompile(strings.Join(quoted, "|"))
+}
+
+// Redact returns the text with the sensitive identifiers replaced
+func (r *Redactor) Redact(text string) string {
+	text = ipv4Pattern.ReplaceAllStringFunc(text, r.redactIP)
+	text = ipv6Pattern.ReplaceAllStringFunc(text, r.redactIP)
+
+	return r.redactNames(text)
+}
+
+// redactNames replaces the names which appear as whole words or labels, e.g. "east" in "cluster east." or "east.local"
+// but not in "north-east", "beast" or "www.east.example.com", whose host name is registered as a whole
+func (r *Redactor) redactNames(text string) string {
+	if r.namesPattern == nil {
+		return text
+	}
+
+	var redacted strings.Builder
+
+	done := 0
+	for offset := 0; offset < len(text); {
+		match := r.namesPattern.FindStringIndex(text[offset:])
+		if match == nil {
+			break
+		}
+
+		start, end := offset+match[0], offset+match[1]
+		if !isNameBoundary(text, start-1, -1) || !isNameBoundary(text, end, 1) {
+			offset = start + 1
+			continue
+		}
+
+		redacted.WriteString(text[done:start])
+		redacted.WriteString(r.names[text[start:end]])
+		done, offset = end, end
+	}
+
+	redacted.WriteString(text[done:])
+
+	return redacted.String()
+}
+
+// isNameBoundary returns true if the character at the index, next to a name in the given direction, ends the name: a
+// name continues with letters, digits, dashes and underscores, and with dots followed by such characters
+func isNameBoundary(text string, index, direction int) bool {
+	if index < 0 || index >= len(text) {
+		return true
+	}
+
+	if text[index] == '.' {
+		next := index + direction
+		return next < 0 || next >= len(text) || !isNameChar(text[next])
+	}
+
+	return !isNameChar(text[index])
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+func (r *Redactor) redactIP(match string) string {
+	address, prefix := match, ""
+
+	if i := strings.Index(match, "/"); i >= 0 {
+		address, prefix = match[:i], match[i+1:]
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return match
+	}
+
+	if ip4 := ip.To4(); ip4 != nil && !strings.Contains(address, ":") {
+		ip = ip4
+	} else if !strings.ContainsAny(address, "0123456789abcdefABCDEF") {
+		// Leave the unspecified address "::" alone
+		return match
+	}
+
+	bits := 8 * len(ip)
+	prefixLength := bits
+
+	if prefix != "" {
+		length, err := strconv.Atoi(prefix)
+		if err != nil || length > bits {
+			return match
+		}
+
+		prefixLength = length
+	}
+
+	result := r.anonymize(ip)
+	for bit := prefixLength; bit < bits; bit++ {
+		result[bit/8] &^= 0x80 >> uint(bit%8)
+	}
+
+	if prefix == "" {
+		return result.String()
+	}
+
+	return fmt.Sprintf("%s/%d", result, prefixLength)
+}
+
+// anonymize flips each bit of the address depending on the bits preceding it, so that the
+// mapping preserves common prefixes
+func (r *Redactor) anonymize(address net.IP) net.IP {
+	result := make(net.IP, len(address))
+	copy(result, address)
+
+	prefix := make([]byte, len(address))
+	buf := make([]byte, len(r.key)+2+len(address))
+	copy(buf, r.key)
+
+	for bit := 0; bit < 8*len(address); bit++ {
+		binary.BigEndian.PutUint16(buf[len(r.key):], uint16(bit))
+		copy(buf[len(r.key)+2:], prefix)
+
+		if sha256.Sum256(buf)[0]&1 == 1 {
+			result[bit/8] ^= 0x80 >> uint(bit%8)
+		}
+
+		// The prefix only holds the original bits preceding the next one
+		prefix[bit/8] |= address[bit/8] & (0x80 >> uint(bit%8))
+	}
+
+	return result
+}
@@ -0,0 +1,131 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestRedactNames(t *testing.T) {
+	r := newRedactorWithKey([]byte("test"))
+	r.AddName("east")
+	r.AddName("east-2")
+
+	redacted := r.Redact(`Checking cluster "east" and cluster "east-2"`)
+	if redacted != `Checking cluster "<cluster-1>" and cluster "<cluster-2>"` {
+		t.Fatalf("Unexpected redacted text %q", redacted)
+	}
+}
+
+func TestRedactNamesOnlyMatchesWholeLabels(t *testing.T) {
+	r := newRedactorWithKey([]byte("test"))
+	r.AddName("kubernetes")
+	r.AddName("gateway")
+	r.AddName("east")
+
+	text := "Label pod-security.kubernetes.io/enforce on submariner-gateway in north-east"
+	if redacted := r.Redact(text); redacted != text {
+		t.Fatalf("Expected the text to be left as is, got %q", redacted)
+	}
+
+	redacted := r.Redact("Cluster east. Gateway gateway, on kubernetes")
+	if redacted != "Cluster <cluster-3>. Gateway <cluster-2>, on <cluster-1>" {
+		t.Fatalf("Unexpected redacted text %q", redacted)
+	}
+}
+
+func TestRedactNamesDoesntCollideWithPseudonyms(t *testing.T) {
+	r := newRedactorWithKey([]byte("test"))
+	r.AddName("east")
+	r.AddName("cluster-1")
+
+	redacted := r.Redact("east cluster-1")
+	if redacted != "<cluster-1> <cluster-2>" {
+		t.Fatalf("Unexpected redacted text %q", redacted)
+	}
+}
+
+func TestRedactIPsIsStable(t *testing.T) {
+	r := newRedactorWithKey([]byte("test"))
+
+	first := r.Redact("10.1.2.3")
+	if first == "10.1.2.3" || net.ParseIP(first) == nil {
+		t.Fatalf("Expected a different valid IP, got %q", first)
+	}
+
+	if second := r.Redact("gateway 10.1.2.3"); second != "gateway "+first {
+		t.Fatalf("Expected %q to be redacted consistently, got %q", first, second)
+	}
+}
+
+func TestRedactCIDRsPreservesOverlaps(t *testing.T) {
+	r := newRedactorWithKey([]byte("test"))
+
+	fields := strings.Fields(r.Redact("10.0.0.0/16 10.0.1.0/24 10.1.0.0/16 10.0.1.7"))
+
+	_, wide, err := net.ParseCIDR(fields[0])
+	if err != nil || wide.String() != fields[0] {
+		t.Fatalf("Expected a valid network CIDR, got %q", fields[0])
+	}
+
+	_, narrow, _ := net.ParseCIDR(fields[1])
+	_, other, _ := net.ParseCIDR(fields[2])
+
+	if !wide.Contains(narrow.IP) {
+		t.Fatalf("Expected %q to contain %q", fields[0], fields[1])
+	}
+
+	if wide.Contains(other.IP) || other.Contains(wide.IP) {
+		t.Fatalf("Expected %q not to overlap %q", fields[0], fields[2])
+	}
+
+	if !narrow.Contains(net.ParseIP(fields[3])) {
+		t.Fatalf("Expected %q to contain %q", fields[1], fields[3])
+	}
+}
+
+func TestRedactIPv6PreservesPrefixes(t *testing.T) {
+	r := newRedactorWithKey([]byte("test"))
+
+	fields := strings.Fields(r.Redact("fd00:10:244::/64 fd00:10:244::7 fd00:10:245::/64 aa:bb:cc:dd:ee:ff"))
+
+	_, network, err := net.ParseCIDR(fields[0])
+	if err != nil || network.String() != fields[0] || fields[0] == "fd00:10:244::/64" {
+		t.Fatalf("Expected a different valid network CIDR, got %q", fields[0])
+	}
+
+	if !network.Contains(net.ParseIP(fields[1])) {
+		t.Fatalf("Expected %q to contain %q", fields[0], fields[1])
+	}
+
+	_, other, _ := net.ParseCIDR(fields[2])
+	if network.Contains(other.IP) {
+		t.Fatalf("Expected %q not to overlap %q", fields[0], fields[2])
+	}
+
+	if fields[3] != "aa:bb:cc:dd:ee:ff" {
+		t.Fatalf("Expected the MAC address to be left as is, got %q", fields[3])
+	}
+
+	if again := r.Redact("fd00:10:244::7"); again != fields[1] {
+		t.Fatalf("Expected %q to be redacted consistently, got %q", fields[1], again)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/submariner-io/submariner-operator/pkg/internal/env"
 	"github.com/submariner-io/submariner-operator/pkg/internal/log"
@@ -31,8 +32,24 @@ const (
 	Success Result = iota
 	Failure
 	Warning
+	// Skipped phases didn't check anything; they are neither counted as passed nor as warned
+	Skipped
 )
 
+// PhaseRecord records how a phase ended and the messages it displayed, with sensitive identifiers redacted
+type PhaseRecord struct {
+	Title string
+	// The cluster which the phase checked, empty if it checked several clusters or none in particular
+	Cluster   string
+	Result    Result
+	Successes []string
+	Failures  []string
+	Warnings  []string
+	// The codes of the failures and warnings, in the same order; messages queued without a code have an empty one
+	FailureCodes []string
+	WarningCodes []string
+}
+
 // Status is used to track ongoing status in a CLI, with a nice loading spinner
 // when attached to a terminal
 type Status struct {
@@ -43,14 +60,40 @@ type Status struct {
 	successFormat string
 	failureFormat string
 	warningFormat string
+	skippedFormat string
+	// when set, the time taken by each phase is displayed once it ends
+	showDurations bool
+	startTime     time.Time
+	// the cluster which the phases started from now on check, and the one the current phase checks
+	cluster      string
+	phaseCluster string
+	// when set, only the phases which didn't succeed are displayed
+	quiet bool
+	// the number of phases which ended with each result
+	resultCounts map[Result]int
+	// the number of phases which ended with a warning result or displayed warnings
+	warnedPhases int
+	// when set, sensitive identifiers are redacted from the output
+	redactor *Redactor
+	// when set, the phases are recorded as they end
+	recording bool
+	recorded  []PhaseRecord
 	// message queues
 	successQueue []string
 	failureQueue []string
 	warningQueue []string
+	// the codes of the queued failures and warnings
+	failureCodes []string
+	warningCodes []string
 }
 
 func NewStatus() *Status {
-	var writer io.Writer = os.Stderr
+	return NewStatusForWriter(os.Stderr)
+}
+
+// NewStatusForWriter returns a new status object writing to the given writer,
+// with a loading spinner if the writer is a terminal
+func NewStatusForWriter(writer io.Writer) *Status {
 	if env.IsSmartTerminal(writer) {
 		writer = NewSpinner(writer)
 	}
@@ -66,9 +109,13 @@ func StatusForLogger(l log.Logger) *Status {
 		successFormat: " ✓ %s\n",
 		failureFormat: " ✗ %s\n",
 		warningFormat: " ⚠ %s\n",
+		skippedFormat: " - %s\n",
 		successQueue:  []string{},
 		failureQueue:  []string{},
 		warningQueue:  []string{},
+		failureCodes:  []string{},
+		warningCodes:  []string{},
+		resultCounts:  map[Result]int{},
 	}
 	// if we're using the CLI logger, check for if it has a spinner setup
 	// and wire the status to that
@@ -79,6 +126,7 @@ func StatusForLogger(l log.Logger) *Status {
 			s.successFormat = " \x1b[32m✓\x1b[0m %s\n"
 			s.failureFormat = " \x1b[31m✗\x1b[0m %s\n"
 			s.warningFormat = " \x1b[33m⚠\x1b[0m %s\n"
+			s.skippedFormat = " \x1b[90m-\x1b[0m %s\n"
 		}
 	}
 	return s
@@ -89,7 +137,12 @@ func StatusForLogger(l log.Logger) *Status {
 func (s *Status) Start(status string) {
 	s.End(Success)
 	// set new status
-	s.status = status
+	s.status = s.Redacted(status)
+	s.phaseCluster = s.Redacted(s.cluster)
+	s.startTime = time.Now()
+	if s.quiet {
+		return
+	}
 	if s.spinner != nil {
 		s.spinner.SetSuffix(fmt.Sprintf(" %s ", s.status))
 		s.spinner.Start()
@@ -105,33 +158,156 @@ func (s *Status) End(output Result) {
 		return
 	}
 
-	if s.spinner != nil {
+	s.resultCounts[output]++
+
+	if output == Warning || len(s.warningQueue) > 0 {
+		s.warnedPhases++
+	}
+
+	if s.recording {
+		s.recorded = append(s.recorded, PhaseRecord{
+			Title:     s.status,
+			Cluster:   s.phaseCluster,
+			Result:    output,
+			Successes: s.redactedAll(s.successQueue),
+			Failures:  s.redactedAll(s.failureQueue),
+			Warnings:  s.redactedAll(s.warningQueue),
+
+			FailureCodes: s.failureCodes,
+			WarningCodes: s.warningCodes,
+		})
+	}
+
+	if s.quiet && (output == Success || output == Skipped) && len(s.failureQueue) == 0 && len(s.warningQueue) == 0 {
+		s.reset()
+		return
+	}
+
+	if s.spinner != nil && !s.quiet {
 		s.spinner.Stop()
 		fmt.Fprint(s.spinner.writer, "\r")
 	}
+
+	status := s.status
+	if s.showDurations {
+		status = fmt.Sprintf("%s (%s)", status, time.Since(s.startTime).Round(time.Millisecond))
+	}
+
 	switch output {
 	case Success:
-		s.logger.V(0).Infof(s.successFormat, s.status)
+		s.logger.V(0).Infof(s.successFormat, status)
 	case Failure:
-		s.logger.V(0).Infof(s.failureFormat, s.status)
+		s.logger.V(0).Infof(s.failureFormat, status)
 	case Warning:
-		s.logger.V(0).Infof(s.warningFormat, s.status)
+		s.logger.V(0).Infof(s.warningFormat, status)
+	case Skipped:
+		s.logger.V(0).Infof(s.skippedFormat, status)
 	}
 
-	for _, message := range s.successQueue {
-		s.logger.V(0).Infof(s.successFormat, message)
+	if !s.quiet {
+		// The messages of a skipped phase explain why it was skipped
+		successFormat := s.successFormat
+		if output == Skipped {
+			successFormat = s.skippedFormat
+		}
+
+		for _, message := range s.successQueue {
+			s.logger.V(0).Infof(successFormat, s.Redacted(message))
+		}
 	}
-	for _, message := range s.failureQueue {
-		s.logger.V(0).Infof(s.failureFormat, message)
+	for i, message := range s.failureQueue {
+		s.logger.V(0).Infof(s.failureFormat, WithCode(s.failureCodes[i], s.Redacted(message)))
 	}
-	for _, message := range s.warningQueue {
-		s.logger.V(0).Infof(s.warningFormat, message)
+	for i, message := range s.warningQueue {
+		s.logger.V(0).Infof(s.warningFormat, WithCode(s.warningCodes[i], s.Redacted(message)))
 	}
 
+	s.reset()
+}
+
+func (s *Status) reset() {
 	s.status = ""
 	s.successQueue = []string{}
 	s.failureQueue = []string{}
 	s.warningQueue = []string{}
+	s.failureCodes = []string{}
+	s.warningCodes = []string{}
+}
+
+// SetCluster sets the cluster which the phases started from now on check, so that they are recorded with it; an
+// empty name marks them as checking several clusters or none in particular
+func (s *Status) SetCluster(clusterName string) {
+	s.cluster = clusterName
+}
+
+// Skip ends the current phase as skipped, displaying the reason
+func (s *Status) Skip(reason string) {
+	s.QueueSuccessMessage(reason)
+	s.End(Skipped)
+}
+
+// Quiet only displays the phases which end with failures or warnings
+func (s *Status) Quiet() {
+	s.quiet = true
+}
+
+// PrintSummary displays the number of phases which ended with each result
+func (s *Status) PrintSummary() {
+	s.End(Success)
+	summary := fmt.Sprintf("%d passed, %d with warnings, %d failed", s.resultCounts[Success], s.resultCounts[Warning],
+		s.resultCounts[Failure])
+	if s.resultCounts[Skipped] > 0 {
+		summary += fmt.Sprintf(", %d skipped", s.resultCounts[Skipped])
+	}
+
+	s.logger.V(0).Infof("%s\n", summary)
+}
+
+// WarnedPhases ends the current phase and returns the number of phases which ended with a warning result or
+// displayed warnings
+func (s *Status) WarnedPhases() int {
+	s.End(Success)
+	return s.warnedPhases
+}
+
+// Redact replaces the sensitive identifiers known to the redactor in all further output
+func (s *Status) Redact(redactor *Redactor) {
+	s.redactor = redactor
+}
+
+// Redacted returns the text as it would be displayed, with sensitive identifiers
+// replaced if redaction is enabled
+func (s *Status) Redacted(text string) string {
+	if s.redactor == nil {
+		return text
+	}
+
+	return s.redactor.Redact(text)
+}
+
+func (s *Status) redactedAll(messages []string) []string {
+	redacted := make([]string, len(messages))
+	for i, message := range messages {
+		redacted[i] = s.Redacted(message)
+	}
+
+	return redacted
+}
+
+// Record enables recording the phases as they end, so that they can be reported once the run completes
+func (s *Status) Record() {
+	s.recording = true
+}
+
+// Recorded ends the current phase and returns the phases recorded so far
+func (s *Status) Recorded() []PhaseRecord {
+	s.End(Success)
+	return s.recorded
+}
+
+// ShowDurations enables displaying the time taken by each phase when it ends
+func (s *Status) ShowDurations() {
+	s.showDurations = true
 }
 
 // QueueSuccessMessage queues up a message, which will be displayed once
@@ -143,13 +319,36 @@ func (s *Status) QueueSuccessMessage(message string) {
 // QueueFailureMessage queues up a message, which will be displayed once
 // the status ends (using the failure format)
 func (s *Status) QueueFailureMessage(message string) {
+	s.QueueFailureMessageWithCode("", message)
+}
+
+// QueueFailureMessageWithCode queues up a failure message along with the
+// stable code identifying the kind of failure
+func (s *Status) QueueFailureMessageWithCode(code, message string) {
 	s.failureQueue = append(s.failureQueue, message)
+	s.failureCodes = append(s.failureCodes, code)
 }
 
 // QueuewarningMessage queues up a message, which will be displayed once
 // the status ends (using the warning format)
 func (s *Status) QueueWarningMessage(message string) {
+	s.QueueWarningMessageWithCode("", message)
+}
+
+// QueueWarningMessageWithCode queues up a warning message along with the
+// stable code identifying the kind of warning
+func (s *Status) QueueWarningMessageWithCode(code, message string) {
 	s.warningQueue = append(s.warningQueue, message)
+	s.warningCodes = append(s.warningCodes, code)
+}
+
+// WithCode prefixes the message with its code, if any, as it is displayed
+func WithCode(code, message string) string {
+	if code == "" {
+		return message
+	}
+
+	return fmt.Sprintf("[%s] %s", code, message)
 }
 
 func (s *Status) HasFailureMessages() bool {
@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStatusRecordsMessageCodes(t *testing.T) {
+	var output bytes.Buffer
+
+	s := NewStatusForWriter(&output)
+	s.Record()
+	s.Start("Checking the CIDRs")
+	s.QueueFailureMessageWithCode("SM-CIDR-001", "The CIDRs overlap")
+	s.QueueFailureMessage("Error listing the Endpoints")
+	s.QueueWarningMessageWithCode("SM-CIDR-002", "The CIDRs drifted")
+	s.End(Failure)
+
+	recorded := s.Recorded()
+	if len(recorded) != 1 {
+		t.Fatalf("Expected one recorded phase, got %d", len(recorded))
+	}
+
+	if !reflect.DeepEqual(recorded[0].FailureCodes, []string{"SM-CIDR-001", ""}) {
+		t.Fatalf("Unexpected failure codes %q", recorded[0].FailureCodes)
+	}
+
+	if !reflect.DeepEqual(recorded[0].WarningCodes, []string{"SM-CIDR-002"}) {
+		t.Fatalf("Unexpected warning codes %q", recorded[0].WarningCodes)
+	}
+
+	if !strings.Contains(output.String(), "[SM-CIDR-001] The CIDRs overlap") {
+		t.Fatalf("Expected the code to be displayed with its message, got %q", output.String())
+	}
+
+	if strings.Contains(output.String(), "] Error listing the Endpoints") {
+		t.Fatalf("Expected the message without a code to be displayed as is, got %q", output.String())
+	}
+}
+
+func TestStatusSkippedPhasesArentWarned(t *testing.T) {
+	var output bytes.Buffer
+
+	s := NewStatusForWriter(&output)
+	s.Start("Checking the firewall")
+	s.Skip("Skipped in read-only mode")
+	s.Start("Checking the CIDRs")
+	s.QueueWarningMessage("The CIDRs drifted")
+	s.End(Warning)
+
+	if warned := s.WarnedPhases(); warned != 1 {
+		t.Fatalf("Expected only the warned phase to be counted, got %d", warned)
+	}
+
+	s.PrintSummary()
+
+	if !strings.Contains(output.String(), "0 passed, 1 with warnings, 0 failed, 1 skipped") {
+		t.Fatalf("Expected the skipped phase to be summarized, got %q", output.String())
+	}
+}
+
+func TestStatusRecordsPhaseClusters(t *testing.T) {
+	s := NewStatusForWriter(&bytes.Buffer{})
+	s.Record()
+	s.SetCluster("east")
+	s.Start("Checking the gateways")
+	s.SetCluster("west")
+	s.QueueSuccessMessage("The cluster changed while the phase ran")
+	s.Start("Checking the gateways of \"east\" from \"west\"")
+	s.SetCluster("")
+	s.Start("Checking that the CIDRs don't overlap")
+
+	clusters := []string{}
+	for _, phase := range s.Recorded() {
+		clusters = append(clusters, phase.Cluster)
+	}
+
+	if !reflect.DeepEqual(clusters, []string{"east", "west", ""}) {
+		t.Fatalf("Unexpected phase clusters %q", clusters)
+	}
+}
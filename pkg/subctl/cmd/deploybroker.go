@@ -20,11 +20,14 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/submariner-io/admiral/pkg/stringset"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
 
 	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
 	"github.com/submariner-io/submariner-operator/pkg/subctl/components"
@@ -46,6 +49,9 @@ var (
 	componentArr                []string
 	GlobalCIDRConfigMap         *v1.ConfigMap
 	defaultCustomDomains        []string
+	deployBrokerNamespace       string
+	deployBrokerDryRun          bool
+	globalnetClusterInfoFile    string
 )
 
 var defaultComponents = []string{components.ServiceDiscovery, components.Connectivity}
@@ -58,6 +64,8 @@ func init() {
 		"GlobalCIDR supernet range for allocating GlobalCIDRs to each cluster")
 	deployBroker.PersistentFlags().UintVar(&defaultGlobalnetClusterSize, "globalnet-cluster-size", 8192,
 		"default cluster size for GlobalCIDR allocated to each cluster (amount of global IPs)")
+	deployBroker.PersistentFlags().StringVar(&globalnetClusterInfoFile, "globalnet-cluster-info", "",
+		"YAML file listing the \"cluster_id\" and \"global_cidr\" entries to seed the globalnet allocations with")
 
 	deployBroker.PersistentFlags().StringVar(&ipsecSubmFile, "ipsec-psk-from", "",
 		"import IPsec PSK from existing submariner broker file, like broker-info.subm")
@@ -67,6 +75,12 @@ func init() {
 
 	_ = deployBroker.PersistentFlags().MarkDeprecated("service-discovery", "please use --components instead")
 
+	deployBroker.PersistentFlags().StringVar(&deployBrokerNamespace, "broker-namespace", broker.SubmarinerBrokerNamespace,
+		"namespace in which the broker resources are created")
+
+	deployBroker.PersistentFlags().BoolVar(&deployBrokerDryRun, "dry-run", false,
+		"print the resources which would be created, as YAML, without creating them")
+
 	deployBroker.PersistentFlags().StringSliceVar(&defaultCustomDomains, "custom-domains", nil,
 		"list of domains to use for multicluster service discovery")
 
@@ -78,6 +92,7 @@ func init() {
 
 	deployBroker.PersistentFlags().BoolVar(&operatorDebug, "operator-debug", false, "enable operator debugging (verbose logging)")
 
+	addWaitFlags(deployBroker)
 	addKubeContextFlag(deployBroker)
 	rootCmd.AddCommand(deployBroker)
 }
@@ -108,18 +123,35 @@ var deployBroker = &cobra.Command{
 		if valid, err := isValidGlobalnetConfig(); !valid {
 			exitOnError("Invalid GlobalCIDR configuration", err)
 		}
+
+		seededClusterInfo, err := readGlobalnetClusterInfo()
+		exitOnError("Error reading the globalnet cluster info", err)
+
+		if deployBrokerDryRun {
+			err := printBrokerPlan(seededClusterInfo)
+			exitOnError("Error printing the resources", err)
+			return
+		}
+
 		config, err := getRestConfig(kubeConfig, kubeContext)
 		exitOnError("The provided kubeconfig is invalid", err)
 
 		status := cli.NewStatus()
 
 		status.Start("Setting up broker RBAC")
-		err = broker.Ensure(config, componentArr, false)
+		err = broker.Ensure(config, componentArr, false, deployBrokerNamespace, waitInterval, brokerTokenWaitTimeout)
 		status.End(cli.CheckForError(err))
 		exitOnError("Error setting up broker RBAC", err)
 
+		// The globalnet config map is created before the broker so that the seeded cluster info isn't pre-empted by
+		// the operator, which creates it without
+		err = broker.CreateGlobalnetConfigMap(config, globalnetEnable, globalnetCIDRRange,
+			defaultGlobalnetClusterSize, deployBrokerNamespace, seededClusterInfo)
+		exitOnError("Error creating globalCIDR configmap on Broker", err)
+
 		status.Start("Deploying the Submariner operator")
-		err = submarinerop.Ensure(status, config, OperatorNamespace, operatorImage(), operatorDebug)
+		err = submarinerop.Ensure(status, config, OperatorNamespace, operatorImage(), operatorDebug, waitInterval,
+			operatorWaitTimeout)
 		status.End(cli.CheckForError(err))
 		exitOnError("Error deploying the operator", err)
 
@@ -146,7 +178,7 @@ var deployBroker = &cobra.Command{
 			}
 		}
 
-		subctlData, err := datafile.NewFromCluster(config, broker.SubmarinerBrokerNamespace, ipsecSubmFile)
+		subctlData, err := datafile.NewFromCluster(config, deployBrokerNamespace, ipsecSubmFile)
 		exitOnError("Error retrieving preparing the subm data file", err)
 
 		newFilename, err := datafile.BackupIfExists(brokerDetailsFilename)
@@ -165,10 +197,6 @@ var deployBroker = &cobra.Command{
 
 		exitOnError("Error setting up service discovery information", err)
 
-		err = broker.CreateGlobalnetConfigMap(config, globalnetEnable, globalnetCIDRRange,
-			defaultGlobalnetClusterSize, broker.SubmarinerBrokerNamespace)
-		exitOnError("Error creating globalCIDR configmap on Broker", err)
-
 		err = subctlData.WriteToFile(brokerDetailsFilename)
 		status.End(cli.CheckForError(err))
 		exitOnError("Error writing the broker information", err)
@@ -176,6 +204,85 @@ var deployBroker = &cobra.Command{
 	},
 }
 
+// printBrokerPlan prints the resources which deploy-broker creates, as a YAML stream
+func printBrokerPlan(seededClusterInfo []broker.ClusterInfo) error {
+	objs, err := brokerPlan(seededClusterInfo)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		output, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("---\n%s", output)
+	}
+
+	return nil
+}
+
+// brokerPlan returns the resources which deploy-broker creates, in the order it creates them. The IPsec PSK secret
+// comes last: it isn't created on the broker cluster but stored in the broker information file, and is reused from the
+// existing file as deploy-broker does. Its key is never included, only a placeholder stating where it comes from.
+func brokerPlan(seededClusterInfo []broker.ClusterInfo) ([]runtime.Object, error) {
+	objs, err := broker.Plan(deployBrokerNamespace, globalnetEnable, globalnetCIDRRange, defaultGlobalnetClusterSize,
+		seededClusterInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	operatorObjs, err := submarinerop.Plan(OperatorNamespace, operatorImage(), operatorDebug)
+	if err != nil {
+		return nil, err
+	}
+
+	objs = append(objs, operatorObjs...)
+	objs = append(objs, brokercr.New(OperatorNamespace, populateBrokerSpec()))
+
+	pskSubmFile := ipsecSubmFile
+	if pskSubmFile == "" {
+		if _, err := datafile.NewFromFile(brokerDetailsFilename); err == nil {
+			pskSubmFile = brokerDetailsFilename
+		}
+	}
+
+	pskPlaceholder := "<generated>"
+	if pskSubmFile != "" {
+		// Check that the PSK can be imported, as deploy-broker would
+		if _, err := datafile.NewIPSecPSKSecret(pskSubmFile); err != nil {
+			return nil, err
+		}
+
+		pskPlaceholder = fmt.Sprintf("<imported from %s>", pskSubmFile)
+	}
+
+	pskSecret := datafile.NewIPSecPSKSecretPlaceholder(pskPlaceholder)
+	pskSecret.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Secret"))
+
+	return append(objs, pskSecret), nil
+}
+
+// readGlobalnetClusterInfo reads the cluster info entries to seed the globalnet config map with, if any
+func readGlobalnetClusterInfo() ([]broker.ClusterInfo, error) {
+	if globalnetClusterInfoFile == "" {
+		return nil, nil
+	}
+
+	contents, err := ioutil.ReadFile(globalnetClusterInfoFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusterInfo []broker.ClusterInfo
+	if err := yaml.Unmarshal(contents, &clusterInfo); err != nil {
+		return nil, err
+	}
+
+	return clusterInfo, nil
+}
+
 func isValidComponents(componentSet stringset.Interface) error {
 	validComponentSet := stringset.New(validComponents...)
 
@@ -206,6 +313,7 @@ func isValidGlobalnetConfig() (bool, error) {
 
 func populateBrokerSpec() submarinerv1a1.BrokerSpec {
 	brokerSpec := submarinerv1a1.BrokerSpec{
+		BrokerNamespace:             deployBrokerNamespace,
 		GlobalnetEnabled:            globalnetEnable,
 		GlobalnetCIDRRange:          globalnetCIDRRange,
 		DefaultGlobalnetClusterSize: defaultGlobalnetClusterSize,
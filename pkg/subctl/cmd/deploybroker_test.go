@@ -0,0 +1,111 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"github.com/submariner-io/submariner-operator/pkg/broker"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/brokercr"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/common/embeddedyamls"
+	lighthousesa "github.com/submariner-io/submariner-operator/pkg/subctl/operator/lighthouse/serviceaccount"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/submarinerop/crds"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/submarinerop/serviceaccount"
+)
+
+func TestBrokerPlanListsDeployedResources(t *testing.T) {
+	objs, err := brokerPlan(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	planned := map[string]bool{}
+
+	for _, obj := range objs {
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if kind == "" {
+			t.Errorf("%q has no kind", objMeta.GetName())
+		}
+
+		if clusterRoleBinding, ok := obj.(*rbacv1.ClusterRoleBinding); ok &&
+			clusterRoleBinding.Subjects[0].Namespace != OperatorNamespace {
+			t.Errorf("ClusterRoleBinding %q binds a service account in namespace %q", clusterRoleBinding.Name,
+				clusterRoleBinding.Subjects[0].Namespace)
+		}
+
+		if secret, ok := obj.(*v1.Secret); ok && (len(secret.Data) > 0 || secret.StringData["psk"] != "<generated>") {
+			t.Errorf("Secret %q discloses its contents", secret.Name)
+		}
+
+		planned[kind+" "+objMeta.GetNamespace()+"/"+objMeta.GetName()] = true
+	}
+
+	expected := []string{
+		"Namespace /" + deployBrokerNamespace,
+		"ServiceAccount " + deployBrokerNamespace + "/" + broker.SubmarinerBrokerAdminSA,
+		"ConfigMap " + deployBrokerNamespace + "/" + broker.GlobalCIDRConfigMapName,
+		"Namespace /" + OperatorNamespace,
+		"Deployment " + OperatorNamespace + "/" + names.OperatorComponent,
+		"Broker " + OperatorNamespace + "/" + brokercr.BrokerName,
+		"Secret /submariner-ipsec-psk",
+	}
+
+	// The resources which the operator deployment creates from the embedded YAMLs
+	embedded := []struct {
+		kind      string
+		namespace string
+		yamls     []string
+	}{
+		{"CustomResourceDefinition", "", crds.CRDs},
+		{"ServiceAccount", OperatorNamespace, serviceaccount.ServiceAccounts},
+		{"Role", OperatorNamespace, serviceaccount.Roles},
+		{"RoleBinding", OperatorNamespace, serviceaccount.RoleBindings},
+		{"ClusterRole", "", serviceaccount.ClusterRoles},
+		{"ClusterRoleBinding", "", serviceaccount.ClusterRoleBindings},
+		{"ServiceAccount", OperatorNamespace, lighthousesa.ServiceAccounts},
+		{"ClusterRole", "", lighthousesa.ClusterRoles},
+		{"ClusterRoleBinding", "", lighthousesa.ClusterRoleBindings},
+	}
+
+	for i := range embedded {
+		for _, yaml := range embedded[i].yamls {
+			name, err := embeddedyamls.GetObjectName(yaml)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expected = append(expected, embedded[i].kind+" "+embedded[i].namespace+"/"+name)
+		}
+	}
+
+	for _, resource := range expected {
+		if !planned[resource] {
+			t.Errorf("The plan doesn't include %s", resource)
+		}
+	}
+}
@@ -105,6 +105,7 @@ func addJoinFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&submarinerDebug, "pod-debug", false,
 		"enable Submariner pod debugging (verbose logging in the deployed pods)")
 	cmd.Flags().BoolVar(&operatorDebug, "operator-debug", false, "enable operator debugging (verbose logging)")
+	addWaitFlags(cmd)
 	cmd.Flags().BoolVar(&labelGateway, "label-gateway", true, "label gateways if necessary")
 	cmd.Flags().StringVar(&cableDriver, "cable-driver", "", "cable driver implementation")
 	cmd.Flags().UintVar(&globalnetClusterSize, "globalnet-cluster-size", 0,
@@ -268,12 +269,14 @@ func joinSubmarinerCluster(config clientcmd.ClientConfig, contextName string, su
 
 	status.Start("Deploying the Submariner operator")
 
-	err = submarinerop.Ensure(status, clientConfig, OperatorNamespace, operatorImage(), operatorDebug)
+	err = submarinerop.Ensure(status, clientConfig, OperatorNamespace, operatorImage(), operatorDebug, waitInterval,
+		operatorWaitTimeout)
 	status.End(cli.CheckForError(err))
 	exitOnError("Error deploying the operator", err)
 
 	status.Start("Creating SA for cluster")
-	clienttoken, err = broker.CreateSAForCluster(brokerAdminClientset, clusterID)
+	clienttoken, err = broker.CreateSAForCluster(brokerAdminClientset, clusterID, brokerNamespace, waitInterval,
+		brokerTokenWaitTimeout)
 	status.End(cli.CheckForError(err))
 	exitOnError("Error creating SA for cluster", err)
 
@@ -304,11 +307,15 @@ func joinSubmarinerCluster(config clientcmd.ClientConfig, contextName string, su
 }
 
 func checkRequirements(config *rest.Config) ([]string, error) {
-	failedRequirements := []string{}
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return failedRequirements, errors.WithMessage(err, "error creating API server client")
+		return []string{}, errors.WithMessage(err, "error creating API server client")
 	}
+	return checkRequirementsWithClient(clientset)
+}
+
+func checkRequirementsWithClient(clientset kubernetes.Interface) ([]string, error) {
+	failedRequirements := []string{}
 	serverVersion, err := clientset.Discovery().ServerVersion()
 	if err != nil {
 		return failedRequirements, errors.WithMessage(err, "error obtaining API server version")
@@ -41,6 +41,16 @@ import (
 	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/submarinercr"
 )
 
+// The cluster name used for the in-cluster configuration
+const inClusterName = "in-cluster"
+
+// How the Submariner resource is looked up: by name and namespace or, with a selector, by its labels in the namespace
+var (
+	submarinerResourceName      = submarinercr.SubmarinerName
+	submarinerResourceNamespace = OperatorNamespace
+	submarinerResourceSelector  string
+)
+
 func getMultipleRestConfigs(kubeConfigPath string, kubeContexts []string) ([]restConfig, error) {
 	var restConfigs []restConfig
 
@@ -63,6 +73,15 @@ func getMultipleRestConfigs(kubeConfigPath string, kubeContexts []string) ([]res
 		}
 	}
 
+	// Without any kubeconfig, fall back to the service account when running in a pod
+	if len(contexts) == 0 && kubeConfigPath == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			addRedactedHost(config.Host)
+			watchThrottling(config, inClusterName)
+			return []restConfig{{config: config, clusterName: inClusterName}}, addCABundle(config)
+		}
+	}
+
 	for _, context := range contexts {
 		if context != "" {
 			overrides.CurrentContext = context
@@ -71,6 +90,13 @@ func getMultipleRestConfigs(kubeConfigPath string, kubeContexts []string) ([]res
 				return nil, err
 			}
 
+			if err := addCABundle(config.config); err != nil {
+				return nil, err
+			}
+
+			addRedactedNames(context, config.clusterName)
+			addRedactedHost(config.config.Host)
+			watchThrottling(config.config, config.clusterName)
 			restConfigs = append(restConfigs, config)
 		}
 	}
@@ -84,25 +110,59 @@ func getSubmarinerResourceWithError(config *rest.Config) (*v1alpha1.Submariner,
 		return nil, err
 	}
 
-	submariner, err := submarinerClient.SubmarinerV1alpha1().Submariners(OperatorNamespace).
-		Get(context.TODO(), submarinercr.SubmarinerName, v1opts.GetOptions{})
+	return getSubmarinerResourceWithClient(submarinerClient)
+}
+
+func getSubmarinerResourceWithClient(submarinerClient subOperatorClientset.Interface) (*v1alpha1.Submariner, error) {
+	if submarinerResourceSelector == "" {
+		return submarinerClient.SubmarinerV1alpha1().Submariners(submarinerResourceNamespace).
+			Get(context.TODO(), submarinerResourceName, v1opts.GetOptions{})
+	}
+
+	submariners, err := submarinerClient.SubmarinerV1alpha1().Submariners(submarinerResourceNamespace).
+		List(context.TODO(), v1opts.ListOptions{LabelSelector: submarinerResourceSelector})
 	if err != nil {
 		return nil, err
 	}
 
-	return submariner, nil
+	switch len(submariners.Items) {
+	case 0:
+		return nil, apierrors.NewNotFound(v1alpha1.SchemeGroupVersion.WithResource("submariners").GroupResource(),
+			submarinerResourceSelector)
+	case 1:
+		return &submariners.Items[0], nil
+	default:
+		return nil, fmt.Errorf("%d Submariner resources in namespace %q match the selector %q", len(submariners.Items),
+			submarinerResourceNamespace, submarinerResourceSelector)
+	}
 }
 
 func getSubmarinerResource(config *rest.Config) *v1alpha1.Submariner {
 	submariner, err := getSubmarinerResourceWithError(config)
+	return submarinerResourceOrExit(submariner, err)
+}
+
+// submarinerResourceOrExit returns the Submariner resource, or nil if it doesn't exist; other errors are fatal
+func submarinerResourceOrExit(submariner *v1alpha1.Submariner, err error) *v1alpha1.Submariner {
+	submariner, err = submarinerResourceOrError(submariner, err)
+	exitOnError("Error obtaining the Submariner resource", err)
+
+	return submariner
+}
+
+// submarinerResourceOrError returns the Submariner resource, or nil without an error if it doesn't exist
+func submarinerResourceOrError(submariner *v1alpha1.Submariner, err error) (*v1alpha1.Submariner, error) {
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			return nil
+			return nil, nil
 		}
-		exitOnError("Error obtaining the Submariner resource", err)
+
+		return nil, err
 	}
 
-	return submariner
+	addRedactedNames(submariner.Spec.ClusterID)
+
+	return submariner, nil
 }
 
 func getEndpointResource(config *rest.Config, clusterID string) *submarinerv1.Endpoint {
@@ -123,7 +183,7 @@ func getEndpointResource(config *rest.Config, clusterID string) *submarinerv1.En
 	return nil
 }
 
-func getActiveGatewayNodeName(clientSet *kubernetes.Clientset, hostname string) string {
+func getActiveGatewayNodeName(clientSet kubernetes.Interface, hostname string) string {
 	nodes, err := clientSet.CoreV1().Nodes().List(context.TODO(), v1opts.ListOptions{})
 	if err != nil {
 		exitOnError("Error listing the Nodes in the local cluster", err)
@@ -146,6 +206,10 @@ func getGatewaysResource(config *rest.Config) *submarinerv1.GatewayList {
 	submarinerClient, err := subClientsetv1.NewForConfig(config)
 	exitOnError("Unable to get the Submariner client", err)
 
+	return getGatewaysResourceWithClient(submarinerClient)
+}
+
+func getGatewaysResourceWithClient(submarinerClient subClientsetv1.Interface) *submarinerv1.GatewayList {
 	gateways, err := submarinerClient.SubmarinerV1().Gateways(OperatorNamespace).
 		List(context.TODO(), v1opts.ListOptions{})
 	if err != nil {
@@ -128,7 +128,27 @@ func getClusterNameFromContext(rawConfig clientcmdapi.Config, overridesContext s
 }
 
 func getRestConfig(kubeConfigPath, kubeContext string) (*rest.Config, error) {
-	return utils.GetRestConfig(kubeConfigPath, kubeContext)
+	clientConfig := getClientConfig(kubeConfigPath, kubeContext)
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	addRedactedNames(kubeContext)
+	addRedactedHost(config.Host)
+
+	// Without a kubeconfig context, the config is the in-cluster one
+	clusterName := inClusterName
+	if rawConfig, err := clientConfig.RawConfig(); err == nil {
+		if name := getClusterNameFromContext(rawConfig, kubeContext); name != nil {
+			clusterName = *name
+		}
+	}
+
+	watchThrottling(config, clusterName)
+
+	return config, addCABundle(config)
 }
 
 func getClientConfig(kubeConfigPath, kubeContext string) clientcmd.ClientConfig {
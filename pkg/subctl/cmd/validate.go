@@ -18,7 +18,23 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
+	smClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	subOperatorClientset "github.com/submariner-io/submariner-operator/pkg/client/clientset/versioned"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
 )
 
 var (
@@ -26,10 +42,416 @@ var (
 		Use:   "diagnose",
 		Short: "Run diagnostic checks on the Submariner deployment and report any issues",
 		Long:  "This command runs various diagnostic checks on the Submariner deployment and reports any issues",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			statusWriters := []io.Writer{}
+			if diagnoseOutputFile != "" {
+				var err error
+				diagnoseOutputFileWriter, err = os.Create(diagnoseOutputFile)
+				exitOnError("Error creating the output file", err)
+				statusWriters = append(statusWriters, diagnoseOutputFileWriter)
+			}
+
+			if diagnoseBundleFile != "" {
+				statusWriters = append(statusWriters, &bundleOutput)
+				diagnoseOutput = io.MultiWriter(os.Stdout, &bundleOutput)
+			}
+
+			if len(statusWriters) > 0 {
+				status = cli.NewStatusForWriter(io.MultiWriter(append([]io.Writer{os.Stderr}, statusWriters...)...))
+			}
+
+			status.ShowDurations()
+			apiThrottling = newThrottlingRecorder()
+
+			if diagnoseConcurrencyPerCluster < 1 {
+				exitWithErrorMsg(fmt.Sprintf("Invalid --concurrency-per-cluster %d, expected at least 1",
+					diagnoseConcurrencyPerCluster))
+			}
+
+			var err error
+			diagnosePodTolerations, err = parseDiagnosePodTolerations(diagnosePodTolerationSpecs)
+			exitOnError("Error parsing the pod tolerations", err)
+
+			diagnoseReportAuthorization, err = reportAuthorization(diagnoseReportAuthorization, diagnoseReportAuthorizationFile)
+			exitOnError("Error reading the report Authorization header", err)
+
+			if diagnoseRedact {
+				diagnoseRedactor, err = cli.NewRedactor()
+				exitOnError("Error setting the redaction up", err)
+
+				addRedactedNames(kubeContexts...)
+				addRedactedNames(brokerContext)
+				status.Redact(diagnoseRedactor)
+			}
+
+			if diagnoseQuiet {
+				status.Quiet()
+			}
+
+			// The structured results are needed for the report and the bundle, whatever the output format
+			if diagnoseReportURL != "" || diagnoseBundleFile != "" {
+				status.Record()
+			}
+
+			switch diagnoseOutputFormat {
+			case textOutputFormat:
+			case markdownOutputFormat:
+				status.Record()
+			case jsonOutputFormat:
+				if !listDiagnoseChecksOnly {
+					exitWithErrorMsg(fmt.Sprintf("The %s output format is only supported with --list-checks", jsonOutputFormat))
+				}
+			default:
+				exitWithErrorMsg(fmt.Sprintf("Unsupported output format %q, expected one of %s", diagnoseOutputFormat,
+					strings.Join([]string{textOutputFormat, markdownOutputFormat, jsonOutputFormat}, ",")))
+			}
+		},
+		// The commands which run checks close the output file in finishValidation, as they may exit with an error
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			closeDiagnoseOutputFile()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if !listDiagnoseChecksOnly {
+				exitOnError("Error printing the help", cmd.Help())
+				return
+			}
+
+			listDiagnoseChecks(diagnoseOutputFormat)
+		},
 	}
+
+	diagnoseOutputFile string
+	// the file given with --output-file, closed once the output is complete
+	diagnoseOutputFileWriter *os.File
+	diagnoseQuiet            bool
+	requireInstalled         bool
+	diagnoseRedact           bool
+	diagnoseRedactor         *cli.Redactor
+	diagnoseReadOnly         bool
+	diagnoseFailOnWarning    bool
+	// the format of the report printed once the checks have run, in addition to the status output
+	diagnoseOutputFormat string
+	// list the checks run by "diagnose all" instead of running them
+	listDiagnoseChecksOnly bool
+	// the URL to post the results to as JSON, and the Authorization header to post them with, given directly, in a file
+	// or in the reportAuthorizationEnv environment variable
+	diagnoseReportURL               string
+	diagnoseReportAuthorization     string
+	diagnoseReportAuthorizationFile string
+	// the gzipped tarball to archive the results and output of the checks into
+	diagnoseBundleFile string
+	// where the output which isn't part of a status phase is printed
+	diagnoseOutput io.Writer = os.Stdout
+	// additional CAs trusted for the API servers, e.g. those of TLS-inspecting proxies
+	diagnoseCABundle string
+	// the number of nodes the checks of a cluster probe at once; the clusters are checked one after the other
+	diagnoseConcurrencyPerCluster int
+
+	// the checks skipped because they need more than read access to the clusters
+	skippedReadOnlyChecks []string
 )
 
+// clusterClients holds the clients shared by the diagnostic checks run against a cluster
+type clusterClients struct {
+	kubeClient       kubernetes.Interface
+	submarinerClient smClientset.Interface
+	operatorClient   subOperatorClientset.Interface
+	dynamicClient    dynamic.Interface
+}
+
+// clusterClientsFactory creates the clients for a cluster; it allows the checks to be run with fake clients
+type clusterClientsFactory func(config *rest.Config) (*clusterClients, error)
+
 func init() {
+	validateCmd.PersistentFlags().StringVar(&diagnoseOutputFile, "output-file", "",
+		"also write the results of the checks to the given file")
+	validateCmd.PersistentFlags().BoolVar(&diagnoseQuiet, "quiet", false,
+		"only print the checks which fail or raise warnings, followed by a summary")
+	validateCmd.PersistentFlags().BoolVar(&requireInstalled, "require-installed", false,
+		"fail the checks of clusters where Submariner isn't installed instead of skipping them")
+	validateCmd.PersistentFlags().BoolVar(&diagnoseRedact, "redact", false,
+		"replace cluster names, IPs and CIDRs in the output with stable pseudonyms, so that it can be shared")
+	validateCmd.PersistentFlags().BoolVar(&diagnoseReadOnly, "read-only", false,
+		"only run the checks which need read access to the clusters, skipping those which create pods or execute"+
+			" commands in them")
+	validateCmd.PersistentFlags().BoolVar(&diagnoseFailOnWarning, "fail-on-warning", false,
+		"exit with a non-zero status if any check raises a warning, as well as when a check fails")
+	validateCmd.PersistentFlags().StringVar(&diagnoseOutputFormat, "output", textOutputFormat,
+		fmt.Sprintf("format of the results - any of %s; with markdown, a report is also printed on the standard output",
+			strings.Join([]string{textOutputFormat, markdownOutputFormat, jsonOutputFormat}, ",")))
+	validateCmd.PersistentFlags().StringVar(&diagnoseReportURL, "report-url", "",
+		"also post the results of the checks as JSON to the given URL once they have run")
+	validateCmd.PersistentFlags().StringVar(&diagnoseReportAuthorization, "report-auth-header", "",
+		"value of the Authorization header sent with the results posted to --report-url, e.g. \"Bearer <token>\"; as"+
+			" flags are visible to the other users of the host, prefer --report-auth-header-file or the "+
+			reportAuthorizationEnv+" environment variable")
+	validateCmd.PersistentFlags().StringVar(&diagnoseReportAuthorizationFile, "report-auth-header-file", "",
+		"file containing the value of the Authorization header sent with the results posted to --report-url, e.g. a"+
+			" mounted secret")
+	validateCmd.PersistentFlags().StringVar(&diagnoseBundleFile, "bundle", "",
+		"also archive the results of the checks as JSON, along with their output including any logs and events shown,"+
+			" into the given .tar.gz file; use --redact to archive them redacted")
+	validateCmd.PersistentFlags().StringVar(&diagnoseCABundle, "ca-bundle", "",
+		"PEM file with additional CA certificates to trust for the member and broker API servers, e.g. those of a"+
+			" TLS-inspecting proxy")
+	validateCmd.PersistentFlags().IntVar(&diagnoseConcurrencyPerCluster, "concurrency-per-cluster", 4,
+		"maximum number of nodes the checks of a cluster probe at once, running commands or pods on them; lower it"+
+			" for clusters with tight API server QPS budgets, 1 probes the nodes one after the other. The clusters are"+
+			" checked one after the other")
+	validateCmd.PersistentFlags().StringVar(&submarinerResourceName, "submariner-name", submarinerResourceName,
+		"name of the Submariner resource, for installations which renamed it")
+	validateCmd.PersistentFlags().StringVar(&submarinerResourceNamespace, "submariner-namespace", submarinerResourceNamespace,
+		"namespace of the Submariner resource")
+	validateCmd.PersistentFlags().StringVar(&submarinerResourceSelector, "submariner-selector", "",
+		"label selector identifying the Submariner resource in its namespace, instead of its name")
+	validateCmd.Flags().BoolVar(&listDiagnoseChecksOnly, "list-checks", false,
+		"list the checks run by \"diagnose all\", with the permissions they need and whether they're disruptive;"+
+			" use --output json for a machine-readable list")
+	addDiagnosePodPlacementFlags(validateCmd)
 	addKubeContextFlag(validateCmd)
 	rootCmd.AddCommand(validateCmd)
 }
+
+func newClusterClients(config *rest.Config) (*clusterClients, error) {
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	submarinerClient, err := smClientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	operatorClient, err := subOperatorClientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clusterClients{kubeClient: kubeClient, submarinerClient: submarinerClient, operatorClient: operatorClient,
+		dynamicClient: dynamicClient}, nil
+}
+
+// reportMissingSubmariner ends the current status for a cluster without Submariner, as a failure if Submariner is
+// required; it returns false in that case
+func reportMissingSubmariner() bool {
+	if requireInstalled {
+		status.QueueFailureMessageWithCode(codeSubmarinerNotInstalled, submMissingMessage)
+		status.End(cli.Failure)
+
+		return false
+	}
+
+	status.QueueWarningMessageWithCode(codeSubmarinerNotInstalled, submMissingMessage)
+	status.End(cli.Success)
+
+	return true
+}
+
+// retrieveSubmariner retrieves the Submariner resource of a diagnosed cluster, returning nil without an error if it
+// isn't installed. Unlike with getSubmarinerResource, other errors, e.g. from an unreachable API server, aren't fatal so
+// that the other clusters are still diagnosed.
+func retrieveSubmariner(config *rest.Config) (*v1alpha1.Submariner, error) {
+	return submarinerResourceOrError(getSubmarinerResourceWithError(config))
+}
+
+// reportUnavailableSubmariner ends the status of a cluster whose Submariner resource couldn't be retrieved: an error
+// fails the cluster, a missing resource is reported by reportMissingSubmariner
+func reportUnavailableSubmariner(err error) bool {
+	if err == nil {
+		return reportMissingSubmariner()
+	}
+
+	status.QueueFailureMessageWithCode(codeSubmarinerUnavailable, fmt.Sprintf("Error obtaining the Submariner resource: %s", err))
+	status.End(cli.Failure)
+
+	return false
+}
+
+// newCheckClients creates the clients of a cluster for its checks using newClients; if they can't be created, the
+// failure is reported in a phase of its own and nil is returned, so that the other clusters are still diagnosed
+func newCheckClients(clusterName string, config *rest.Config, newClients clusterClientsFactory) *clusterClients {
+	clients, err := newClients(config)
+	if err == nil {
+		return clients
+	}
+
+	status.Start(fmt.Sprintf("Creating the clients for cluster %q", clusterName))
+	status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating the clients: %s", err))
+	status.End(cli.Failure)
+
+	return nil
+}
+
+// addRedactedNames registers sensitive names, such as cluster names and IDs, to be redacted from the diagnose output
+// when redaction is enabled
+func addRedactedNames(names ...string) {
+	if diagnoseRedactor == nil {
+		return
+	}
+
+	for _, name := range names {
+		diagnoseRedactor.AddName(name)
+	}
+}
+
+// addRedactedHost registers the host name of an API server URL to be redacted from the diagnose output
+func addRedactedHost(apiServer string) {
+	if u, err := url.Parse(apiServer); err == nil && u.Hostname() != "" {
+		addRedactedNames(u.Hostname())
+	} else {
+		addRedactedNames(apiServer)
+	}
+}
+
+// addCABundle adds the CA certificates of the CA bundle, if any, to those trusted by the config. The config's own CA
+// is kept; without one, the bundle replaces the system CAs.
+func addCABundle(config *rest.Config) error {
+	if diagnoseCABundle == "" || config.Insecure {
+		return nil
+	}
+
+	bundle, err := ioutil.ReadFile(diagnoseCABundle)
+	if err != nil {
+		return fmt.Errorf("error reading the CA bundle: %s", err)
+	}
+
+	caData := config.CAData
+	if len(caData) == 0 && config.CAFile != "" {
+		if caData, err = ioutil.ReadFile(config.CAFile); err != nil {
+			return fmt.Errorf("error reading the CA file %q: %s", config.CAFile, err)
+		}
+	}
+
+	if len(caData) > 0 && !bytes.HasSuffix(caData, []byte("\n")) {
+		caData = append(caData, '\n')
+	}
+
+	config.CAData = append(caData, bundle...)
+	config.CAFile = ""
+
+	return nil
+}
+
+// skipInReadOnlyMode is called by the checks which create pods or execute commands in them, after starting their
+// status; in read-only mode, it ends the status as skipped and returns true so that the check is skipped
+func skipInReadOnlyMode(check, clusterName string) bool {
+	if !diagnoseReadOnly {
+		return false
+	}
+
+	status.Skip("Skipped in read-only mode, as this check creates pods or executes commands in them")
+
+	skippedReadOnlyChecks = append(skippedReadOnlyChecks, fmt.Sprintf("%s in %q", check, clusterName))
+
+	return true
+}
+
+// reportReadOnlySkips lists the checks which were skipped in read-only mode
+func reportReadOnlySkips() {
+	if len(skippedReadOnlyChecks) == 0 {
+		return
+	}
+
+	status.Start("Listing the checks skipped in read-only mode")
+	status.Skip(fmt.Sprintf("%d checks were skipped as they need to create pods or execute commands in"+
+		" them: %s", len(skippedReadOnlyChecks), strings.Join(skippedReadOnlyChecks, ", ")))
+}
+
+// diagnosePrintf prints diagnose output which isn't part of a status phase, redacting it if required
+func diagnosePrintf(format string, args ...interface{}) {
+	fmt.Fprint(diagnoseOutput, status.Redacted(fmt.Sprintf(format, args...)))
+}
+
+// diagnoseSeparator prints a blank line between the checks, except in quiet mode which only displays the checks
+// which fail or raise warnings
+func diagnoseSeparator() {
+	if !diagnoseQuiet {
+		diagnosePrintf("\n")
+	}
+}
+
+// finishValidation reports any API server throttling and skipped checks, prints the summary of the checks in quiet
+// mode, and exits with an error if a check failed
+func finishValidation(validationStatus bool) {
+	checkPhases := len(status.Recorded())
+	// Throttling warnings are about the diagnose run rather than the clusters, so they don't fail it
+	warnedPhases := status.WarnedPhases()
+
+	reportThrottling()
+	reportReadOnlySkips()
+
+	if diagnoseFailOnWarning && warnedPhases > 0 {
+		status.Start("Checking for warnings")
+		status.QueueFailureMessageWithCode(codeFailedOnWarning,
+			fmt.Sprintf("%d checks raised warnings, which fail the validation with --fail-on-warning", warnedPhases))
+		status.End(cli.Failure)
+
+		validationStatus = false
+	}
+
+	if diagnoseOutputFormat == markdownOutputFormat {
+		printMarkdownReport(status.Recorded(), checkPhases)
+	}
+
+	// The report is built before the phases posting and archiving it start, as retrieving the recorded phases ends
+	// the current one
+	var report *jsonReport
+	if diagnoseReportURL != "" || diagnoseBundleFile != "" {
+		report = newJSONReport(status.Recorded(), checkPhases, validationStatus)
+	}
+
+	// A failure to post the results doesn't change the outcome of the checks
+	if diagnoseReportURL != "" {
+		status.Start("Posting the results to the report URL")
+
+		err := postJSONReport(diagnoseReportURL, diagnoseReportAuthorization, report)
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeReportFailed, fmt.Sprintf("Error posting the results: %s", err))
+			status.End(cli.Warning)
+		} else {
+			status.End(cli.Success)
+		}
+	}
+
+	// Likewise, a failure to write the bundle doesn't change the outcome
+	if diagnoseBundleFile != "" {
+		status.Start(fmt.Sprintf("Archiving the results to %q", diagnoseBundleFile))
+
+		err := writeDiagnoseBundle(diagnoseBundleFile, report, bundleOutput.Bytes())
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeReportFailed, fmt.Sprintf("Error writing the bundle: %s", err))
+			status.End(cli.Warning)
+		} else {
+			status.End(cli.Success)
+		}
+	}
+
+	if diagnoseQuiet {
+		status.PrintSummary()
+	}
+
+	closeDiagnoseOutputFile()
+
+	if !validationStatus {
+		os.Exit(1)
+	}
+}
+
+// closeDiagnoseOutputFile closes the file given with --output-file, if any, once the output is complete
+func closeDiagnoseOutputFile() {
+	if diagnoseOutputFileWriter == nil {
+		return
+	}
+
+	if err := diagnoseOutputFileWriter.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error closing the output file %q: %s\n", diagnoseOutputFile, err)
+	}
+
+	diagnoseOutputFileWriter = nil
+}
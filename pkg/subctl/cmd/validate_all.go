@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
@@ -28,55 +27,112 @@ import (
 var validateAllCmd = &cobra.Command{
 	Use:   "all",
 	Short: "Run all diagnostic checks (except those requiring two kubecontexts)",
-	Long:  "This command runs all diagnostic checks (except those requiring two kubecontexts) and reports any issues",
-	Run:   validateAll,
+	Long: "This command runs all diagnostic checks (except those requiring two kubecontexts) and reports any issues. " +
+		"The --profile flag restricts the checks to a predefined group: network, platform or security.",
+	Run: validateAll,
 }
 
 func init() {
+	addConnectionsTopologyFlag(validateAllCmd)
+	addFailedComponentLogsFlag(validateAllCmd)
+	addNodeCIDRsFlag(validateAllCmd)
+	addFailedComponentEventsFlag(validateAllCmd)
+	addServiceDiscoveryConsistencyFlags(validateAllCmd)
+	addGlobalnetUtilizationFlags(validateAllCmd)
+	addGatewayHAModeFlags(validateAllCmd)
+	addExpectedCIDRsFlag(validateAllCmd)
+	addGatewayEgressFlags(validateAllCmd)
+	addReconcileTimeoutFlag(validateAllCmd)
+	addProfileFlag(validateAllCmd)
+	validateAllCmd.Flags().BoolVar(&checkIntraClusterFirst, "intra-cluster", false,
+		"check the pod-to-pod connectivity within each cluster first, skipping its other checks if it fails")
 	validateCmd.AddCommand(validateAllCmd)
 }
 
 func validateAll(cmd *cobra.Command, args []string) {
+	profileChecks, err := selectDiagnoseProfile(diagnoseProfile)
+	exitOnError("Error selecting the checks", err)
+
 	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
 	exitOnError("Error getting REST config for cluster", err)
 
 	validationStatus := true
+	broker := findBrokerMember(configs, newClusterClients)
 
 	for _, item := range configs {
-		validationStatus = validationStatus && validateK8sVersionInCluster(item.config, item.clusterName)
-		fmt.Println()
-
-		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
-		submariner := getSubmarinerResource(item.config)
-		if submariner == nil {
-			status.QueueWarningMessage(submMissingMessage)
-			status.End(cli.Success)
-			fmt.Println()
+		status.SetCluster(item.clusterName)
+		if checkIntraClusterFirst && !validateIntraClusterConnectivity(item.config, item.clusterName) {
+			validationStatus = false
+			diagnoseSeparator()
+			continue
+		}
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			diagnoseSeparator()
+			continue
+		}
+
+		target := &clusterCheckTarget{config: item.config, clusterName: item.clusterName, clients: clients, broker: broker}
+		installed := true
+
+		for i := range diagnoseChecks {
+			check := &diagnoseChecks[i]
+			if check.AcrossClusters || !inDiagnoseProfile(check, profileChecks) {
+				continue
+			}
+
+			if check.RequiresSubmariner && target.submariner == nil {
+				if installed, err = retrieveCheckedSubmariner(target); !installed {
+					validationStatus = reportUnavailableSubmariner(err) && validationStatus
+					diagnoseSeparator()
+					break
+				}
+			}
+
+			validationStatus = check.run(target) && validationStatus
+			diagnoseSeparator()
+		}
+
+		if !installed || (diagnoseProfile != fullProfile && diagnoseProfile != networkProfile) {
 			continue
 		}
-		status.End(cli.Success)
-		fmt.Println()
-
-		validationStatus = validationStatus && validateCNIInCluster(item.config, item.clusterName, submariner)
-		fmt.Println()
-		validationStatus = validationStatus && validateConnectionsInCluster(item.config, item.clusterName)
-		fmt.Println()
-		validationStatus = validationStatus && checkPods(item, submariner, OperatorNamespace)
-		fmt.Println()
-		validationStatus = validationStatus && checkOverlappingCIDRs(item, submariner)
-		fmt.Println()
-		validationStatus = validationStatus && validateKubeProxyModeInCluster(item.config, item.clusterName)
-		fmt.Println()
-		validationStatus = validationStatus && validateFirewallMetricsConfigWithinCluster(item.config, item.clusterName)
-		fmt.Println()
-		validationStatus = validationStatus && validateVxLANConfigWithinCluster(item.config, item.clusterName, submariner)
-		fmt.Println()
-		fmt.Printf("Skipping tunnel firewall check as it requires two kubeconfigs." +
+
+		diagnosePrintf("Skipping tunnel firewall check as it requires two kubeconfigs." +
 			" Please run \"subctl diagnose firewall tunnel\" command manually.\n")
-		fmt.Println()
+		diagnoseSeparator()
 	}
 
-	if !validationStatus {
-		os.Exit(1)
+	status.SetCluster("")
+
+	if len(configs) > 1 {
+		for i := range diagnoseChecks {
+			if diagnoseChecks[i].AcrossClusters && inDiagnoseProfile(&diagnoseChecks[i], profileChecks) {
+				validationStatus = diagnoseChecks[i].runAcross(configs) && validationStatus
+				diagnoseSeparator()
+			}
+		}
 	}
+
+	finishValidation(validationStatus)
+}
+
+// retrieveCheckedSubmariner retrieves the Submariner resource of the cluster, for the checks which require Submariner;
+// it returns false, leaving the status started, if Submariner isn't installed or couldn't be retrieved, along with the
+// error if any
+func retrieveCheckedSubmariner(target *clusterCheckTarget) (bool, error) {
+	status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", target.clusterName))
+
+	submariner, err := retrieveSubmariner(target.config)
+	if submariner == nil {
+		return false, err
+	}
+
+	target.submariner = submariner
+
+	status.End(cli.Success)
+	diagnoseSeparator()
+
+	return true, nil
 }
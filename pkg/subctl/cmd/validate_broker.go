@@ -0,0 +1,510 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	submarinerv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/cidr"
+	smClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	mcsv1a1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/broker"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/datafile"
+)
+
+// brokerCRD is a CRD the member clusters sync through the broker, along with the version the member operator uses
+type brokerCRD struct {
+	name    string
+	version string
+}
+
+var brokerCRDs = []brokerCRD{
+	{name: "clusters.submariner.io", version: submarinerv1.SchemeGroupVersion.Version},
+	{name: "endpoints.submariner.io", version: submarinerv1.SchemeGroupVersion.Version},
+	{name: "serviceimports.multicluster.x-k8s.io", version: mcsv1a1.GroupVersion.Version},
+}
+
+var (
+	brokerContext           string
+	diagnoseBrokerNamespace string
+)
+
+var validateBrokerCmd = &cobra.Command{
+	Use:   "broker",
+	Short: "Check the broker resources",
+	Long: "This command checks the broker cluster using its own kubeconfig: the broker namespace and secrets, the" +
+		" globalnet configuration, the CRDs and their versions, the consistency of the synced Cluster and Endpoint resources, that" +
+		" the global CIDR range doesn't overlap the clusters' pod and service CIDRs, that the global CIDRs allocated to" +
+		" the clusters don't overlap each other, and that the globalnet allocations match" +
+		" the joined clusters. It also checks that the member clusters found in the kubeconfig which use this broker" +
+		" have registered a Cluster resource with it, and reports the Cluster resources which don't belong to any of them.",
+	Run: validateBroker,
+}
+
+func init() {
+	addBrokerFlags(validateBrokerCmd)
+	validateCmd.AddCommand(validateBrokerCmd)
+}
+
+func addBrokerFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&brokerContext, "broker-context", "",
+		"kubeconfig context for the broker cluster")
+	cmd.Flags().StringVar(&diagnoseBrokerNamespace, "broker-namespace", broker.SubmarinerBrokerNamespace,
+		"namespace of the broker")
+}
+
+func validateBroker(cmd *cobra.Command, args []string) {
+	config, err := getRestConfig(kubeConfig, brokerContext)
+	exitOnError("Error getting REST config for the broker cluster", err)
+
+	members, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := validateBrokerInCluster(config, diagnoseBrokerNamespace)
+	validationStatus = validateBrokerClusterRegistrations(config, diagnoseBrokerNamespace, members) && validationStatus
+
+	finishValidation(validationStatus)
+}
+
+// validateBrokerClusterRegistrations cross-references the Cluster resources on the broker with the member clusters,
+// i.e. the clusters whose Submariner resource uses this broker: a member without a Cluster resource never fully joined, while a Cluster
+// resource without a member may be left over from a cluster which didn't leave cleanly
+func validateBrokerClusterRegistrations(config *rest.Config, namespace string, members []restConfig) bool {
+	status.Start("Checking the Cluster resources registered with the broker against the member clusters")
+
+	submarinerClient, err := smClientset.NewForConfig(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Unable to get the Submariner client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	clusters, err := submarinerClient.SubmarinerV1().Clusters(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner clusters: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	registered := stringset.New()
+	for i := range clusters.Items {
+		registered.Add(clusters.Items[i].Spec.ClusterID)
+	}
+
+	memberIDs := stringset.New()
+	unreachable := 0
+	otherBrokers := 0
+	brokerAPIServer := apiServerAddress(config.Host)
+
+	for _, item := range members {
+		submariner, err := retrieveSubmariner(item.config)
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Unable to obtain the Submariner resource from cluster %q, so its"+
+				" registration isn't checked: %s", item.clusterName, err))
+			unreachable++
+			continue
+		}
+
+		if submariner == nil {
+			continue
+		}
+
+		if !usesBroker(submariner, brokerAPIServer, namespace) {
+			otherBrokers++
+			continue
+		}
+
+		memberIDs.Add(submariner.Spec.ClusterID)
+
+		if !registered.Contains(submariner.Spec.ClusterID) {
+			status.QueueFailureMessageWithCode(codeClusterNotRegistered, fmt.Sprintf("The member cluster %q (context %q) has"+
+				" no Cluster resource on the broker; it didn't fully join", submariner.Spec.ClusterID, item.clusterName))
+		}
+	}
+
+	orphaned := []string{}
+	for _, clusterID := range registered.Elements() {
+		if !memberIDs.Contains(clusterID) {
+			orphaned = append(orphaned, clusterID)
+		}
+	}
+
+	sort.Strings(orphaned)
+
+	// The kubeconfig may not cover all the members, so the orphaned Clusters are only reported as warnings
+	if len(orphaned) > 0 {
+		message := fmt.Sprintf("The Cluster resources of clusters %v don't belong to any member cluster in the kubeconfig;"+
+			" they may be left over from clusters which didn't leave cleanly", orphaned)
+		if unreachable > 0 {
+			message += fmt.Sprintf(", or belong to the %d unreachable clusters", unreachable)
+		}
+
+		status.QueueWarningMessageWithCode(codeClusterOrphaned, message)
+	}
+
+	if otherBrokers > 0 {
+		status.QueueSuccessMessage(fmt.Sprintf("The %d clusters in the kubeconfig which use another broker aren't checked",
+			otherBrokers))
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage(fmt.Sprintf("The %d member clusters are registered with the broker", memberIDs.Size()))
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+// usesBroker returns true if the Submariner resource was joined to the broker with the given API server and namespace
+func usesBroker(submariner *v1alpha1.Submariner, apiServer, namespace string) bool {
+	return submariner.Spec.BrokerK8sApiServer != "" && apiServerAddress(submariner.Spec.BrokerK8sApiServer) == apiServer &&
+		submariner.Spec.BrokerK8sRemoteNamespace == namespace
+}
+
+func validateBrokerInCluster(config *rest.Config, namespace string) bool {
+	status.Start(fmt.Sprintf("Checking the broker in namespace %q", namespace))
+
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	_, err = clientSet.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the broker namespace %q: %s", namespace, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	checkBrokerGlobalnetConfigMap(clientSet, namespace)
+	checkBrokerSecrets(clientSet, namespace)
+
+	apiExtClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+	} else {
+		checkBrokerCRDs(apiExtClient)
+	}
+
+	submarinerClient, err := smClientset.NewForConfig(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Unable to get the Submariner client: %s", err))
+	} else {
+		checkBrokerClustersAndEndpoints(submarinerClient, namespace)
+		checkGlobalnetRangeOverlaps(clientSet, submarinerClient, namespace)
+		checkGlobalnetClusterInfoEntries(clientSet, submarinerClient, namespace)
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("The broker resources are valid")
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+func checkBrokerGlobalnetConfigMap(clientSet kubernetes.Interface, namespace string) {
+	stored, err := broker.ReadGlobalnetConfigMap(clientSet, namespace)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the globalnet ConfigMap %q: %s",
+			broker.GlobalCIDRConfigMapName, err))
+		return
+	}
+
+	// The legacy encodings are only migrated in memory, the diagnosis mustn't modify the broker
+	configMap := stored.DeepCopy()
+
+	migrated, err := broker.MigrateGlobalnetConfigMap(configMap)
+	if err != nil {
+		// The stored data is validated as is, to locate the corruption which prevents the migration
+		problems := broker.ValidateGlobalnetConfigMap(stored)
+		if len(problems) == 0 {
+			problems = append(problems, err)
+		}
+
+		queueGlobalnetConfigMapProblems(problems)
+
+		return
+	}
+
+	if migrated {
+		status.QueueWarningMessageWithCode(codeGlobalnetConfigMapLegacy, fmt.Sprintf("The globalnet ConfigMap %q uses"+
+			" a legacy encoding and needs migrating; it will be migrated the next time a cluster joins",
+			broker.GlobalCIDRConfigMapName))
+	}
+
+	queueGlobalnetConfigMapProblems(broker.ValidateGlobalnetConfigMap(configMap))
+
+	problems, overlaps, warnings := broker.ValidateGlobalnetAllocations(configMap)
+	for _, problem := range problems {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRAllocationInvalid, fmt.Sprintf("Invalid globalnet allocation: %s",
+			problem))
+	}
+
+	for _, overlap := range overlaps {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRAllocationOverlap, fmt.Sprintf("Overlapping globalnet allocation: %s",
+			overlap))
+	}
+
+	for _, warning := range warnings {
+		status.QueueWarningMessageWithCode(codeGlobalCIDRSizeMismatch, fmt.Sprintf("Globalnet allocation inconsistent with"+
+			" the current cluster size: %s", warning))
+	}
+}
+
+func queueGlobalnetConfigMapProblems(problems []error) {
+	for _, problem := range problems {
+		status.QueueFailureMessageWithCode(codeGlobalnetConfigMapMalformed, fmt.Sprintf("The globalnet ConfigMap %q is"+
+			" malformed: %s", broker.GlobalCIDRConfigMapName, problem))
+	}
+}
+
+func checkBrokerSecrets(clientSet kubernetes.Interface, namespace string) {
+	_, err := broker.GetClientTokenSecret(clientSet, namespace, broker.SubmarinerBrokerAdminSA)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the token secret for the %q service account: %s",
+			broker.SubmarinerBrokerAdminSA, err))
+	}
+
+	// The PSK is normally only distributed to the clusters through the broker-info.subm file
+	_, err = datafile.GetIPSECPSKSecret(clientSet, namespace)
+	if apierrors.IsNotFound(err) {
+		status.QueueSuccessMessage("The IPsec PSK secret isn't stored on the broker; it is distributed in the broker" +
+			" information file")
+	} else if err != nil {
+		status.QueueWarningMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the IPsec PSK secret: %s", err))
+	}
+}
+
+func checkBrokerCRDs(apiExtClient clientset.Interface) {
+	for _, expected := range brokerCRDs {
+		crd, err := apiExtClient.ApiextensionsV1().CustomResourceDefinitions().Get(context.TODO(), expected.name, metav1.GetOptions{})
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the CRD %q: %s", expected.name, err))
+			continue
+		}
+
+		if !isCRDEstablished(crd) {
+			status.QueueFailureMessageWithCode(codeBrokerCRDNotEstablished, fmt.Sprintf("The CRD %q is not established",
+				expected.name))
+		}
+
+		if served := servedCRDVersions(crd); !served.Contains(expected.version) {
+			status.QueueWarningMessageWithCode(codeBrokerCRDVersionMismatch, fmt.Sprintf("The CRD %q on the broker serves"+
+				" versions %v but the member operators use version %q; the broker and the members were probably upgraded separately", expected.name,
+				served.Elements(), expected.version))
+		}
+	}
+}
+
+func servedCRDVersions(crd *apiextensionsv1.CustomResourceDefinition) stringset.Interface {
+	served := stringset.New()
+
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Served {
+			served.Add(crd.Spec.Versions[i].Name)
+		}
+	}
+
+	return served
+}
+
+func isCRDEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established {
+			return condition.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// checkBrokerClustersAndEndpoints checks that every cluster which joined the broker has both a Cluster and an
+// Endpoint resource
+func checkBrokerClustersAndEndpoints(submarinerClient smClientset.Interface, namespace string) {
+	clusters, err := submarinerClient.SubmarinerV1().Clusters(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner clusters: %s", err))
+		return
+	}
+
+	endpoints, err := submarinerClient.SubmarinerV1().Endpoints(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner endpoints: %s", err))
+		return
+	}
+
+	clusterIDs := stringset.New()
+	for i := range clusters.Items {
+		clusterIDs.Add(clusters.Items[i].Spec.ClusterID)
+	}
+
+	endpointClusterIDs := stringset.New()
+	for i := range endpoints.Items {
+		endpointClusterIDs.Add(endpoints.Items[i].Spec.ClusterID)
+
+		if !clusterIDs.Contains(endpoints.Items[i].Spec.ClusterID) {
+			status.QueueWarningMessageWithCode(codeBrokerEndpointOrphaned, fmt.Sprintf("The Endpoint %q refers to cluster %q,"+
+				" which has no Cluster resource",
+				endpoints.Items[i].Name, endpoints.Items[i].Spec.ClusterID))
+		}
+	}
+
+	for i := range clusters.Items {
+		if !endpointClusterIDs.Contains(clusters.Items[i].Spec.ClusterID) {
+			status.QueueWarningMessageWithCode(codeBrokerEndpointMissing, fmt.Sprintf("The cluster %q has no Endpoint resource",
+				clusters.Items[i].Spec.ClusterID))
+		}
+	}
+}
+
+// checkGlobalnetRangeOverlaps checks that the global CIDR range doesn't overlap the pod and service CIDRs of any
+// cluster, which would break the globalnet NAT
+func checkGlobalnetRangeOverlaps(clientSet *kubernetes.Clientset, submarinerClient smClientset.Interface, namespace string) {
+	globalnetInfo, _, err := globalnet.ReadGlobalNetworks(clientSet, namespace)
+	if err != nil {
+		// Already reported by the globalnet ConfigMap check
+		return
+	}
+
+	if !globalnetInfo.GlobalnetEnabled {
+		return
+	}
+
+	clusters, err := submarinerClient.SubmarinerV1().Clusters(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner clusters: %s", err))
+		return
+	}
+
+	for i := range clusters.Items {
+		spec := &clusters.Items[i].Spec
+
+		checkGlobalnetRangeOverlap(globalnetInfo.GlobalnetCidrRange, spec.ClusterID, "pod", spec.ClusterCIDR)
+		checkGlobalnetRangeOverlap(globalnetInfo.GlobalnetCidrRange, spec.ClusterID, "service", spec.ServiceCIDR)
+	}
+}
+
+// checkGlobalnetClusterInfoEntries checks that the globalnet ConfigMap has an allocation for every joined cluster, i.e.
+// every cluster with a Cluster or Endpoint resource, and no allocation left over from clusters which are gone
+func checkGlobalnetClusterInfoEntries(clientSet *kubernetes.Clientset, submarinerClient smClientset.Interface, namespace string) {
+	globalnetInfo, _, err := globalnet.ReadGlobalNetworks(clientSet, namespace)
+	if err != nil {
+		// Already reported by the globalnet ConfigMap check
+		return
+	}
+
+	if !globalnetInfo.GlobalnetEnabled {
+		return
+	}
+
+	clusters, err := submarinerClient.SubmarinerV1().Clusters(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner clusters: %s", err))
+		return
+	}
+
+	endpoints, err := submarinerClient.SubmarinerV1().Endpoints(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner endpoints: %s", err))
+		return
+	}
+
+	joined := stringset.New()
+	for i := range clusters.Items {
+		joined.Add(clusters.Items[i].Spec.ClusterID)
+	}
+
+	for i := range endpoints.Items {
+		joined.Add(endpoints.Items[i].Spec.ClusterID)
+	}
+
+	allocated := stringset.New()
+	for clusterID := range globalnetInfo.GlobalCidrInfo {
+		allocated.Add(clusterID)
+	}
+
+	var stale, unallocated []string
+
+	for _, clusterID := range allocated.Elements() {
+		if !joined.Contains(clusterID) {
+			stale = append(stale, clusterID)
+		}
+	}
+
+	for _, clusterID := range joined.Elements() {
+		if !allocated.Contains(clusterID) {
+			unallocated = append(unallocated, clusterID)
+		}
+	}
+
+	if len(stale) == 0 && len(unallocated) == 0 {
+		return
+	}
+
+	sort.Strings(stale)
+	sort.Strings(unallocated)
+
+	if len(stale) > 0 {
+		status.QueueWarningMessageWithCode(codeGlobalCIDRStaleAllocation, fmt.Sprintf("The globalnet ConfigMap has %d"+
+			" allocations for %d joined clusters; the allocations of clusters %v are stale, these clusters have no Cluster"+
+			" or Endpoint resource",
+			allocated.Size(), joined.Size(), stale))
+	}
+
+	if len(unallocated) > 0 {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRUnallocated, fmt.Sprintf("The globalnet ConfigMap has %d"+
+			" allocations for %d joined clusters; the joined clusters %v have no globalnet allocation", allocated.Size(),
+			joined.Size(), unallocated))
+	}
+}
+
+func checkGlobalnetRangeOverlap(globalnetCIDRRange, clusterID, kind string, cidrs []string) {
+	overlap, err := cidr.IsOverlapping(cidrs, globalnetCIDRRange)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCIDRInvalid, fmt.Sprintf("Error checking the %s CIDRs %v of cluster"+
+			" %q: %s", kind, cidrs, clusterID, err))
+		return
+	}
+
+	if overlap {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRRangeOverlap, fmt.Sprintf("The global CIDR range %q overlaps the %s"+
+			" CIDRs %v of cluster %q", globalnetCIDRRange, kind, cidrs, clusterID))
+	}
+}
@@ -0,0 +1,169 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateBrokerChecksumCmd = &cobra.Command{
+	Use:   "broker-checksum",
+	Short: "Check that all the clusters joined the same broker",
+	Long: "This command computes a checksum of the broker settings imported by each cluster (the broker API server," +
+		" its CA, the broker namespace, the IPsec PSK and whether Globalnet is enabled) and reports the clusters whose" +
+		" checksum differs from the others.",
+	Run: validateBrokerChecksum,
+}
+
+// brokerSetting is a broker-derived setting of the Submariner resource included in the broker checksum
+type brokerSetting struct {
+	name  string
+	value func(spec *v1alpha1.SubmarinerSpec) string
+}
+
+var brokerSettings = []brokerSetting{
+	{name: "broker API server", value: func(spec *v1alpha1.SubmarinerSpec) string { return spec.BrokerK8sApiServer }},
+	{name: "broker CA", value: func(spec *v1alpha1.SubmarinerSpec) string { return spec.BrokerK8sCA }},
+	{name: "broker namespace", value: func(spec *v1alpha1.SubmarinerSpec) string { return spec.BrokerK8sRemoteNamespace }},
+	{name: "IPsec PSK", value: func(spec *v1alpha1.SubmarinerSpec) string { return spec.CeIPSecPSK }},
+	{name: "Globalnet", value: func(spec *v1alpha1.SubmarinerSpec) string { return strconv.FormatBool(spec.GlobalCIDR != "") }},
+}
+
+// clusterBrokerChecksum records the broker checksum of a cluster, along with the digest of each setting
+type clusterBrokerChecksum struct {
+	clusterName string
+	checksum    string
+	digests     []string
+}
+
+func init() {
+	validateCmd.AddCommand(validateBrokerChecksumCmd)
+}
+
+func validateBrokerChecksum(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	finishValidation(validateBrokerChecksumAcrossClusters(configs))
+}
+
+// brokerChecksum returns a stable checksum of the broker-derived settings of a Submariner resource, along with the
+// digest of each setting; secrets such as the PSK can't be recovered from either
+func brokerChecksum(spec *v1alpha1.SubmarinerSpec) (string, []string) {
+	digests := make([]string, len(brokerSettings))
+	checksum := sha256.New()
+
+	for i, setting := range brokerSettings {
+		digest := sha256.Sum256([]byte(setting.value(spec)))
+		digests[i] = hex.EncodeToString(digest[:])
+		checksum.Write(digest[:])
+	}
+
+	return hex.EncodeToString(checksum.Sum(nil))[:12], digests
+}
+
+// validateBrokerChecksumAcrossClusters compares the broker checksums of the clusters, and reports the clusters whose
+// checksum differs from the one shared by most of the clusters
+func validateBrokerChecksumAcrossClusters(configs []restConfig) bool {
+	status.Start("Checking that the clusters imported the same broker settings")
+
+	checksums := []clusterBrokerChecksum{}
+	counts := map[string]int{}
+
+	for _, item := range configs {
+		submariner, err := retrieveSubmariner(item.config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the Submariner resource from"+
+				" cluster %q: %s", item.clusterName, err))
+			continue
+		}
+
+		if submariner == nil {
+			if requireInstalled {
+				status.QueueFailureMessageWithCode(codeSubmarinerNotInstalled, fmt.Sprintf("Submariner is not installed in"+
+					" cluster %q", item.clusterName))
+			}
+
+			continue
+		}
+
+		checksum, digests := brokerChecksum(&submariner.Spec)
+		checksums = append(checksums, clusterBrokerChecksum{clusterName: item.clusterName, checksum: checksum, digests: digests})
+		counts[checksum]++
+	}
+
+	if len(counts) > 1 {
+		reference := mostCommonBrokerChecksum(checksums, counts)
+
+		for _, cluster := range checksums {
+			if cluster.checksum == reference.checksum {
+				continue
+			}
+
+			differing := []string{}
+			for i := range brokerSettings {
+				if cluster.digests[i] != reference.digests[i] {
+					differing = append(differing, brokerSettings[i].name)
+				}
+			}
+
+			status.QueueFailureMessageWithCode(codeBrokerChecksumMismatch, fmt.Sprintf("Cluster %q has broker checksum %s"+
+				" instead of %s (differing settings: %s)",
+				cluster.clusterName, cluster.checksum, reference.checksum, strings.Join(differing, ", ")))
+		}
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	for _, cluster := range checksums {
+		status.QueueSuccessMessage(fmt.Sprintf("Cluster %q has broker checksum %s", cluster.clusterName, cluster.checksum))
+	}
+
+	status.End(cli.Success)
+	return true
+}
+
+// mostCommonBrokerChecksum returns a cluster with the checksum shared by the most clusters, ties being broken by
+// the checksum itself so that the result is stable
+func mostCommonBrokerChecksum(checksums []clusterBrokerChecksum, counts map[string]int) clusterBrokerChecksum {
+	sorted := make([]clusterBrokerChecksum, len(checksums))
+	copy(sorted, checksums)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if counts[sorted[i].checksum] != counts[sorted[j].checksum] {
+			return counts[sorted[i].checksum] > counts[sorted[j].checksum]
+		}
+
+		return sorted[i].checksum < sorted[j].checksum
+	})
+
+	return sorted[0]
+}
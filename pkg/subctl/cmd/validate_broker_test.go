@@ -0,0 +1,129 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/broker"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const testBrokerNamespace = "submariner-k8s-broker"
+
+// recordCheck runs the check in a recorded phase of a new status, and returns the phase's record
+func recordCheck(t *testing.T, check func()) cli.PhaseRecord {
+	previous := status
+	defer func() { status = previous }()
+
+	status = cli.NewStatusForWriter(&bytes.Buffer{})
+	status.Record()
+	status.Start("Running the check")
+	check()
+	status.End(status.ResultFromMessages())
+
+	recorded := status.Recorded()
+	if len(recorded) != 1 {
+		t.Fatalf("Expected one recorded phase, got %d", len(recorded))
+	}
+
+	return recorded[0]
+}
+
+func TestCheckBrokerGlobalnetConfigMapLocatesTruncatedRange(t *testing.T) {
+	configMap, err := broker.NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	configMap.Data[broker.GlobalnetCidrRange] = `"169.254.0.0/1`
+	clientSet := fake.NewSimpleClientset(configMap)
+
+	record := recordCheck(t, func() {
+		checkBrokerGlobalnetConfigMap(clientSet, testBrokerNamespace)
+	})
+
+	if len(record.Failures) != 1 {
+		t.Fatalf("Expected a single failure, got %q", record.Failures)
+	}
+
+	if record.FailureCodes[0] != codeGlobalnetConfigMapMalformed {
+		t.Fatalf("Expected the failure code %s, got %q", codeGlobalnetConfigMapMalformed, record.FailureCodes[0])
+	}
+
+	if !strings.Contains(record.Failures[0], "line 1, column") {
+		t.Fatalf("Expected the failure to locate the error, got %q", record.Failures[0])
+	}
+
+	for _, action := range clientSet.Actions() {
+		if action.GetVerb() != "get" {
+			t.Fatalf("Expected the check to only read the broker, got a %q", action.GetVerb())
+		}
+	}
+}
+
+func TestCheckBrokerGlobalnetConfigMapWarnsOnLegacyEncoding(t *testing.T) {
+	configMap, err := broker.NewGlobalnetConfigMap(true, "169.254.0.0/16", 8192, testBrokerNamespace, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	configMap.Data[broker.GlobalnetCidrRange] = "169.254.0.0/16"
+	clientSet := fake.NewSimpleClientset(configMap)
+
+	record := recordCheck(t, func() {
+		checkBrokerGlobalnetConfigMap(clientSet, testBrokerNamespace)
+	})
+
+	if len(record.Failures) != 0 || len(record.WarningCodes) != 1 || record.WarningCodes[0] != codeGlobalnetConfigMapLegacy {
+		t.Fatalf("Expected a single legacy encoding warning, got failures %q and warnings %q", record.Failures, record.Warnings)
+	}
+}
+
+func TestUsesBroker(t *testing.T) {
+	submariner := &v1alpha1.Submariner{Spec: v1alpha1.SubmarinerSpec{
+		BrokerK8sApiServer:       "broker.example.com:6443",
+		BrokerK8sRemoteNamespace: testBrokerNamespace,
+	}}
+
+	tests := []struct {
+		apiServer string
+		namespace string
+		expected  bool
+	}{
+		{apiServerAddress("https://broker.example.com:6443/"), testBrokerNamespace, true},
+		{apiServerAddress("https://other.example.com:6443"), testBrokerNamespace, false},
+		{apiServerAddress("https://broker.example.com:6443"), "other-broker", false},
+	}
+
+	for _, test := range tests {
+		if actual := usesBroker(submariner, test.apiServer, test.namespace); actual != test.expected {
+			t.Errorf("usesBroker(%q, %q) returned %t, expected %t", test.apiServer, test.namespace, actual, test.expected)
+		}
+	}
+
+	if usesBroker(&v1alpha1.Submariner{}, apiServerAddress("https://broker.example.com:6443"), testBrokerNamespace) {
+		t.Errorf("usesBroker returned true for a Submariner resource which doesn't record its broker")
+	}
+}
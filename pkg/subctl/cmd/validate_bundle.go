@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// The files archived in the diagnose bundle
+const (
+	bundleResultsFile = "results.json"
+	bundleOutputFile  = "output.txt"
+)
+
+// bundleOutput collects the output of the diagnose run, including the logs and events shown, for the bundle
+var bundleOutput bytes.Buffer
+
+// writeDiagnoseBundle archives the structured results of the checks and the output of the run into a gzipped tarball
+// at the given path; both are redacted like the rest of the output
+func writeDiagnoseBundle(path string, report *jsonReport, output []byte) error {
+	results, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	modTime := time.Now()
+
+	for _, entry := range []struct {
+		name    string
+		content []byte
+	}{
+		{name: bundleResultsFile, content: results},
+		{name: bundleOutputFile, content: output},
+	} {
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name:    entry.name,
+			Mode:    0644,
+			Size:    int64(len(entry.content)),
+			ModTime: modTime,
+		})
+		if err == nil {
+			_, err = tarWriter.Write(entry.content)
+		}
+
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
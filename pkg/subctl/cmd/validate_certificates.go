@@ -0,0 +1,156 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var certificateExpiryWindow time.Duration
+
+var validateCertificatesCmd = &cobra.Command{
+	Use:   "certificates",
+	Short: "Check the expiry of the certificates used by Submariner",
+	Long: "This command checks that the TLS certificates in the Submariner namespace and the broker CA certificate" +
+		" are neither expired nor about to expire.",
+	Run: validateCertificates,
+}
+
+func init() {
+	addCertificateExpiryFlag(validateCertificatesCmd)
+	validateCmd.AddCommand(validateCertificatesCmd)
+}
+
+func addCertificateExpiryFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&certificateExpiryWindow, "expiry-window", 30*24*time.Hour,
+		"warn about certificates expiring within this duration")
+}
+
+func validateCertificates(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateCertificatesInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateCertificatesInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the expiry of the certificates used by Submariner in cluster %q", clusterName))
+
+	clientSet := clients.kubeClient
+
+	secrets, err := clientSet.CoreV1().Secrets(OperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the secrets: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	now := time.Now()
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if tlsCert, found := secret.Data[v1.TLSCertKey]; found {
+			checkCertificatesExpiry(fmt.Sprintf("secret %q", secret.Name), tlsCert, now)
+		}
+	}
+
+	if submariner.Spec.BrokerK8sCA != "" {
+		brokerCA, err := base64.StdEncoding.DecodeString(submariner.Spec.BrokerK8sCA)
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeCertificateUnreadable, fmt.Sprintf("Unable to decode the broker CA certificate: %s", err))
+		} else {
+			checkCertificatesExpiry("the broker CA", brokerCA, now)
+		}
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage(fmt.Sprintf("No certificates expire within %s", certificateExpiryWindow))
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+func checkCertificatesExpiry(source string, pemData []byte, now time.Time) {
+	for remaining := pemData; len(remaining) > 0; {
+		var block *pem.Block
+
+		block, remaining = pem.Decode(remaining)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeCertificateUnreadable, fmt.Sprintf("Unable to parse a certificate in %s: %s", source, err))
+			continue
+		}
+
+		expiry := cert.NotAfter.UTC().Format(time.RFC3339)
+
+		if now.After(cert.NotAfter) {
+			status.QueueFailureMessageWithCode(codeCertificateExpired, fmt.Sprintf("The certificate for %q in %s expired on %s",
+				cert.Subject.String(), source, expiry))
+		} else if now.Add(certificateExpiryWindow).After(cert.NotAfter) {
+			status.QueueWarningMessageWithCode(codeCertificateExpiring, fmt.Sprintf("The certificate for %q in %s expires on %s",
+				cert.Subject.String(), source, expiry))
+		}
+	}
+}
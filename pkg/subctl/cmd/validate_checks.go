@@ -0,0 +1,388 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+)
+
+// The permissions the checks need in the clusters
+const (
+	readPermission       = "read"
+	createPodsPermission = "create pods"
+	execPermission       = "exec in pods"
+)
+
+// clusterCheckTarget holds what the per-cluster checks run against; submariner and clients are only set for the
+// checks which require Submariner
+type clusterCheckTarget struct {
+	config      *rest.Config
+	clusterName string
+	submariner  *v1alpha1.Submariner
+	clients     *clusterClients
+	broker      *brokerMember
+}
+
+// diagnoseCheck describes a check run by "diagnose all", either in each cluster or across the clusters
+type diagnoseCheck struct {
+	Name        string   `json:"name"`
+	Command     string   `json:"command"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	// Disruptive checks create pods in the clusters or execute commands in the Submariner pods
+	Disruptive bool `json:"disruptive"`
+	// Checks which don't require Submariner run before its resource is retrieved
+	RequiresSubmariner bool `json:"requiresSubmariner"`
+	AcrossClusters     bool `json:"acrossClusters"`
+
+	run       func(target *clusterCheckTarget) bool
+	runAcross func(configs []restConfig) bool
+}
+
+var readOnly = []string{readPermission}
+
+// diagnoseChecks lists the checks run by "diagnose all", in order
+var diagnoseChecks = []diagnoseCheck{
+	{
+		Name: "k8s-version", Command: "k8s-version", Permissions: readOnly,
+		Description: "Check that the Kubernetes version is supported",
+		run: func(t *clusterCheckTarget) bool {
+			return validateK8sVersionInCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "finalizers", Command: "finalizers", Permissions: readOnly,
+		Description: "Check for Submariner resources stuck on their finalizers",
+		run: func(t *clusterCheckTarget) bool {
+			return validateFinalizersInCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "webhooks", Command: "webhooks", Permissions: readOnly,
+		Description: "Check that the Submariner admission webhooks are served",
+		run: func(t *clusterCheckTarget) bool {
+			return validateWebhooksInCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "reconcile", Command: "reconcile", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the operator reconciles the Submariner resource",
+		run: func(t *clusterCheckTarget) bool {
+			return validateReconcileInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "cni", Command: "cni", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the CNI network plugin is supported",
+		run: func(t *clusterCheckTarget) bool {
+			return validateCNIInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "connections", Command: "connections", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check the gateway connections to the other clusters",
+		run: func(t *clusterCheckTarget) bool {
+			return validateConnectionsInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "gateway-ports", Command: "gateway-ports", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the ports exposed for the gateways match the cable driver",
+		run: func(t *clusterCheckTarget) bool {
+			return validateGatewayPortsInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "gateway-loadbalancer", Command: "gateway-loadbalancer", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check the load balancer exposing the gateways",
+		run: func(t *clusterCheckTarget) bool {
+			return validateGatewayLoadBalancerInCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "health-check", Command: "health-check", Permissions: []string{readPermission, execPermission},
+		Disruptive: true, RequiresSubmariner: true,
+		Description: "Check the health checks of the gateway connections",
+		run: func(t *clusterCheckTarget) bool {
+			return validateHealthCheckInCluster(t.config, t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "pods", Command: "deployment", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the Submariner pods are up and running",
+		run: func(t *clusterCheckTarget) bool {
+			return checkPods(t.clusterName, t.clients, t.submariner, OperatorNamespace)
+		},
+	},
+	{
+		Name: "overlapping-cidrs", Command: "deployment", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the CIDRs of the clusters don't overlap",
+		run: func(t *clusterCheckTarget) bool {
+			return checkOverlappingCIDRs(t.clients, t.submariner, t.broker)
+		},
+	},
+	{
+		Name: "active-gateways", Command: "deployment", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that a single gateway is active",
+		run: func(t *clusterCheckTarget) bool {
+			return checkActiveGateways(t.clusterName, t.clients, t.submariner)
+		},
+	},
+	{
+		Name: "operator-leader", Command: "deployment", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the operator leader is running",
+		run: func(t *clusterCheckTarget) bool {
+			return checkOperatorLeader(t.clusterName, t.clients, OperatorNamespace)
+		},
+	},
+	{
+		Name: "operator-csv", Command: "deployment", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the operator's OLM ClusterServiceVersion has succeeded",
+		run: func(t *clusterCheckTarget) bool {
+			return checkOperatorCSV(t.clusterName, t.clients, OperatorNamespace)
+		},
+	},
+	{
+		Name: "component-versions", Command: "deployment", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the gateway and the route agent run the same version",
+		run: func(t *clusterCheckTarget) bool {
+			return checkComponentVersions(t.clusterName, t.clients, OperatorNamespace)
+		},
+	},
+	{
+		Name: "globalnet-consistency", Command: "globalnet-consistency", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the global IPs are consistently assigned",
+		run: func(t *clusterCheckTarget) bool {
+			return validateGlobalnetConsistencyInCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "globalnet-utilization", Command: "globalnet-utilization", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the global IPs aren't close to exhaustion",
+		run: func(t *clusterCheckTarget) bool {
+			return validateGlobalnetUtilizationInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "globalnet-egress-ips", Command: "globalnet-egress-ips", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check the GlobalEgressIP allocations and the pods they select",
+		run: func(t *clusterCheckTarget) bool {
+			return validateGlobalnetEgressIPsInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "cidr-drift", Command: "cidr-drift", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the declared CIDRs match those detected in the cluster",
+		run: func(t *clusterCheckTarget) bool {
+			return validateCIDRDriftInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "endpoint-subnets", Command: "cidr-drift", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the Endpoints advertise the declared CIDRs",
+		run: func(t *clusterCheckTarget) bool {
+			return validateEndpointSubnetsInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "pod-security-labels", Command: "pod-privileges", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the Pod Security admission allows the privileged Submariner pods",
+		run: func(t *clusterCheckTarget) bool {
+			return validatePodSecurityLabelsInCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "pod-privileges", Command: "pod-privileges", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the Submariner pods have the privileges they need",
+		run: func(t *clusterCheckTarget) bool {
+			return validatePodPrivilegesInCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "certificates", Command: "certificates", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check the certificates used by Submariner",
+		run: func(t *clusterCheckTarget) bool {
+			return validateCertificatesInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "kube-proxy-mode", Command: "kube-proxy-mode", Permissions: []string{readPermission, createPodsPermission},
+		Disruptive: true, RequiresSubmariner: true,
+		Description: "Check that kube-proxy runs in a supported mode",
+		run: func(t *clusterCheckTarget) bool {
+			return validateKubeProxyModeInCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "kube-proxy-presence", Command: "kube-proxy-presence", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that kube-proxy runs, or that the CNI replaces it",
+		run: func(t *clusterCheckTarget) bool {
+			return validateKubeProxyPresenceInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "firewall-metrics", Command: "firewall metrics", Permissions: []string{readPermission, createPodsPermission},
+		Disruptive: true, RequiresSubmariner: true,
+		Description: "Check that the firewall allows access to the metrics ports",
+		run: func(t *clusterCheckTarget) bool {
+			return validateFirewallMetricsConfigWithinCluster(t.clients, t.clusterName)
+		},
+	},
+	{
+		Name: "firewall-vxlan", Command: "firewall vxlan", Permissions: []string{readPermission, createPodsPermission},
+		Disruptive: true, RequiresSubmariner: true,
+		Description: "Check that the firewall allows the VXLAN traffic",
+		run: func(t *clusterCheckTarget) bool {
+			return validateVxLANConfigWithinCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "endpoint-ips", Command: "endpoint-ips", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the Endpoints advertise IPs their peers can reach",
+		run: func(t *clusterCheckTarget) bool {
+			return validateEndpointIPsInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "nat-traversal", Command: "nat-traversal", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the NAT traversal setting of the Endpoints matches their network",
+		run: func(t *clusterCheckTarget) bool {
+			return validateNATTraversalInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "gateway-egress", Command: "gateway-egress", Permissions: []string{readPermission, execPermission},
+		Disruptive: true, RequiresSubmariner: true,
+		Description: "Check the outbound connectivity of the gateways",
+		run: func(t *clusterCheckTarget) bool {
+			return validateGatewayEgressInCluster(t.config, t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "kernel-modules", Command: "kernel-modules", Permissions: []string{readPermission, createPodsPermission},
+		Disruptive: true, RequiresSubmariner: true,
+		Description: "Check that the gateway nodes have the kernel modules needed by the cable driver",
+		run: func(t *clusterCheckTarget) bool {
+			return validateKernelModulesInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "mtu", Command: "mtu", Permissions: []string{readPermission, execPermission},
+		Disruptive: true, RequiresSubmariner: true,
+		Description: "Check the MTU of the inter-cluster path",
+		run: func(t *clusterCheckTarget) bool {
+			return validateMTUInCluster(t.config, t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "ipsec-ciphers", Command: "ipsec-ciphers", Permissions: []string{readPermission, execPermission},
+		Disruptive: true, RequiresSubmariner: true,
+		Description: "Check that the IPsec tunnels use the configured ciphers",
+		run: func(t *clusterCheckTarget) bool {
+			return validateIPsecCiphersInCluster(t.config, t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "wireguard", Command: "wireguard", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check the WireGuard keys of the gateways",
+		run: func(t *clusterCheckTarget) bool {
+			return validateWireGuardKeysInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "service-discovery", Command: "service-discovery", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check the global IPs of the exported services",
+		run: func(t *clusterCheckTarget) bool {
+			return validateServiceDiscoveryGlobalIPsInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "export-scope", Command: "export-scope", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Report which namespaces export services to the other clusters",
+		run: func(t *clusterCheckTarget) bool {
+			return validateExportScopeInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "lighthouse-dns", Command: "lighthouse-dns", Permissions: readOnly, RequiresSubmariner: true,
+		Description: "Check that the cluster DNS forwards the clusterset domains to Lighthouse",
+		run: func(t *clusterCheckTarget) bool {
+			return validateLighthouseDNSInCluster(t.clients, t.clusterName, t.submariner)
+		},
+	},
+	{
+		Name: "service-discovery-consistency", Command: "service-discovery-consistency", Permissions: readOnly,
+		RequiresSubmariner: true, AcrossClusters: true,
+		Description: "Check that service discovery is enabled consistently across the clusters",
+		runAcross:   validateServiceDiscoveryConsistencyAcrossClusters,
+	},
+	{
+		Name: "clusterset-domains", Command: "clusterset-domains", Permissions: readOnly, RequiresSubmariner: true,
+		AcrossClusters: true,
+		Description:    "Check that the clusters agree on the clusterset domains",
+		runAcross:      validateClusterSetDomainsAcrossClusters,
+	},
+	{
+		Name: "broker-checksum", Command: "broker-checksum", Permissions: readOnly, RequiresSubmariner: true,
+		AcrossClusters: true,
+		Description:    "Check that the clusters use the same broker settings",
+		runAcross:      validateBrokerChecksumAcrossClusters,
+	},
+	{
+		Name: "gateway-ha-mode", Command: "gateway-ha-mode", Permissions: readOnly, RequiresSubmariner: true,
+		AcrossClusters: true,
+		Description:    "Check that the clusters use the same gateway HA mode",
+		runAcross:      validateGatewayHAModeAcrossClusters,
+	},
+	{
+		Name: "node-cidr-overlaps", Command: "deployment", Permissions: []string{readPermission, execPermission},
+		Disruptive: true, RequiresSubmariner: true, AcrossClusters: true,
+		Description: "Check that the node networks don't overlap the node networks or CIDRs of the other clusters",
+		runAcross:   validateNodeCIDROverlapsAcrossClusters,
+	},
+}
+
+// listDiagnoseChecks prints the checks run by "diagnose all" and their metadata, as JSON or as a table
+func listDiagnoseChecks(format string) {
+	if format == jsonOutputFormat {
+		output, err := json.MarshalIndent(diagnoseChecks, "", "  ")
+		exitOnError("Error encoding the checks", err)
+		fmt.Println(string(output))
+
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tCOMMAND\tPERMISSIONS\tDISRUPTIVE\tDESCRIPTION")
+
+	for i := range diagnoseChecks {
+		check := &diagnoseChecks[i]
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%t\t%s\n", check.Name, check.Command, strings.Join(check.Permissions, ","),
+			check.Disruptive, check.Description)
+	}
+
+	writer.Flush()
+}
@@ -0,0 +1,54 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+func findDiagnoseCheck(name string) *diagnoseCheck {
+	for i := range diagnoseChecks {
+		if diagnoseChecks[i].Name == name {
+			return &diagnoseChecks[i]
+		}
+	}
+
+	return nil
+}
+
+func TestChecksRunWithTheClusterClients(t *testing.T) {
+	previous := status
+	defer func() { status = previous }()
+
+	status = cli.NewStatusForWriter(&bytes.Buffer{})
+
+	check := findDiagnoseCheck("webhooks")
+	if check == nil {
+		t.Fatal("Expected a webhooks check")
+	}
+
+	// Without a REST config, the check can only succeed by using the clients of the target
+	target := &clusterCheckTarget{clusterName: "east", clients: newTestClusterClients(nil)}
+	if !check.run(target) {
+		t.Error("Expected the webhooks check to succeed with the cluster clients")
+	}
+}
@@ -0,0 +1,295 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/network"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+// expectedCIDRs lists the subnets each cluster, identified by its cluster ID, is expected to advertise
+type expectedCIDRs struct {
+	Clusters map[string][]string `json:"clusters"`
+}
+
+var expectedCIDRsFile string
+
+var validateCIDRDriftCmd = &cobra.Command{
+	Use:   "cidr-drift",
+	Short: "Check the Submariner CIDRs against the cluster network configuration",
+	Long: "This command checks that the cluster and service CIDRs used by Submariner match the CIDRs currently" +
+		" configured in the cluster, and that the local Endpoints advertise the CIDRs declared in the Submariner resource" +
+		" and, with --expected-cidrs, the CIDRs expected for the cluster.",
+	Run: validateCIDRDrift,
+}
+
+func init() {
+	addExpectedCIDRsFlag(validateCIDRDriftCmd)
+	validateCmd.AddCommand(validateCIDRDriftCmd)
+}
+
+func addExpectedCIDRsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&expectedCIDRsFile, "expected-cidrs", "",
+		"YAML file mapping the cluster IDs, under \"clusters\", to the exact list of CIDRs each cluster should advertise")
+}
+
+func validateCIDRDrift(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateCIDRDriftInCluster(clients, item.clusterName, submariner) && validationStatus
+		validationStatus = validateEndpointSubnetsInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateCIDRDriftInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the Submariner CIDRs against the network configuration of cluster %q", clusterName))
+
+	dynClient, clientSet := clients.dynamicClient, clients.kubeClient
+
+	clusterNetwork, err := network.Discover(dynClient, clientSet, nil, OperatorNamespace)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCIDRsNotDiscovered, fmt.Sprintf("Error discovering the cluster network details: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if clusterNetwork == nil {
+		status.QueueWarningMessageWithCode(codeCIDRsNotDiscovered, "Unable to discover the cluster network details")
+		status.End(cli.Warning)
+		return true
+	}
+
+	checkCIDRDrift("cluster", submariner.Status.ClusterCIDR, clusterNetwork.PodCIDRs)
+	checkCIDRDrift("service", submariner.Status.ServiceCIDR, clusterNetwork.ServiceCIDRs)
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("The Submariner CIDRs match the cluster network configuration")
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+// validateEndpointSubnetsInCluster checks that the subnets advertised by the local Endpoints are the CIDRs declared
+// in the Submariner resource: the global CIDR with Globalnet, the service and cluster CIDRs otherwise
+func validateEndpointSubnetsInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the subnets advertised by the Endpoints of cluster %q", clusterName))
+
+	endpoints, err := listEndpoints(clients.submarinerClient)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Endpoints: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	declared := declaredSubnets(submariner)
+	found := false
+
+	expected, err := getExpectedSubnets(submariner.Spec.ClusterID)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeInputInvalid, fmt.Sprintf("Error reading the expected CIDRs from %q: %s", expectedCIDRsFile, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if expectedCIDRsFile != "" && expected == nil {
+		status.QueueWarningMessageWithCode(codeExpectedCIDRsUnlisted, fmt.Sprintf("Cluster %q isn't listed in %q, so its"+
+			" subnets aren't checked against expected CIDRs", submariner.Spec.ClusterID, expectedCIDRsFile))
+	}
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if endpoint.Spec.ClusterID != submariner.Spec.ClusterID {
+			continue
+		}
+
+		found = true
+
+		if !sameCIDRs(declared, endpoint.Spec.Subnets) {
+			status.QueueFailureMessageWithCode(codeEndpointSubnetsMismatch,
+				fmt.Sprintf("Endpoint %q advertises subnets %v but the Submariner resource declares %v",
+					endpoint.Name, endpoint.Spec.Subnets, declared))
+		}
+
+		if expected != nil && !sameCIDRs(expected, endpoint.Spec.Subnets) {
+			status.QueueFailureMessageWithCode(codeEndpointSubnetsMismatch,
+				fmt.Sprintf("Endpoint %q advertises subnets %v but %v are expected",
+					endpoint.Name, endpoint.Spec.Subnets, expected))
+		}
+	}
+
+	if !found {
+		status.QueueWarningMessageWithCode(codeLocalEndpointMissing, "No local Endpoint was found")
+		status.End(cli.Warning)
+		return true
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	if expected != nil {
+		status.QueueSuccessMessage(fmt.Sprintf("The Endpoints advertise the declared and expected subnets %v", declared))
+	} else {
+		status.QueueSuccessMessage(fmt.Sprintf("The Endpoints advertise the declared subnets %v", declared))
+	}
+
+	status.End(status.ResultFromMessages())
+	return true
+}
+
+// getExpectedSubnets returns the subnets the given cluster is expected to advertise according to the expected CIDRs
+// file, or nil if there are no expectations for the cluster
+func getExpectedSubnets(clusterID string) ([]string, error) {
+	if expectedCIDRsFile == "" {
+		return nil, nil
+	}
+
+	contents, err := ioutil.ReadFile(expectedCIDRsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := &expectedCIDRs{}
+	if err := yaml.Unmarshal(contents, expected); err != nil {
+		return nil, err
+	}
+
+	for _, cidr := range expected.Clusters[clusterID] {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q for cluster %q: %s", cidr, clusterID, err)
+		}
+	}
+
+	return expected.Clusters[clusterID], nil
+}
+
+// declaredSubnets returns the subnets a cluster should advertise: its global CIDR with Globalnet, its service and
+// cluster CIDRs otherwise
+func declaredSubnets(submariner *v1alpha1.Submariner) []string {
+	if globalCIDR := getGlobalCIDR(submariner); globalCIDR != "" {
+		return []string{globalCIDR}
+	}
+
+	return []string{submariner.Status.ServiceCIDR, submariner.Status.ClusterCIDR}
+}
+
+func getGlobalCIDR(submariner *v1alpha1.Submariner) string {
+	if submariner.Status.GlobalCIDR != "" {
+		return submariner.Status.GlobalCIDR
+	}
+
+	return submariner.Spec.GlobalCIDR
+}
+
+// sameCIDRs returns true if both lists contain the same CIDRs, in any order
+func sameCIDRs(first, second []string) bool {
+	if len(first) != len(second) {
+		return false
+	}
+
+	for _, cidr := range first {
+		found := false
+
+		for _, other := range second {
+			if sameCIDR(cidr, other) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkCIDRDrift(kind, declared string, detected []string) {
+	if declared == "" {
+		status.QueueWarningMessageWithCode(codeCIDRUnrecorded, fmt.Sprintf("The Submariner resource does not record a %s CIDR",
+			kind))
+		return
+	}
+
+	if len(detected) == 0 {
+		status.QueueWarningMessageWithCode(codeCIDRsNotDiscovered, fmt.Sprintf("Unable to detect the %s CIDRs to compare"+
+			" against %q", kind, declared))
+		return
+	}
+
+	for _, detectedCIDR := range detected {
+		if sameCIDR(declared, detectedCIDR) {
+			return
+		}
+	}
+
+	status.QueueWarningMessageWithCode(codeCIDRDrift,
+		fmt.Sprintf("The %s CIDR used by Submariner (%q) differs from the %s CIDRs configured in"+
+			" the cluster (%v)", kind, declared, kind, detected))
+}
+
+func sameCIDR(first, second string) bool {
+	_, firstNet, err := net.ParseCIDR(first)
+	if err != nil {
+		return first == second
+	}
+
+	_, secondNet, err := net.ParseCIDR(second)
+	if err != nil {
+		return false
+	}
+
+	return firstNet.String() == secondNet.String()
+}
@@ -0,0 +1,406 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	"github.com/submariner-io/submariner/pkg/cidr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+// InterfaceAddressesCommand lists the addresses of the interfaces of a node, one per line, along with their prefix length
+const InterfaceAddressesCommand = "ip -o addr show"
+
+var (
+	cidrOverlapsDOTFile string
+	includeNodeCIDRs    bool
+)
+
+// clusterNodeCIDRs holds the subnets advertised by a cluster and the host networks of its nodes, i.e. the networks of
+// the interfaces carrying their internal IPs, or the internal IPs themselves when the interfaces couldn't be read,
+// along with the nodes in each network
+type clusterNodeCIDRs struct {
+	clusterID    string
+	subnets      []string
+	nodeNetworks map[string][]string
+}
+
+// cidrOverlap describes a CIDR of one cluster overlapping with the CIDRs of another cluster
+type cidrOverlap struct {
+	clusterID      string
+	cidr           string
+	otherClusterID string
+	otherCIDRs     []string
+}
+
+func addNodeCIDRsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&includeNodeCIDRs, "include-node-cidrs", false,
+		"also check, across the clusters, that the node networks of each cluster, read from the Route Agent pods, don't"+
+			" overlap the node networks or the CIDRs of the others")
+}
+
+// brokerMember describes the broker cluster when it is also one of the member clusters being diagnosed
+type brokerMember struct {
+	clusterID string
+	subnets   []string
+}
+
+// findBrokerMember returns the member cluster whose API server is the broker's, if any; the broker is identified
+// from the Submariner resources of the members, retrieved with the clients created by newClients
+func findBrokerMember(configs []restConfig, newClients clusterClientsFactory) *brokerMember {
+	brokerAPIServers := stringset.New()
+	members := map[string]*brokerMember{}
+
+	for _, item := range configs {
+		clients, err := newClients(item.config)
+		if err != nil {
+			continue
+		}
+
+		submariner, err := getSubmarinerResourceWithClient(clients.operatorClient)
+		if err != nil || submariner == nil {
+			continue
+		}
+
+		if submariner.Spec.BrokerK8sApiServer != "" {
+			brokerAPIServers.Add(apiServerAddress(submariner.Spec.BrokerK8sApiServer))
+		}
+
+		subnets := []string{}
+		for _, subnet := range declaredSubnets(submariner) {
+			if subnet != "" {
+				subnets = append(subnets, subnet)
+			}
+		}
+
+		members[apiServerAddress(item.config.Host)] = &brokerMember{
+			clusterID: submariner.Spec.ClusterID,
+			subnets:   subnets,
+		}
+	}
+
+	for apiServer, member := range members {
+		if brokerAPIServers.Contains(apiServer) {
+			return member
+		}
+	}
+
+	return nil
+}
+
+// apiServerAddress returns the address of an API server without the schema and the trailing slash, so that the broker
+// API server recorded in a Submariner resource can be compared with the host of a REST config
+func apiServerAddress(url string) string {
+	return removeSchemaPrefix(strings.TrimSuffix(url, "/"))
+}
+
+// withBrokerMemberEndpoint adds an Endpoint with the broker's declared subnets when the broker cluster is a member
+// without an Endpoint, e.g. because its gateway is down, so that its CIDRs are still included in the overlap analysis
+func withBrokerMemberEndpoint(endpoints []subv1.Endpoint, broker *brokerMember) []subv1.Endpoint {
+	if broker == nil || len(broker.subnets) == 0 {
+		return endpoints
+	}
+
+	for i := range endpoints {
+		if endpoints[i].Spec.ClusterID == broker.clusterID {
+			return endpoints
+		}
+	}
+
+	return append(endpoints, subv1.Endpoint{
+		ObjectMeta: metav1.ObjectMeta{Name: broker.clusterID + "-broker"},
+		Spec:       subv1.EndpointSpec{ClusterID: broker.clusterID, Subnets: broker.subnets},
+	})
+}
+
+// describeCluster names the cluster, pointing out the broker cluster
+func describeCluster(clusterID string, broker *brokerMember) string {
+	if broker != nil && broker.clusterID == clusterID {
+		return fmt.Sprintf("%q (the broker cluster)", clusterID)
+	}
+
+	return fmt.Sprintf("%q", clusterID)
+}
+
+// clusterDOTFile returns the path of the CIDR overlaps graph of the given cluster, the given path suffixed with the
+// cluster name before its extension, so that the graphs of the diagnosed clusters don't overwrite each other
+func clusterDOTFile(path, clusterName string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + clusterName + ext
+}
+
+// writeCIDROverlapsDOT writes a Graphviz graph with the clusters as nodes and their overlapping CIDRs as edges
+func writeCIDROverlapsDOT(path string, endpoints []subv1.Endpoint, overlaps []cidrOverlap) error {
+	clusterIDs := stringset.New()
+	for i := range endpoints {
+		clusterIDs.Add(endpoints[i].Spec.ClusterID)
+	}
+
+	nodes := clusterIDs.Elements()
+	sort.Strings(nodes)
+
+	var graph strings.Builder
+
+	graph.WriteString("graph cidr_overlaps {\n")
+
+	for _, clusterID := range nodes {
+		fmt.Fprintf(&graph, "  %q;\n", clusterID)
+	}
+
+	for _, overlap := range overlaps {
+		fmt.Fprintf(&graph, "  %q -- %q [label=%q];\n", overlap.clusterID, overlap.otherClusterID,
+			fmt.Sprintf("%s overlaps %s", overlap.cidr, strings.Join(overlap.otherCIDRs, ",")))
+	}
+
+	graph.WriteString("}\n")
+
+	return ioutil.WriteFile(path, []byte(graph.String()), 0644)
+}
+
+// validateNodeCIDROverlapsAcrossClusters checks that the node networks of each cluster overlap neither the node
+// networks nor the advertised subnets of the other clusters, when requested
+func validateNodeCIDROverlapsAcrossClusters(configs []restConfig) bool {
+	status.Start("Checking that the node CIDRs don't overlap across the clusters")
+
+	if !includeNodeCIDRs {
+		status.QueueSuccessMessage("The node CIDRs are only checked with --include-node-cidrs")
+		status.End(cli.Success)
+		return true
+	}
+
+	clusters := []clusterNodeCIDRs{}
+
+	for _, item := range configs {
+		submariner, err := retrieveSubmariner(item.config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the Submariner resource from"+
+				" cluster %q: %s", item.clusterName, err))
+			continue
+		}
+
+		if submariner == nil {
+			if requireInstalled {
+				status.QueueFailureMessageWithCode(codeSubmarinerNotInstalled, fmt.Sprintf("Submariner is not installed in"+
+					" cluster %q", item.clusterName))
+			}
+
+			continue
+		}
+
+		clientSet, err := kubernetes.NewForConfig(item.config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+			continue
+		}
+
+		nodes, err := clientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the nodes of cluster %q: %s", item.clusterName, err))
+			continue
+		}
+
+		cluster := clusterNodeCIDRs{
+			clusterID:    submariner.Spec.ClusterID,
+			nodeNetworks: getNodeNetworks(item.config, clientSet, nodes.Items),
+		}
+
+		for _, subnet := range declaredSubnets(submariner) {
+			if subnet != "" {
+				cluster.subnets = append(cluster.subnets, subnet)
+			}
+		}
+
+		clusters = append(clusters, cluster)
+	}
+
+	if diagnoseReadOnly {
+		status.QueueSuccessMessage("In read-only mode, the networks of the node interfaces aren't read, so only the" +
+			" node IPs are compared")
+	}
+
+	for i := range clusters {
+		for j := range clusters {
+			if i == j {
+				continue
+			}
+
+			if i < j {
+				checkNodeNetworksOverlap(&clusters[i], &clusters[j])
+			}
+
+			checkNodeNetworksInSubnets(&clusters[i], &clusters[j])
+		}
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage(fmt.Sprintf("The node networks of the %d clusters don't overlap the node networks or"+
+			" CIDRs of the other clusters", len(clusters)))
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+// getNodeNetworks returns the nodes in each network carrying their internal IPs. The networks are read from the
+// interfaces of each node, in its Route Agent pod; the internal IPs whose interface couldn't be read, e.g. in read-only
+// mode, are returned as host networks.
+func getNodeNetworks(config *rest.Config, clientSet kubernetes.Interface, nodes []v1.Node) map[string][]string {
+	interfaceNetworks := map[string]map[string]*net.IPNet{}
+
+	if !diagnoseReadOnly {
+		interfaceNetworks = readNodeInterfaceNetworks(config, clientSet)
+	}
+
+	networks := map[string][]string{}
+
+	for i := range nodes {
+		for _, address := range nodes[i].Status.Addresses {
+			ip := net.ParseIP(address.Address)
+			if address.Type != v1.NodeInternalIP || ip == nil {
+				continue
+			}
+
+			network, found := interfaceNetworks[nodes[i].Name][ip.String()]
+			if !found {
+				network = hostNetwork(ip)
+			}
+
+			networks[network.String()] = append(networks[network.String()], nodes[i].Name)
+		}
+	}
+
+	return networks
+}
+
+// readNodeInterfaceNetworks returns the networks of the interface addresses of each node, by address
+func readNodeInterfaceNetworks(config *rest.Config, clientSet kubernetes.Interface) map[string]map[string]*net.IPNet {
+	networks := map[string]map[string]*net.IPNet{}
+
+	pods, err := clientSet.CoreV1().Pods(OperatorNamespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: "app=submariner-routeagent"})
+	if err != nil {
+		status.QueueWarningMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Route Agent pods, so only the"+
+			" node IPs are compared: %s", err))
+		return networks
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		output, err := execInPod(config, clientSet, pod, InterfaceAddressesCommand)
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeNodeProbeFailed, fmt.Sprintf("Unable to read the interface addresses of node"+
+				" %q, so only its IPs are compared: %s", pod.Spec.NodeName, err))
+			continue
+		}
+
+		networks[pod.Spec.NodeName] = parseInterfaceNetworks(output)
+	}
+
+	return networks
+}
+
+// parseInterfaceNetworks returns the network of each address in the given "ip -o addr show" output
+func parseInterfaceNetworks(output string) map[string]*net.IPNet {
+	networks := map[string]*net.IPNet{}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] != "inet" && fields[i] != "inet6" {
+				continue
+			}
+
+			if ip, network, err := net.ParseCIDR(fields[i+1]); err == nil {
+				networks[ip.String()] = network
+			}
+
+			break
+		}
+	}
+
+	return networks
+}
+
+// hostNetwork returns the network holding only the given IP
+func hostNetwork(ip net.IP) *net.IPNet {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// checkNodeNetworksOverlap reports the node networks of the cluster overlapping the node networks of the other cluster
+func checkNodeNetworksOverlap(cluster, other *clusterNodeCIDRs) {
+	for _, network := range sortedNetworks(cluster.nodeNetworks) {
+		for _, otherNetwork := range sortedNetworks(other.nodeNetworks) {
+			if overlap, err := cidr.IsOverlapping([]string{network}, otherNetwork); err == nil && overlap {
+				status.QueueFailureMessageWithCode(codeNodeIPOverlap,
+					fmt.Sprintf("The network %s of nodes %v in cluster %q overlaps the network %s of nodes %v in cluster %q",
+						network, cluster.nodeNetworks[network], cluster.clusterID, otherNetwork, other.nodeNetworks[otherNetwork],
+						other.clusterID))
+			}
+		}
+	}
+}
+
+// checkNodeNetworksInSubnets reports the node networks of the cluster overlapping the subnets advertised by the other
+// cluster
+func checkNodeNetworksInSubnets(cluster, other *clusterNodeCIDRs) {
+	for _, subnet := range other.subnets {
+		for _, network := range sortedNetworks(cluster.nodeNetworks) {
+			if overlap, err := cidr.IsOverlapping([]string{subnet}, network); err == nil && overlap {
+				status.QueueFailureMessageWithCode(codeNodeIPOverlap,
+					fmt.Sprintf("The network %s of nodes %v in cluster %q overlaps the CIDR %q of cluster %q",
+						network, cluster.nodeNetworks[network], cluster.clusterID, subnet, other.clusterID))
+			}
+		}
+	}
+}
+
+func sortedNetworks(nodeNetworks map[string][]string) []string {
+	networks := make([]string, 0, len(nodeNetworks))
+	for network := range nodeNetworks {
+		networks = append(networks, network)
+	}
+
+	sort.Strings(networks)
+
+	return networks
+}
@@ -0,0 +1,168 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	operatorfake "github.com/submariner-io/submariner-operator/pkg/client/clientset/versioned/fake"
+)
+
+const testInterfaceAddresses = `1: lo    inet 127.0.0.1/8 scope host lo\       valid_lft forever preferred_lft forever
+2: eth0    inet 172.18.0.5/16 brd 172.18.255.255 scope global eth0\       valid_lft forever preferred_lft forever
+2: eth0    inet6 fc00:f853:ccd:e793::5/64 scope global nodad \       valid_lft forever preferred_lft forever
+`
+
+func TestParseInterfaceNetworks(t *testing.T) {
+	networks := parseInterfaceNetworks(testInterfaceAddresses)
+
+	expected := map[string]string{
+		"127.0.0.1":             "127.0.0.0/8",
+		"172.18.0.5":            "172.18.0.0/16",
+		"fc00:f853:ccd:e793::5": "fc00:f853:ccd:e793::/64",
+	}
+
+	if len(networks) != len(expected) {
+		t.Fatalf("Expected %d networks, got %v", len(expected), networks)
+	}
+
+	for ip, network := range expected {
+		if networks[ip] == nil || networks[ip].String() != network {
+			t.Errorf("Expected the network of %s to be %s, got %v", ip, network, networks[ip])
+		}
+	}
+}
+
+func TestHostNetwork(t *testing.T) {
+	if network := hostNetwork(net.ParseIP("10.0.0.1")).String(); network != "10.0.0.1/32" {
+		t.Errorf("Expected 10.0.0.1/32, got %s", network)
+	}
+
+	if network := hostNetwork(net.ParseIP("fd00::1")).String(); network != "fd00::1/128" {
+		t.Errorf("Expected fd00::1/128, got %s", network)
+	}
+}
+
+func TestCheckNodeNetworksOverlap(t *testing.T) {
+	cluster := &clusterNodeCIDRs{clusterID: "east", nodeNetworks: map[string][]string{"10.0.0.0/24": {"east-node"}}}
+	overlapping := &clusterNodeCIDRs{clusterID: "west", nodeNetworks: map[string][]string{"10.0.0.0/16": {"west-node"}}}
+	separate := &clusterNodeCIDRs{clusterID: "north", nodeNetworks: map[string][]string{"10.1.0.0/24": {"north-node"}}}
+
+	record := recordCheck(t, func() {
+		checkNodeNetworksOverlap(cluster, overlapping)
+	})
+
+	if len(record.Failures) != 1 || record.FailureCodes[0] != codeNodeIPOverlap {
+		t.Errorf("Expected the overlapping node networks to be reported, got %q", record.Failures)
+	}
+
+	record = recordCheck(t, func() {
+		checkNodeNetworksOverlap(cluster, separate)
+	})
+
+	if len(record.Failures) != 0 {
+		t.Errorf("Expected no overlap to be reported, got %q", record.Failures)
+	}
+}
+
+func TestCheckNodeNetworksInSubnets(t *testing.T) {
+	cluster := &clusterNodeCIDRs{clusterID: "east", nodeNetworks: map[string][]string{"10.0.0.0/24": {"east-node"}}}
+	other := &clusterNodeCIDRs{clusterID: "west", subnets: []string{"10.0.0.128/25", "10.96.0.0/12"}}
+
+	record := recordCheck(t, func() {
+		checkNodeNetworksInSubnets(cluster, other)
+	})
+
+	if len(record.Failures) != 1 {
+		t.Errorf("Expected the node network overlapping the pod CIDR to be reported, got %q", record.Failures)
+	}
+}
+
+func TestClusterDOTFile(t *testing.T) {
+	tests := map[string]string{
+		"overlaps.dot":         "overlaps-east.dot",
+		"/tmp/graphs/overlaps": "/tmp/graphs/overlaps-east",
+		"graphs.d/overlaps.gv": "graphs.d/overlaps-east.gv",
+	}
+
+	for path, expected := range tests {
+		if actual := clusterDOTFile(path, "east"); actual != expected {
+			t.Errorf("clusterDOTFile(%q) = %q, expected %q", path, actual, expected)
+		}
+	}
+}
+
+// fakeClusterClients returns a factory returning the given clients of the clusters, keyed by API server host
+func fakeClusterClients(clients map[string]*clusterClients) clusterClientsFactory {
+	return func(config *rest.Config) (*clusterClients, error) {
+		clusterClients, ok := clients[config.Host]
+		if !ok {
+			return nil, fmt.Errorf("no clients for %q", config.Host)
+		}
+
+		return clusterClients, nil
+	}
+}
+
+func newTestSubmariner(clusterID, brokerAPIServer, serviceCIDR, clusterCIDR string) runtime.Object {
+	return &v1alpha1.Submariner{
+		ObjectMeta: metav1.ObjectMeta{Name: submarinerResourceName, Namespace: submarinerResourceNamespace},
+		Spec:       v1alpha1.SubmarinerSpec{ClusterID: clusterID, BrokerK8sApiServer: brokerAPIServer},
+		Status:     v1alpha1.SubmarinerStatus{ServiceCIDR: serviceCIDR, ClusterCIDR: clusterCIDR},
+	}
+}
+
+func TestFindBrokerMember(t *testing.T) {
+	configs := []restConfig{
+		{config: &rest.Config{Host: "https://east.example.com:6443"}, clusterName: "east"},
+		{config: &rest.Config{Host: "https://west.example.com:6443/"}, clusterName: "west"},
+		{config: &rest.Config{Host: "https://unreachable.example.com:6443"}, clusterName: "unreachable"},
+	}
+
+	newClients := fakeClusterClients(map[string]*clusterClients{
+		"https://east.example.com:6443": {operatorClient: operatorfake.NewSimpleClientset(
+			newTestSubmariner("east", "west.example.com:6443", "10.96.0.0/16", "10.244.0.0/16"))},
+		"https://west.example.com:6443/": {operatorClient: operatorfake.NewSimpleClientset(
+			newTestSubmariner("west", "west.example.com:6443", "100.96.0.0/16", "100.244.0.0/16"))},
+	})
+
+	broker := findBrokerMember(configs, newClients)
+	if broker == nil {
+		t.Fatalf("The broker member cluster wasn't found")
+	}
+
+	if broker.clusterID != "west" {
+		t.Errorf("The broker member cluster is %q, expected \"west\"", broker.clusterID)
+	}
+
+	if len(broker.subnets) != 2 || broker.subnets[0] != "100.96.0.0/16" || broker.subnets[1] != "100.244.0.0/16" {
+		t.Errorf("The broker member cluster has subnets %v, expected [100.96.0.0/16 100.244.0.0/16]", broker.subnets)
+	}
+
+	if broker := findBrokerMember(configs[:1], newClients); broker != nil {
+		t.Errorf("Found broker member cluster %q when the broker isn't a member", broker.clusterID)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	subOperatorClientset "github.com/submariner-io/submariner-operator/pkg/client/clientset/versioned"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+)
+
+var validateClusterSetDomainsCmd = &cobra.Command{
+	Use:   "clusterset-domains",
+	Short: "Check that the clusters agree on the clusterset domains",
+	Long: "This command collects the clusterset domains served by service discovery in each cluster, i.e. " +
+		clusterSetDomain + " and any custom domains, and fails if they differ between the clusters, reporting the" +
+		" domains of each cluster. Clusters without service discovery are ignored.",
+	Run: validateClusterSetDomains,
+}
+
+func init() {
+	validateCmd.AddCommand(validateClusterSetDomainsCmd)
+}
+
+func validateClusterSetDomains(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	finishValidation(validateClusterSetDomainsAcrossClusters(configs))
+}
+
+// validateClusterSetDomainsAcrossClusters compares the domains served by service discovery in the clusters
+func validateClusterSetDomainsAcrossClusters(configs []restConfig) bool {
+	status.Start("Checking that the clusters agree on the clusterset domains")
+
+	// The clusters serving each set of domains, indexed by the comma-separated domains
+	clustersByDomains := map[string][]string{}
+
+	for _, item := range configs {
+		submariner, err := retrieveSubmariner(item.config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the Submariner resource from"+
+				" cluster %q: %s", item.clusterName, err))
+			continue
+		}
+
+		if submariner == nil {
+			if requireInstalled {
+				status.QueueFailureMessageWithCode(codeSubmarinerNotInstalled, fmt.Sprintf("Submariner is not installed in"+
+					" cluster %q", item.clusterName))
+			}
+
+			continue
+		}
+
+		if !submariner.Spec.ServiceDiscoveryEnabled {
+			continue
+		}
+
+		operatorClient, err := subOperatorClientset.NewForConfig(item.config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client for cluster"+
+				" %q: %s", item.clusterName, err))
+			continue
+		}
+
+		serviceDiscovery, err := operatorClient.SubmarinerV1alpha1().ServiceDiscoveries(OperatorNamespace).Get(context.TODO(),
+			names.ServiceDiscoveryCrName, metav1.GetOptions{})
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving the ServiceDiscovery resource from cluster %q: %s",
+				item.clusterName, err))
+			continue
+		}
+
+		domains := stringset.New(clusterSetDomain)
+		for _, domain := range serviceDiscovery.Spec.CustomDomains {
+			domains.Add(strings.TrimSuffix(domain, "."))
+		}
+
+		served := domains.Elements()
+		sort.Strings(served)
+
+		key := strings.Join(served, ",")
+		clustersByDomains[key] = append(clustersByDomains[key], item.clusterName)
+	}
+
+	if len(clustersByDomains) > 1 {
+		keys := make([]string, 0, len(clustersByDomains))
+		for key := range clustersByDomains {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			clusters := clustersByDomains[key]
+			sort.Strings(clusters)
+			status.QueueFailureMessageWithCode(codeClusterSetDomainMismatch,
+				fmt.Sprintf("Clusters %v serve the clusterset domains [%s]", clusters, key))
+		}
+
+		status.QueueFailureMessageWithCode(codeClusterSetDomainMismatch,
+			"The clusters must serve the same clusterset domains for their services to be resolved"+
+				" in all of them")
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	for key, clusters := range clustersByDomains {
+		status.QueueSuccessMessage(fmt.Sprintf("All the %d clusters with service discovery serve the clusterset domains [%s]",
+			len(clusters), key))
+	}
+
+	status.End(cli.Success)
+	return true
+}
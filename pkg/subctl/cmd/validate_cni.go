@@ -20,7 +20,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
 
 	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 	"github.com/submariner-io/submariner/pkg/routeagent_driver/constants"
@@ -33,7 +32,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/client-go/rest"
 )
 
 var supportedNetworkPlugins = []string{constants.NetworkPluginGeneric, constants.NetworkPluginCanalFlannel, constants.NetworkPluginWeaveNet,
@@ -65,24 +63,32 @@ func validateCNIConfig(cmd *cobra.Command, args []string) {
 	validationStatus := true
 
 	for _, item := range configs {
+		status.SetCluster(item.clusterName)
 		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
-		submariner := getSubmarinerResource(item.config)
+		submariner, err := retrieveSubmariner(item.config)
 		if submariner == nil {
-			status.QueueWarningMessage(submMissingMessage)
-			status.End(cli.Success)
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
 			continue
 		}
 		status.End(cli.Success)
-		if !validateCNIInCluster(item.config, item.clusterName, submariner) {
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		if !validateCNIInCluster(clients, item.clusterName, submariner) {
 			validationStatus = false
 		}
 	}
-	if !validationStatus {
-		os.Exit(1)
-	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
 }
 
-func validateCNIInCluster(config *rest.Config, clusterName string, submariner *v1alpha1.Submariner) bool {
+func validateCNIInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
 	message := fmt.Sprintf("Checking Submariner support for the CNI network"+
 		" plugin in cluster %q", clusterName)
 	status.Start(message)
@@ -98,7 +104,7 @@ func validateCNIInCluster(config *rest.Config, clusterName string, submariner *v
 	if !isSupportedPlugin {
 		message := fmt.Sprintf("The detected CNI network plugin (%q) is not supported by Submariner."+
 			" Supported network plugins: %v\n", submariner.Status.NetworkPlugin, supportedNetworkPlugins)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCNIUnsupported, message)
 		status.End(cli.Failure)
 		return false
 	}
@@ -108,7 +114,7 @@ func validateCNIInCluster(config *rest.Config, clusterName string, submariner *v
 	status.QueueSuccessMessage(message)
 	status.End(cli.Success)
 
-	calicoCNIStatus := validateCalicoIPPoolsIfCalicoCNI(config)
+	calicoCNIStatus := validateCalicoIPPoolsIfCalicoCNI(clients)
 	return calicoCNIStatus
 }
 
@@ -126,14 +132,8 @@ func findCalicoConfigMap(clientSet kubernetes.Interface) (*v1.ConfigMap, error)
 	return nil, nil
 }
 
-func validateCalicoIPPoolsIfCalicoCNI(config *rest.Config) bool {
-	dynClient, clientSet, err := getClients(config)
-	if err != nil {
-		message := fmt.Sprintf("Error creating client - unable to detect the Calico CNI: %s", err)
-		status.Start(message)
-		status.End(cli.Failure)
-		return false
-	}
+func validateCalicoIPPoolsIfCalicoCNI(clients *clusterClients) bool {
+	dynClient, clientSet := clients.dynamicClient, clients.kubeClient
 
 	calicoConfig, err := findCalicoConfigMap(clientSet)
 	if err != nil {
@@ -150,10 +150,10 @@ func validateCalicoIPPoolsIfCalicoCNI(config *rest.Config) bool {
 	message := "Calico CNI detected, verifying if the Submariner IPPool pre-requisites are configured."
 	status.Start(message)
 
-	gateways := getGatewaysResource(config)
+	gateways := getGatewaysResourceWithClient(clients.submarinerClient)
 	if gateways == nil {
 		message = "There are no gateways detected on the cluster"
-		status.QueueWarningMessage(message)
+		status.QueueWarningMessageWithCode(codeNoGateway, message)
 		status.End(cli.Warning)
 		return false
 	}
@@ -163,14 +163,14 @@ func validateCalicoIPPoolsIfCalicoCNI(config *rest.Config) bool {
 	ippoolList, err := client.List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		message := fmt.Sprintf("Error obtaining IPPools: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeAPIListFailed, message)
 		status.End(cli.Failure)
 		return false
 	}
 
 	if len(ippoolList.Items) < 1 {
 		message := "Could not find any IPPools in the cluster"
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCalicoIPPoolsMissing, message)
 		status.End(cli.Failure)
 		return false
 	}
@@ -180,13 +180,13 @@ func validateCalicoIPPoolsIfCalicoCNI(config *rest.Config) bool {
 		cidr, found, err := unstructured.NestedString(pool.Object, "spec", "cidr")
 		if err != nil {
 			message := fmt.Sprintf("Error extracting field cidr from IPPool %q", pool.GetName())
-			status.QueueFailureMessage(message)
+			status.QueueFailureMessageWithCode(codeCalicoIPPoolInvalid, message)
 			continue
 		}
 
 		if !found {
 			message := fmt.Sprintf("No CIDR found in IPPool %q", pool.GetName())
-			status.QueueFailureMessage(message)
+			status.QueueFailureMessageWithCode(codeCalicoIPPoolInvalid, message)
 			continue
 		}
 		ippools[cidr] = pool
@@ -203,20 +203,36 @@ func validateCalicoIPPoolsIfCalicoCNI(config *rest.Config) bool {
 				if found {
 					isDisabled, err := getSpecBool(ipPool, "disabled")
 					if err != nil {
-						status.QueueFailureMessage(err.Error())
+						status.QueueFailureMessageWithCode(codeCalicoIPPoolInvalid, err.Error())
 						continue
 					}
 
 					// When disabled is set to true, Calico IPAM will not assign addresses from this Pool.
 					// The IPPools configured for Submariner remote CIDRs should have disabled as true.
 					if !isDisabled {
-						status.QueueFailureMessage(fmt.Sprintf("The IPPool %q with CIDR %q for remote endpoint"+
-							" %q has disabled set to false", ipPool.GetName(), subnet, connection.Endpoint.CableName))
+						status.QueueFailureMessageWithCode(codeCalicoIPPoolMismatch,
+							fmt.Sprintf("The IPPool %q with CIDR %q for remote endpoint"+
+								" %q has disabled set to false", ipPool.GetName(), subnet, connection.Endpoint.CableName))
+						continue
+					}
+
+					// Traffic to the remote CIDRs must not be NATed, otherwise the return traffic is lost
+					natOutgoing, _, err := unstructured.NestedBool(ipPool.Object, "spec", "natOutgoing")
+					if err != nil {
+						status.QueueFailureMessageWithCode(codeCalicoIPPoolInvalid, err.Error())
+						continue
+					}
+
+					if natOutgoing {
+						status.QueueFailureMessageWithCode(codeCalicoIPPoolMismatch,
+							fmt.Sprintf("The IPPool %q with CIDR %q for remote endpoint"+
+								" %q has natOutgoing set to true", ipPool.GetName(), subnet, connection.Endpoint.CableName))
 						continue
 					}
 				} else {
-					status.QueueFailureMessage(fmt.Sprintf("Could not find any IPPool with CIDR %q for remote"+
-						" endpoint %q", subnet, connection.Endpoint.CableName))
+					status.QueueFailureMessageWithCode(codeCalicoIPPoolMismatch,
+						fmt.Sprintf("Could not find any IPPool with CIDR %q for remote"+
+							" endpoint %q", subnet, connection.Endpoint.CableName))
 					continue
 				}
 			}
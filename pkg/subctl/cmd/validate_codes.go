@@ -0,0 +1,228 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+// The codes identifying the kinds of failures and warnings raised by the diagnose checks. They are displayed with the
+// messages and included in the JSON results, so that the issues can be alerted on, suppressed and documented
+// independently of the message text: a code must never be reused for another kind of issue.
+const (
+	// The failures and warnings which haven't been assigned a code
+	codeUnclassifiedFailure = "SM-GEN-001"
+	codeUnclassifiedWarning = "SM-GEN-002"
+
+	// The diagnose run; SM-RUN-001 was raised by the checks skipped in read-only mode, which are now reported as skipped
+	codeFailedOnWarning = "SM-RUN-002"
+	codeCheckRegressed  = "SM-RUN-003"
+	codeAPIThrottled    = "SM-RUN-004"
+	codeInputInvalid    = "SM-RUN-005"
+	codeReportFailed    = "SM-RUN-006"
+
+	// Accessing the API servers
+	codeAPIClientFailed = "SM-API-001"
+	codeAPIListFailed   = "SM-API-002"
+	codeAPIGetFailed    = "SM-API-003"
+
+	// The Submariner resource
+	codeSubmarinerNotInstalled = "SM-SUB-001"
+	codeSubmarinerUnavailable  = "SM-SUB-002"
+
+	// The Submariner components
+	codeComponentNotReady         = "SM-DEP-001"
+	codeComponentDisabled         = "SM-DEP-002"
+	codeComponentRestarting       = "SM-DEP-003"
+	codeContainerResourcesMissing = "SM-DEP-004"
+	codeContainerOOMKilled        = "SM-DEP-005"
+
+	// The pods and services spawned by the checks to probe the clusters
+	codeCheckPodFailed     = "SM-POD-001"
+	codeNodeProbeFailed    = "SM-POD-002"
+	codeCheckServiceFailed = "SM-POD-003"
+
+	// The CIDRs of the clusters
+	codeClusterCIDROverlap      = "SM-CIDR-001"
+	codeCIDRDrift               = "SM-CIDR-002"
+	codeEndpointSubnetsMismatch = "SM-CIDR-003"
+	codeNodeIPOverlap           = "SM-CIDR-004"
+	codePreflightCIDROverlap    = "SM-CIDR-005"
+	codeCIDRsNotDiscovered      = "SM-CIDR-006"
+	codeExpectedCIDRsUnlisted   = "SM-CIDR-007"
+	codeCIDRUnrecorded          = "SM-CIDR-008"
+	codeCIDRInvalid             = "SM-CIDR-009"
+
+	// Globalnet
+	codeGlobalCIDRRangeOverlap      = "SM-GN-001"
+	codeGlobalCIDRAllocationOverlap = "SM-GN-002"
+	codeGlobalCIDRUnallocated       = "SM-GN-003"
+	codeGlobalCIDRStaleAllocation   = "SM-GN-004"
+	codeGlobalCIDRMismatch          = "SM-GN-005"
+	codeGlobalIPsNearlyExhausted    = "SM-GN-006"
+	codeGlobalIPsUnallocated        = "SM-GN-007"
+	codeGlobalnetConfigMapMalformed = "SM-GN-008"
+	codeGlobalnetSettingsMismatch   = "SM-GN-009"
+	codeGlobalnetConfigMapLegacy    = "SM-GN-010"
+	codeGlobalCIDRInvalid           = "SM-GN-011"
+	codeGlobalEgressIPConflict      = "SM-GN-012"
+	codeGlobalEgressIPUnallocated   = "SM-GN-013"
+	codeGlobalEgressIPOutsideCIDR   = "SM-GN-014"
+	codeGlobalEgressIPCountMismatch = "SM-GN-015"
+	codeGlobalEgressIPOrphaned      = "SM-GN-016"
+	codeGlobalEgressIPBadSelector   = "SM-GN-017"
+	codeGlobalEgressIPSelectsNone   = "SM-GN-018"
+	codeGlobalIngressIPUnallocated  = "SM-GN-019"
+	codeGlobalnetFlowBroken         = "SM-GN-020"
+	codeGlobalnetInconsistent       = "SM-GN-021"
+	codeGlobalCIDRAllocationInvalid = "SM-GN-022"
+	codeGlobalCIDRSizeMismatch      = "SM-GN-023"
+
+	// Versions
+	codeK8sVersionUnsupported    = "SM-VER-001"
+	codeVersionSkew              = "SM-VER-002"
+	codeComponentVersionMismatch = "SM-VER-003"
+	codeComponentVersionUnknown  = "SM-VER-004"
+	codeK8sVersionUnknown        = "SM-VER-005"
+
+	// The CNI network plugin
+	codeCNIUnsupported       = "SM-CNI-001"
+	codeCalicoIPPoolMismatch = "SM-CNI-002"
+	codeCalicoIPPoolsMissing = "SM-CNI-003"
+	codeCalicoIPPoolInvalid  = "SM-CNI-004"
+
+	// The gateways
+	codeGatewayPortMissing       = "SM-GW-001"
+	codeGatewayStalePort         = "SM-GW-002"
+	codeGatewayLBNoAddress       = "SM-GW-003"
+	codeGatewayHAModeMismatch    = "SM-GW-004"
+	codeMultipleActiveGateways   = "SM-GW-005"
+	codeNoGateway                = "SM-GW-006"
+	codeMultipleClusterEndpoints = "SM-GW-007"
+	codeLocalEndpointMissing     = "SM-GW-008"
+	codeGatewayNoDefaultRoute    = "SM-GW-009"
+	codeGatewayEgressBlocked     = "SM-GW-010"
+	codeGatewayNoRouteToPeer     = "SM-GW-011"
+	codeGatewayLBEvent           = "SM-GW-012"
+	codeGatewayPodUnavailable    = "SM-GW-013"
+
+	// The connections between the clusters
+	codeNoConnections        = "SM-CONN-001"
+	codeConnectionConnecting = "SM-CONN-002"
+	codeConnectionError      = "SM-CONN-003"
+	codeConnectionMissing    = "SM-CONN-004"
+	codeConnectivityUnprobed = "SM-CONN-005"
+	codeServiceUnreachable   = "SM-CONN-006"
+	codeIntraClusterBroken   = "SM-CONN-007"
+	codeSourceIPNotReceived  = "SM-CONN-008"
+	codeSourceIPNATed        = "SM-CONN-009"
+
+	// The firewalls between the nodes and the clusters
+	codeTunnelPortUnknown     = "SM-FW-001"
+	codeTunnelTrafficBlocked  = "SM-FW-002"
+	codeVXLANTrafficBlocked   = "SM-FW-003"
+	codeVXLANTrafficMangled   = "SM-FW-004"
+	codeMetricsTrafficBlocked = "SM-FW-005"
+
+	// The cable drivers
+	codeIPsecNoSecurityAssociations = "SM-CABLE-001"
+	codeIPsecCipherNonCompliant     = "SM-CABLE-002"
+	codeWireGuardKeyMissing         = "SM-CABLE-003"
+	codeWireGuardKeyMismatch        = "SM-CABLE-004"
+	codeCableDriverUnknown          = "SM-CABLE-005"
+
+	// The health checks between the gateways
+	codeHealthCheckIPMissing   = "SM-HC-001"
+	codeHealthCheckUnreachable = "SM-HC-002"
+
+	// Service discovery
+	codeClusterSetDomainMismatch     = "SM-DNS-001"
+	codeDNSNotForwarded              = "SM-DNS-002"
+	codeDNSDomainNotServed           = "SM-DNS-003"
+	codeServiceDiscoveryInconsistent = "SM-DNS-004"
+	codeServiceImportMalformed       = "SM-DNS-005"
+	codeServiceImportNotGlobal       = "SM-DNS-006"
+	codeServiceNotImported           = "SM-DNS-007"
+	codeServiceImportNoEndpoints     = "SM-DNS-008"
+	codeServiceExportInvalid         = "SM-DNS-009"
+	codeServiceExportConflict        = "SM-DNS-010"
+	codeLighthouseDNSNoClusterIP     = "SM-DNS-011"
+
+	// Certificates
+	codeCertificateExpired    = "SM-CERT-001"
+	codeCertificateExpiring   = "SM-CERT-002"
+	codeCertificateUnreadable = "SM-CERT-003"
+
+	// NAT traversal
+	codeNATTraversalMismatch    = "SM-NAT-001"
+	codeEndpointPrivateIPAbsent = "SM-NAT-002"
+	codeNATTraversalDisabled    = "SM-NAT-003"
+	codeEndpointPublicIPAbsent  = "SM-NAT-004"
+
+	// MTUs
+	codeMTUMismatch   = "SM-MTU-001"
+	codeMTUUnmeasured = "SM-MTU-002"
+
+	// The operator
+	codeObservedGenerationNotReported = "SM-OP-001"
+	codeReconcileLagging              = "SM-OP-002"
+	codeOperatorLeaderMissing         = "SM-OP-003"
+	codeOperatorLeaderUnowned         = "SM-OP-004"
+	codeOperatorLeaderStale           = "SM-OP-005"
+	codeOperatorLeaderTerminating     = "SM-OP-006"
+	codeOperatorLeaderUnhealthy       = "SM-OP-007"
+	codeFinalizerPresent              = "SM-OP-008"
+	codeOperatorCSVFailed             = "SM-OP-009"
+
+	// The broker
+	codeClusterNotRegistered     = "SM-BRK-001"
+	codeClusterOrphaned          = "SM-BRK-002"
+	codeBrokerCRDNotEstablished  = "SM-BRK-003"
+	codeBrokerCRDVersionMismatch = "SM-BRK-004"
+	codeBrokerEndpointOrphaned   = "SM-BRK-005"
+	codeBrokerEndpointMissing    = "SM-BRK-006"
+	codeBrokerChecksumMismatch   = "SM-BRK-007"
+
+	// The admission webhooks
+	codeWebhookCABundleMissing      = "SM-HOOK-001"
+	codeWebhookServiceMissing       = "SM-HOOK-002"
+	codeWebhookServiceNotReady      = "SM-HOOK-003"
+	codeWebhookEndpointsUnavailable = "SM-HOOK-004"
+
+	// The pod privileges and the Pod Security admission
+	codePodSecurityEnforced    = "SM-SEC-001"
+	codePodSecurityReported    = "SM-SEC-002"
+	codePrivilegesNotRequested = "SM-SEC-003"
+	codePrivilegesRemoved      = "SM-SEC-004"
+
+	// The container images
+	codeImagePullFailed  = "SM-IMG-001"
+	codeImagePullTimeout = "SM-IMG-002"
+
+	// Kernel modules
+	codeKernelModulesMissing = "SM-KMOD-001"
+
+	// kube-proxy
+	codeKubeProxyIPVS    = "SM-KP-001"
+	codeKubeProxyMissing = "SM-KP-002"
+)
+
+// codeOrDefault returns the code of a failure or warning, or the unclassified code if it has none
+func codeOrDefault(code, unclassified string) string {
+	if code == "" {
+		return unclassified
+	}
+
+	return code
+}
@@ -0,0 +1,99 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/submariner-io/submariner-operator/pkg/images"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+// checkComponentVersions checks that the gateway and the route agent of a cluster run the same Submariner version;
+// they can differ after a partial upgrade, and speak incompatible protocols
+func checkComponentVersions(clusterName string, clients *clusterClients, namespace string) bool {
+	status.Start(fmt.Sprintf("Checking that the gateway and route agent versions match in %q", clusterName))
+
+	gatewayVersion, gatewayPinned, err := daemonSetImageVersion(clients, namespace, "submariner-gateway")
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeComponentVersionUnknown, fmt.Sprintf("Unable to determine the version of the gateway: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	routeAgentVersion, routeAgentPinned, err := daemonSetImageVersion(clients, namespace, "submariner-routeagent")
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeComponentVersionUnknown, fmt.Sprintf("Unable to determine the version of the"+
+			" route agent: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	// The gateway and route agent images always have different digests, only their tags can be compared
+	if gatewayPinned || routeAgentPinned {
+		status.QueueWarningMessageWithCode(codeComponentVersionUnknown, "The gateway or the route agent image is pinned"+
+			" by digest without a tag, so their versions can't be compared")
+		status.End(cli.Warning)
+		return true
+	}
+
+	if gatewayVersion != routeAgentVersion {
+		status.QueueFailureMessageWithCode(codeComponentVersionMismatch,
+			fmt.Sprintf("The gateway runs version %q but the route agent runs version %q;"+
+				" complete the upgrade so that they match", gatewayVersion, routeAgentVersion))
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("The gateway and the route agent both run version %q", gatewayVersion))
+	status.End(cli.Success)
+	return true
+}
+
+// daemonSetImageVersion returns the tag of the image of the DaemonSet's first container, or true if the image is
+// pinned by digest without a tag
+func daemonSetImageVersion(clients *clusterClients, namespace, name string) (string, bool, error) {
+	daemonSet, err := clients.kubeClient.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", false, fmt.Errorf("error obtaining DaemonSet %q: %v", name, err)
+	}
+
+	containers := daemonSet.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return "", false, fmt.Errorf("the DaemonSet %q has no containers", name)
+	}
+
+	image := containers[0].Image
+	if i := strings.Index(image, "@"); i != -1 {
+		// A tag may precede the digest, e.g. repository/image:tag@sha256:...
+		reference := image[:i]
+		if j := strings.LastIndex(reference, ":"); j > strings.LastIndex(reference, "/") {
+			return reference[j+1:], false, nil
+		}
+
+		return "", true, nil
+	}
+
+	version, _ := images.ParseOperatorImage(image)
+
+	return version, false, nil
+}
@@ -0,0 +1,65 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newImageDaemonSet(name, image string) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: OperatorNamespace},
+		Spec: appsv1.DaemonSetSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{Containers: []v1.Container{{Name: name, Image: image}}},
+			},
+		},
+	}
+}
+
+func TestDaemonSetImageVersion(t *testing.T) {
+	tests := []struct {
+		image   string
+		version string
+		pinned  bool
+	}{
+		{image: "quay.io/submariner/submariner-gateway:0.10.0", version: "0.10.0"},
+		{image: "quay.io/submariner/submariner-gateway:0.10.0@sha256:0123abcd", version: "0.10.0"},
+		{image: "localhost:5000/submariner/submariner-gateway@sha256:0123abcd", pinned: true},
+	}
+
+	for _, test := range tests {
+		clients := &clusterClients{kubeClient: fake.NewSimpleClientset(newImageDaemonSet("submariner-gateway", test.image))}
+
+		version, pinned, err := daemonSetImageVersion(clients, OperatorNamespace, "submariner-gateway")
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %s", test.image, err)
+		}
+
+		if version != test.version || pinned != test.pinned {
+			t.Fatalf("Expected version %q and pinned %t for %q, got %q and %t", test.version, test.pinned, test.image,
+				version, pinned)
+		}
+	}
+}
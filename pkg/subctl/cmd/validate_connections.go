@@ -19,14 +19,26 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io/ioutil"
+	"sort"
 
 	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
 	submv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
-	"k8s.io/client-go/rest"
+	"sigs.k8s.io/yaml"
 )
 
+// connectionsTopology describes a hub-and-spoke clusterset: hubs are expected to connect to every other cluster,
+// spokes only to the hubs
+type connectionsTopology struct {
+	Hubs   []string `json:"hubs"`
+	Spokes []string `json:"spokes"`
+}
+
+var connectionsTopologyFile string
+
 var validateConnectionsCmd = &cobra.Command{
 	Use:   "connections",
 	Short: "Check the Gateway connections",
@@ -35,9 +47,15 @@ var validateConnectionsCmd = &cobra.Command{
 }
 
 func init() {
+	addConnectionsTopologyFlag(validateConnectionsCmd)
 	validateCmd.AddCommand(validateConnectionsCmd)
 }
 
+func addConnectionsTopologyFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&connectionsTopologyFile, "topology", "",
+		"YAML file listing the \"hubs\" and \"spokes\" cluster IDs; only the hub-spoke and hub-hub connections are checked")
+}
+
 func validateConnections(cmd *cobra.Command, args []string) {
 	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
 	exitOnError("Error getting REST config for cluster", err)
@@ -45,29 +63,44 @@ func validateConnections(cmd *cobra.Command, args []string) {
 	validationStatus := true
 
 	for _, item := range configs {
+		status.SetCluster(item.clusterName)
 		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
-		submariner := getSubmarinerResource(item.config)
+		submariner, err := retrieveSubmariner(item.config)
 		if submariner == nil {
-			status.QueueWarningMessage(submMissingMessage)
-			status.End(cli.Success)
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
 			continue
 		}
 		status.End(cli.Success)
-		validationStatus = validationStatus && validateConnectionsInCluster(item.config, item.clusterName)
-	}
-	if !validationStatus {
-		os.Exit(1)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateConnectionsInCluster(clients, item.clusterName, submariner) && validationStatus
 	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
 }
 
-func validateConnectionsInCluster(config *rest.Config, clusterName string) bool {
+func validateConnectionsInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
 	message := fmt.Sprintf("Checking Gateway connections in cluster %q", clusterName)
 	status.Start(message)
 
-	gateways := getGatewaysResource(config)
+	peers, err := getIntendedPeers(submariner.Spec.ClusterID)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeInputInvalid, fmt.Sprintf("Error reading the topology: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	gateways := getGatewaysResourceWithClient(clients.submarinerClient)
 	if gateways == nil {
 		message = "There are no gateways detected"
-		status.QueueWarningMessage(message)
+		status.QueueWarningMessageWithCode(codeNoGateway, message)
 		status.End(cli.Failure)
 		return false
 	}
@@ -80,19 +113,40 @@ func validateConnectionsInCluster(config *rest.Config, clusterName string) bool
 
 		if len(gateway.Status.Connections) == 0 {
 			message = "There are no active connections"
-			status.QueueFailureMessage(message)
+			status.QueueFailureMessageWithCode(codeNoConnections, message)
 			status.End(cli.Failure)
 			return false
 		}
 
+		connected := stringset.New()
+
 		for _, connection := range gateway.Status.Connections {
-			if connection.Status == submv1.Connecting {
+			if peers != nil && !peers.Contains(connection.Endpoint.ClusterID) {
+				continue
+			}
+
+			connected.Add(connection.Endpoint.ClusterID)
+
+			if connection.Status == submv1.Connected && peers != nil {
+				status.QueueSuccessMessage(fmt.Sprintf("Connection to cluster %q is established", connection.Endpoint.ClusterID))
+			} else if connection.Status == submv1.Connecting {
 				message = fmt.Sprintf("Connection to cluster %q is in progress", connection.Endpoint.ClusterID)
-				status.QueueFailureMessage(message)
+				status.QueueFailureMessageWithCode(codeConnectionConnecting, message)
 				allConnectionsEstablished = false
 			} else if connection.Status == submv1.ConnectionError {
 				message = fmt.Sprintf("Connection to cluster %q is not established", connection.Endpoint.ClusterID)
-				status.QueueFailureMessage(message)
+				status.QueueFailureMessageWithCode(codeConnectionError, message)
+				allConnectionsEstablished = false
+			}
+		}
+
+		if peers == nil {
+			continue
+		}
+
+		for _, peer := range sortedElements(peers) {
+			if !connected.Contains(peer) {
+				status.QueueFailureMessageWithCode(codeConnectionMissing, fmt.Sprintf("There is no connection to cluster %q", peer))
 				allConnectionsEstablished = false
 			}
 		}
@@ -108,3 +162,45 @@ func validateConnectionsInCluster(config *rest.Config, clusterName string) bool
 	status.End(cli.Success)
 	return true
 }
+
+// getIntendedPeers returns the clusters the given cluster is expected to connect to according to the topology,
+// or nil if all the connections are expected
+func getIntendedPeers(clusterID string) (stringset.Interface, error) {
+	if connectionsTopologyFile == "" {
+		return nil, nil
+	}
+
+	contents, err := ioutil.ReadFile(connectionsTopologyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &connectionsTopology{}
+	if err := yaml.Unmarshal(contents, topology); err != nil {
+		return nil, err
+	}
+
+	hubs := stringset.New(topology.Hubs...)
+
+	var peers stringset.Interface
+
+	switch {
+	case hubs.Contains(clusterID):
+		peers = stringset.New(append(topology.Hubs, topology.Spokes...)...)
+	case stringset.New(topology.Spokes...).Contains(clusterID):
+		peers = hubs
+	default:
+		return nil, nil
+	}
+
+	peers.Remove(clusterID)
+
+	return peers, nil
+}
+
+func sortedElements(set stringset.Interface) []string {
+	elements := set.Elements()
+	sort.Strings(elements)
+
+	return elements
+}
@@ -0,0 +1,423 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	lhconstants "github.com/submariner-io/lighthouse/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	mcsv1a1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const connectivityCheckTimeout = 3
+
+const (
+	tcpProtocol  = "tcp"
+	udpProtocol  = "udp"
+	icmpProtocol = "icmp"
+)
+
+// The commands probing a target over each protocol; UDP targets are only reachable if they reply to the datagram
+var connectivityProbes = map[string]string{
+	tcpProtocol:  "nc -w %[1]d %[2]s %[3]d </dev/null",
+	udpProtocol:  "echo | nc -u -w %[1]d %[2]s %[3]d | grep -q .",
+	icmpProtocol: "ping -c 1 -W %[1]d %[2]s",
+}
+
+var validateConnectivityCmd = &cobra.Command{
+	Use:   "connectivity",
+	Short: "Check the connectivity to the services imported from the other clusters",
+	Long: "This command checks, from a pod on a non-Gateway node, that a sample of the services imported from the" +
+		" other clusters can be reached on their service (or global ingress) IPs and on the IPs of their pods." +
+		" With --service-cidr-only, only the service IPs are checked, which isolates service handling issues" +
+		" from pod connectivity issues. With Globalnet, only the global IPs of the services are checked, the pod IPs" +
+		" aren't routable across the clusters. With ICMP, the service ClusterIPs aren't pinged since they don't" +
+		" answer it. The targets are probed over TCP on their first TCP port by default;" +
+		" --protocol and --port select another protocol or port, e.g. where ICMP or some ports are filtered.",
+	Run: validateConnectivity,
+}
+
+var (
+	serviceCIDROnly        bool
+	connectivitySampleSize int
+	connectivityProtocol   string
+	connectivityPort       int
+)
+
+// connectivityTarget is an address of a remote service to connect to
+type connectivityTarget struct {
+	service string
+	cluster string
+	ip      string
+	port    int32
+	// set for service (or global ingress) IPs, unset for pod IPs
+	serviceIP bool
+	globalIP  bool
+}
+
+func (t *connectivityTarget) String() string {
+	kind := "pod"
+	if t.globalIP {
+		kind = "global"
+	} else if t.serviceIP {
+		kind = "service"
+	}
+
+	if connectivityProtocol == icmpProtocol {
+		return fmt.Sprintf("%s IP %s over ICMP", kind, t.ip)
+	}
+
+	return fmt.Sprintf("%s IP %s:%d over %s", kind, t.ip, t.port, strings.ToUpper(connectivityProtocol))
+}
+
+func (t *connectivityTarget) address() string {
+	return fmt.Sprintf("%s:%d", t.ip, t.port)
+}
+
+func init() {
+	addValidateFWConfigFlags(validateConnectivityCmd)
+	validateConnectivityCmd.Flags().BoolVar(&serviceCIDROnly, "service-cidr-only", false,
+		"only check the service IPs of the remote services, not the IPs of their pods")
+	validateConnectivityCmd.Flags().IntVar(&connectivitySampleSize, "sample-size", 5,
+		"maximum number of imported services whose connectivity is checked")
+	validateConnectivityCmd.Flags().StringVar(&connectivityProtocol, "protocol", tcpProtocol,
+		fmt.Sprintf("protocol used to probe the targets - any of %s,%s,%s; UDP targets must reply to be reachable",
+			tcpProtocol, udpProtocol, icmpProtocol))
+	validateConnectivityCmd.Flags().IntVar(&connectivityPort, "port", 0,
+		"port probed on the targets, instead of the first port of the services using the protocol")
+	validateCmd.AddCommand(validateConnectivityCmd)
+}
+
+func validateConnectivity(cmd *cobra.Command, args []string) {
+	err := isValidConnectivityProbe(connectivityProtocol, connectivityPort)
+	exitOnError("Invalid connectivity probe", err)
+
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+		validationStatus = validateConnectivityInCluster(item.config, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateConnectivityInCluster(config *rest.Config, clusterName string, submariner *v1alpha1.Submariner) bool {
+	if serviceCIDROnly {
+		status.Start(fmt.Sprintf("Checking the connectivity to the remote service IPs from cluster %q", clusterName))
+	} else {
+		status.Start(fmt.Sprintf("Checking the connectivity to the remote services and pods from cluster %q", clusterName))
+	}
+
+	if skipInReadOnlyMode("connectivity", clusterName) {
+		return true
+	}
+
+	if !submariner.Spec.ServiceDiscoveryEnabled {
+		status.QueueSuccessMessage("This check requires service discovery, which is not enabled")
+		status.End(cli.Success)
+		return true
+	}
+
+	serviceIPs, podIPs := connectivityTargetKinds(submariner.Spec.GlobalCIDR != "")
+	if !serviceIPs && !podIPs {
+		status.QueueWarningMessageWithCode(codeConnectivityUnprobed, "The service ClusterIPs don't answer ICMP, so there's"+
+			" nothing to ping with --service-cidr-only; use --protocol tcp or udp instead")
+		status.End(cli.Warning)
+		return true
+	}
+
+	if !serviceIPs {
+		status.QueueSuccessMessage("Only the pod IPs are pinged: the service ClusterIPs don't answer ICMP")
+	}
+
+	dynClient, _, err := getClients(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	targets, err := getConnectivityTargets(dynClient, clientSet, submariner.Spec.ClusterID, submariner.Spec.GlobalCIDR != "")
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the imported services: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if submariner.Spec.GlobalCIDR != "" && !serviceCIDROnly {
+		status.QueueSuccessMessage("Globalnet is enabled, so only the global IPs of the services are checked: the pod IPs" +
+			" aren't routable across the clusters")
+	}
+
+	if len(targets) == 0 {
+		status.QueueWarningMessageWithCode(codeConnectivityUnprobed,
+			"No services imported from other clusters were found, so the connectivity can't be checked")
+		status.End(cli.Warning)
+		return true
+	}
+
+	reachable, err := runConnectivityClientPod(clientSet, targets)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, err.Error())
+		status.End(cli.Failure)
+		return false
+	}
+
+	for i := range targets {
+		target := &targets[i]
+		if reachable.Contains(target.address()) {
+			status.QueueSuccessMessage(fmt.Sprintf("Service %q from cluster %q is reachable on its %s", target.service,
+				target.cluster, target))
+		} else {
+			status.QueueFailureMessageWithCode(codeServiceUnreachable, fmt.Sprintf("Service %q from cluster %q is not"+
+				" reachable on its %s", target.service, target.cluster, target))
+		}
+	}
+
+	result := status.ResultFromMessages()
+	status.End(result)
+	return result != cli.Failure
+}
+
+func isValidConnectivityProbe(protocol string, port int) error {
+	if _, ok := connectivityProbes[protocol]; !ok {
+		return fmt.Errorf("unknown protocol %q, expected one of %s,%s,%s", protocol, tcpProtocol, udpProtocol, icmpProtocol)
+	}
+
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+
+	return nil
+}
+
+// connectivityTargetKinds returns whether the service (or global ingress) IPs and the pod IPs of the remote services are
+// probed; with Globalnet, the pod IPs aren't routable across the clusters, and the service ClusterIPs don't answer ICMP
+func connectivityTargetKinds(globalnet bool) (serviceIPs, podIPs bool) {
+	return globalnet || connectivityProtocol != icmpProtocol, !serviceCIDROnly && !globalnet
+}
+
+// getConnectivityTargets returns the service IPs, and one pod IP where they're probed, of a sample of the services
+// imported from other clusters with a port using the probed protocol
+func getConnectivityTargets(dynClient dynamic.Interface, clientSet kubernetes.Interface, localClusterID string,
+	globalnet bool) ([]connectivityTarget, error) {
+	serviceIPs, podIPs := connectivityTargetKinds(globalnet)
+
+	importList, err := dynClient.Resource(serviceImportsGVR).Namespace(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	imports := []*mcsv1a1.ServiceImport{}
+
+	for i := range importList.Items {
+		serviceImport := &mcsv1a1.ServiceImport{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(importList.Items[i].Object, serviceImport); err != nil {
+			return nil, err
+		}
+
+		if serviceImport.Spec.Type == mcsv1a1.ClusterSetIP &&
+			serviceImport.Labels[lhconstants.LabelSourceCluster] != localClusterID {
+			imports = append(imports, serviceImport)
+		}
+	}
+
+	sort.Slice(imports, func(i, j int) bool {
+		return imports[i].Namespace+"/"+imports[i].Name < imports[j].Namespace+"/"+imports[j].Name
+	})
+
+	if len(imports) > connectivitySampleSize {
+		imports = imports[:connectivitySampleSize]
+	}
+
+	targets := []connectivityTarget{}
+
+	for _, serviceImport := range imports {
+		service := fmt.Sprintf("%s/%s", serviceImport.Labels[lhconstants.LabelSourceNamespace],
+			serviceImport.Labels[lhconstants.LabelSourceName])
+		cluster := serviceImport.Labels[lhconstants.LabelSourceCluster]
+
+		port, ok := firstProbedPort(serviceImport.Spec.Ports)
+		if !ok {
+			continue
+		}
+
+		if serviceIPs {
+			for _, ip := range serviceImport.Spec.IPs {
+				targets = append(targets, connectivityTarget{service: service, cluster: cluster, ip: ip, port: port,
+					serviceIP: true, globalIP: globalnet})
+			}
+		}
+
+		if !podIPs {
+			continue
+		}
+
+		podTarget, err := getPodConnectivityTarget(clientSet, serviceImport, service, cluster)
+		if err != nil {
+			return nil, err
+		}
+
+		if podTarget != nil {
+			targets = append(targets, *podTarget)
+		}
+	}
+
+	return targets, nil
+}
+
+// getPodConnectivityTarget returns the first pod address in the EndpointSlices synced for the imported service
+func getPodConnectivityTarget(clientSet kubernetes.Interface, serviceImport *mcsv1a1.ServiceImport, service, cluster string) (
+	*connectivityTarget, error) {
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{
+		discoveryv1beta1.LabelManagedBy:  lhconstants.LabelValueManagedBy,
+		lhconstants.LabelSourceName:      serviceImport.Labels[lhconstants.LabelSourceName],
+		lhconstants.LabelSourceNamespace: serviceImport.Labels[lhconstants.LabelSourceNamespace],
+		lhconstants.LabelSourceCluster:   cluster,
+	}}
+
+	slices, err := clientSet.DiscoveryV1beta1().EndpointSlices(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(&selector)})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range slices.Items {
+		for j := range slices.Items[i].Ports {
+			port := &slices.Items[i].Ports[j]
+			if port.Port == nil || !isProbedProtocol(port.Protocol) {
+				continue
+			}
+
+			targetPort := *port.Port
+			if connectivityPort != 0 {
+				targetPort = int32(connectivityPort)
+			}
+
+			for k := range slices.Items[i].Endpoints {
+				if len(slices.Items[i].Endpoints[k].Addresses) > 0 {
+					return &connectivityTarget{service: service, cluster: cluster,
+						ip: slices.Items[i].Endpoints[k].Addresses[0], port: targetPort}, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// firstProbedPort returns the port to probe on the service: the requested one, or the first port using the probed
+// protocol. With ICMP, any port will do.
+func firstProbedPort(ports []mcsv1a1.ServicePort) (int32, bool) {
+	for i := range ports {
+		if isProbedProtocol(&ports[i].Protocol) {
+			if connectivityPort != 0 {
+				return int32(connectivityPort), true
+			}
+
+			return ports[i].Port, true
+		}
+	}
+
+	return 0, false
+}
+
+// isProbedProtocol returns true if the probe can be sent to a port using the given protocol, TCP by default
+func isProbedProtocol(protocol *v1.Protocol) bool {
+	portProtocol := v1.ProtocolTCP
+	if protocol != nil && *protocol != "" {
+		portProtocol = *protocol
+	}
+
+	switch connectivityProtocol {
+	case tcpProtocol:
+		return portProtocol == v1.ProtocolTCP
+	case udpProtocol:
+		return portProtocol == v1.ProtocolUDP
+	default:
+		return true
+	}
+}
+
+// runConnectivityClientPod connects to each target from a pod on a non-Gateway node, and returns the addresses of
+// the reachable targets
+func runConnectivityClientPod(clientSet *kubernetes.Clientset, targets []connectivityTarget) (stringset.Interface, error) {
+	commands := []string{}
+	for i := range targets {
+		probe := fmt.Sprintf(connectivityProbes[connectivityProtocol], connectivityCheckTimeout, targets[i].ip, targets[i].port)
+		commands = append(commands, fmt.Sprintf("%s && echo 'OK %s'", probe, targets[i].address()))
+	}
+
+	cPod, err := spawnClientPodOnNonGatewayNode(clientSet, namespace, strings.Join(commands, "; ")+"; true")
+	if err != nil {
+		return nil, fmt.Errorf("error while spawning the client pod on a non-Gateway node: %v", err)
+	}
+
+	defer cPod.DeletePod()
+
+	if err = cPod.AwaitPodCompletion(); err != nil {
+		return nil, fmt.Errorf("error while waiting for the client pod to finish its execution: %v", err)
+	}
+
+	reachable := stringset.New()
+
+	for _, line := range strings.Split(cPod.PodOutput, "\n") {
+		if address := strings.TrimPrefix(strings.TrimSpace(line), "OK "); address != strings.TrimSpace(line) {
+			reachable.Add(address)
+		}
+	}
+
+	return reachable, nil
+}
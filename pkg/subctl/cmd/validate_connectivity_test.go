@@ -0,0 +1,110 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// saveConnectivityFlags returns a function restoring the current connectivity flags
+func saveConnectivityFlags() func() {
+	previousProtocol, previousServiceOnly := connectivityProtocol, serviceCIDROnly
+
+	return func() {
+		connectivityProtocol, serviceCIDROnly = previousProtocol, previousServiceOnly
+	}
+}
+
+func TestIsValidConnectivityProbe(t *testing.T) {
+	for _, protocol := range []string{tcpProtocol, udpProtocol, icmpProtocol} {
+		if err := isValidConnectivityProbe(protocol, 8080); err != nil {
+			t.Fatalf("Expected %s to be valid, got %s", protocol, err)
+		}
+	}
+
+	if err := isValidConnectivityProbe("sctp", 0); err == nil {
+		t.Fatal("Expected an unknown protocol to be rejected")
+	}
+
+	for _, port := range []int{-1, 65536} {
+		if err := isValidConnectivityProbe(tcpProtocol, port); err == nil {
+			t.Fatalf("Expected port %d to be rejected", port)
+		}
+	}
+}
+
+func TestIsProbedProtocol(t *testing.T) {
+	tcp, udp, unset := v1.ProtocolTCP, v1.ProtocolUDP, v1.Protocol("")
+
+	tests := []struct {
+		protocol string
+		port     *v1.Protocol
+		probed   bool
+	}{
+		{protocol: tcpProtocol, port: &tcp, probed: true},
+		{protocol: tcpProtocol, port: nil, probed: true},
+		{protocol: tcpProtocol, port: &unset, probed: true},
+		{protocol: tcpProtocol, port: &udp, probed: false},
+		{protocol: udpProtocol, port: &udp, probed: true},
+		{protocol: udpProtocol, port: nil, probed: false},
+		{protocol: icmpProtocol, port: &udp, probed: true},
+		{protocol: icmpProtocol, port: &tcp, probed: true},
+	}
+
+	defer saveConnectivityFlags()()
+
+	for _, test := range tests {
+		connectivityProtocol = test.protocol
+
+		if probed := isProbedProtocol(test.port); probed != test.probed {
+			t.Fatalf("Expected isProbedProtocol(%v) to be %t with %s, got %t", test.port, test.probed, test.protocol, probed)
+		}
+	}
+}
+
+func TestConnectivityTargetKinds(t *testing.T) {
+	tests := []struct {
+		protocol    string
+		serviceOnly bool
+		globalnet   bool
+		serviceIPs  bool
+		podIPs      bool
+	}{
+		{protocol: tcpProtocol, serviceIPs: true, podIPs: true},
+		{protocol: tcpProtocol, serviceOnly: true, serviceIPs: true},
+		{protocol: tcpProtocol, globalnet: true, serviceIPs: true},
+		{protocol: icmpProtocol, podIPs: true},
+		{protocol: icmpProtocol, serviceOnly: true},
+		{protocol: icmpProtocol, globalnet: true, serviceIPs: true},
+	}
+
+	defer saveConnectivityFlags()()
+
+	for _, test := range tests {
+		connectivityProtocol, serviceCIDROnly = test.protocol, test.serviceOnly
+
+		serviceIPs, podIPs := connectivityTargetKinds(test.globalnet)
+		if serviceIPs != test.serviceIPs || podIPs != test.podIPs {
+			t.Fatalf("Expected service IPs %t and pod IPs %t for %+v, got %t and %t", test.serviceIPs, test.podIPs, test,
+				serviceIPs, podIPs)
+		}
+	}
+}
@@ -20,60 +20,239 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
 	"github.com/submariner-io/submariner/pkg/cidr"
 	smClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/utils"
 )
 
 var validatePodsCmd = &cobra.Command{
 	Use:   "deployment",
 	Short: "Check the Submariner deployment",
-	Long:  "This command checks that the Submariner components are properly deployed and running with no overlapping CIDRs.",
-	Run:   validateSubmarinerDeployment,
+	Long: "This command checks that the Submariner components are properly deployed and running, with no overlapping" +
+		" CIDRs, a single active gateway, a running operator leader, matching gateway and route agent versions and," +
+		" when the operator is installed by OLM, a successfully installed ClusterServiceVersion. With" +
+		" --include-node-cidrs, the node networks of each cluster are also checked against the node networks and CIDRs" +
+		" of the other clusters.",
+	Run: validateDeployment,
+}
+
+const (
+	gatewayComponentName    = "gateway"
+	routeAgentComponentName = "routeagent"
+	globalnetComponentName  = "globalnet"
+	lighthouseComponentName = "lighthouse"
+)
+
+// Pods which have been terminating for longer than this are reported as stuck
+const podTerminatingThreshold = 5 * time.Minute
+
+const (
+	// The ClusterGlobalEgressIP created by globalnet for the cluster-wide egress
+	clusterGlobalEgressIPName = "cluster-egress.submariner.io"
+	// Global IP requests which haven't been processed within this period are reported
+	globalnetAllocationGracePeriod = 2 * time.Minute
+)
+
+// diagnoseComponentCheck describes the workloads making up a Submariner component and how to check them
+type diagnoseComponentCheck struct {
+	workloads []string
+	enabled   func(submariner *v1alpha1.Submariner) bool
+	check     func(clients *clusterClients, namespace string) bool
+}
+
+var (
+	diagnoseComponent   string
+	requirePodResources bool
+)
+
+var diagnoseComponentNames = []string{gatewayComponentName, routeAgentComponentName, lighthouseComponentName,
+	globalnetComponentName}
+
+var diagnoseComponents = map[string]diagnoseComponentCheck{
+	gatewayComponentName: {
+		workloads: []string{"submariner-gateway"},
+		enabled:   alwaysEnabled,
+		check:     checkGatewayComponent,
+	},
+	routeAgentComponentName: {
+		workloads: []string{"submariner-routeagent"},
+		enabled:   alwaysEnabled,
+		check:     checkRouteAgentComponent,
+	},
+	globalnetComponentName: {
+		workloads: []string{"submariner-globalnet"},
+		enabled: func(submariner *v1alpha1.Submariner) bool {
+			return submariner.Spec.GlobalCIDR != ""
+		},
+		check: checkGlobalnetComponent,
+	},
+	lighthouseComponentName: {
+		workloads: []string{"submariner-lighthouse-agent", "submariner-lighthouse-coredns"},
+		enabled: func(submariner *v1alpha1.Submariner) bool {
+			return submariner.Spec.ServiceDiscoveryEnabled
+		},
+		check: checkLighthouseComponent,
+	},
 }
 
 func init() {
+	validatePodsCmd.Flags().StringVar(&cidrOverlapsDOTFile, "dot-file", "",
+		"write the clusters and their overlapping CIDRs as a Graphviz DOT graph to the given file, suffixed with the name of"+
+			" each diagnosed cluster")
+	validatePodsCmd.Flags().StringVar(&diagnoseComponent, "component", "",
+		fmt.Sprintf("only check the given component - any of %s", strings.Join(diagnoseComponentNames, ",")))
+	validatePodsCmd.Flags().BoolVar(&requirePodResources, "require-resources", false,
+		"fail if any container of the component pods lacks CPU or memory requests or limits")
+	addFailedComponentLogsFlag(validatePodsCmd)
+	addNodeCIDRsFlag(validatePodsCmd)
+	addFailedComponentEventsFlag(validatePodsCmd)
 	validateCmd.AddCommand(validatePodsCmd)
 }
 
-func validateSubmarinerDeployment(cmd *cobra.Command, args []string) {
+// CheckResult records the outcome of a single diagnostic check and how long it took
+type CheckResult struct {
+	Name     string
+	Passed   bool
+	Duration time.Duration
+}
+
+// ClusterValidationResult records the outcome of the deployment checks run in a cluster.
+// Skipped is set when Submariner isn't installed in the cluster, in which case no checks are run.
+type ClusterValidationResult struct {
+	ClusterName string
+	Skipped     bool
+	Checks      []CheckResult
+}
+
+// Passed returns true if none of the checks run in the cluster failed
+func (r *ClusterValidationResult) Passed() bool {
+	for _, check := range r.Checks {
+		if !check.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateDeployment(cmd *cobra.Command, args []string) {
+	err := isValidDiagnoseComponent(diagnoseComponent)
+	exitOnError("Invalid component parameter", err)
+
 	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
 	exitOnError("Error getting REST config for cluster", err)
 
-	validationStatus := true
+	if watchDeployment {
+		finishValidation(watchSubmarinerDeployment(configs))
+		return
+	}
+
+	validationStatus := validationResultsPassed(validateSubmarinerDeployment(configs, newClusterClients))
+
+	if includeNodeCIDRs && len(configs) > 1 {
+		validationStatus = validateNodeCIDROverlapsAcrossClusters(configs) && validationStatus
+	}
+
+	finishValidation(validationStatus)
+}
+
+// validateSubmarinerDeployment runs the deployment checks in the given clusters, using the clients created by
+// newClients
+func validateSubmarinerDeployment(configs []restConfig, newClients clusterClientsFactory) []ClusterValidationResult {
+	results := make([]ClusterValidationResult, 0, len(configs))
+	broker := findBrokerMember(configs, newClients)
 
 	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		result := ClusterValidationResult{ClusterName: item.clusterName}
+
+		clients := newCheckClients(item.clusterName, item.config, newClients)
+		if clients == nil {
+			result.Checks = append(result.Checks, CheckResult{Name: "clients"})
+			results = append(results, result)
+			continue
+		}
+
 		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
-		submariner := getSubmarinerResource(item.config)
+		submariner, err := submarinerResourceOrError(getSubmarinerResourceWithClient(clients.operatorClient))
 		if submariner == nil {
-			status.QueueWarningMessage(submMissingMessage)
-			status.End(cli.Success)
+			if !reportUnavailableSubmariner(err) {
+				name := "installed"
+				if err != nil {
+					name = "reachable"
+				}
+
+				result.Checks = append(result.Checks, CheckResult{Name: name})
+			}
+
+			result.Skipped = true
+			results = append(results, result)
 			continue
 		}
 
 		status.End(cli.Success)
 
-		validationStatus = validationStatus && checkPods(item, submariner, OperatorNamespace)
-		validationStatus = validationStatus && checkOverlappingCIDRs(item, submariner)
+		result.Checks = append(result.Checks,
+			runCheck("pods", func() bool {
+				return checkPods(item.clusterName, clients, submariner, OperatorNamespace)
+			}),
+			runCheck("overlapping-cidrs", func() bool {
+				return checkOverlappingCIDRs(clients, submariner, broker)
+			}),
+			runCheck("active-gateways", func() bool {
+				return checkActiveGateways(item.clusterName, clients, submariner)
+			}),
+			runCheck("operator-leader", func() bool {
+				return checkOperatorLeader(item.clusterName, clients, OperatorNamespace)
+			}),
+			runCheck("operator-csv", func() bool {
+				return checkOperatorCSV(item.clusterName, clients, OperatorNamespace)
+			}),
+			runCheck("component-versions", func() bool {
+				return checkComponentVersions(item.clusterName, clients, OperatorNamespace)
+			}))
+		results = append(results, result)
 	}
 
-	if !validationStatus {
-		os.Exit(1)
+	status.SetCluster("")
+
+	return results
+}
+
+// validationResultsPassed returns true if none of the checks run in the clusters failed
+func validationResultsPassed(results []ClusterValidationResult) bool {
+	for i := range results {
+		if !results[i].Passed() {
+			return false
+		}
 	}
+
+	return true
 }
 
-func checkOverlappingCIDRs(item restConfig, submariner *v1alpha1.Submariner) bool {
-	submarinerClient, err := smClientset.NewForConfig(item.config)
-	exitOnError("Unable to get the Submariner client", err)
+func runCheck(name string, check func() bool) CheckResult {
+	start := time.Now()
+	passed := check()
+
+	return CheckResult{Name: name, Passed: passed, Duration: time.Since(start)}
+}
 
+// checkOverlappingCIDRs checks that the CIDRs advertised by the clusters don't overlap; when the broker cluster is
+// also a member, its CIDRs are included even if it has no Endpoint
+func checkOverlappingCIDRs(clients *clusterClients, submariner *v1alpha1.Submariner, broker *brokerMember) bool {
 	if submariner.Spec.GlobalCIDR != "" {
 		status.Start("Globalnet deployment detected, checking if globalnet CIDRs overlap")
 	} else {
@@ -81,23 +260,27 @@ func checkOverlappingCIDRs(item restConfig, submariner *v1alpha1.Submariner) boo
 	}
 
 	localClusterName := submariner.Status.ClusterID
-	endpointList, err := submarinerClient.SubmarinerV1().Endpoints(submariner.Namespace).List(context.TODO(), metav1.ListOptions{})
+	endpointList, err := clients.submarinerClient.SubmarinerV1().Endpoints(submariner.Namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		message := fmt.Sprintf("Error listing the Submariner endpoints in cluster %q", localClusterName)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeAPIListFailed, message)
 		status.End(cli.Failure)
 		return false
 	}
 
 	var message string
-	for i, source := range endpointList.Items {
-		for _, dest := range endpointList.Items[i+1:] {
+	var overlaps []cidrOverlap
+
+	endpoints := withBrokerMemberEndpoint(endpointList.Items, broker)
+
+	for i, source := range endpoints {
+		for _, dest := range endpoints[i+1:] {
 			// Currently we dont support multiple endpoints in a cluster, hence return an error.
 			// When the corresponding support is added, this check needs to be updated.
 			if source.Spec.ClusterID == dest.Spec.ClusterID {
 				message = fmt.Sprintf("Found multiple Submariner endpoints (%q and %q) in cluster %q",
 					source.Name, dest.Name, source.Spec.ClusterID)
-				status.QueueFailureMessage(message)
+				status.QueueFailureMessageWithCode(codeMultipleClusterEndpoints, message)
 				continue
 			}
 
@@ -106,19 +289,33 @@ func checkOverlappingCIDRs(item restConfig, submariner *v1alpha1.Submariner) boo
 				if err != nil {
 					// Ideally this case will never hit, as the subnets are valid CIDRs
 					message = fmt.Sprintf("Error parsing CIDR in cluster %q: %s", dest.Spec.ClusterID, err)
-					status.QueueFailureMessage(message)
+					status.QueueFailureMessageWithCode(codeCIDRInvalid, message)
 					continue
 				}
 
 				if overlap {
-					message = fmt.Sprintf("CIDR %q in cluster %q overlaps with cluster %q (CIDRs: %v)",
-						subnet, dest.Spec.ClusterID, source.Spec.ClusterID, source.Spec.Subnets)
-					status.QueueFailureMessage(message)
+					overlaps = append(overlaps, cidrOverlap{
+						clusterID:      dest.Spec.ClusterID,
+						cidr:           subnet,
+						otherClusterID: source.Spec.ClusterID,
+						otherCIDRs:     source.Spec.Subnets,
+					})
+					message = fmt.Sprintf("CIDR %q in cluster %s overlaps with cluster %s (CIDRs: %v)",
+						subnet, describeCluster(dest.Spec.ClusterID, broker), describeCluster(source.Spec.ClusterID, broker),
+						source.Spec.Subnets)
+					status.QueueFailureMessageWithCode(codeClusterCIDROverlap, message)
 				}
 			}
 		}
 	}
 
+	if cidrOverlapsDOTFile != "" {
+		path := clusterDOTFile(cidrOverlapsDOTFile, localClusterName)
+		if err := writeCIDROverlapsDOT(path, endpoints, overlaps); err != nil {
+			status.QueueFailureMessageWithCode(codeReportFailed, fmt.Sprintf("Error writing the CIDR overlaps graph to %q: %s", path, err))
+		}
+	}
+
 	if status.HasFailureMessages() {
 		status.End(cli.Failure)
 		return false
@@ -134,44 +331,91 @@ func checkOverlappingCIDRs(item restConfig, submariner *v1alpha1.Submariner) boo
 	return true
 }
 
-func checkPods(item restConfig, submariner *v1alpha1.Submariner, operatorNamespace string) bool {
-	message := fmt.Sprintf("Checking Submariner pods in %q", item.clusterName)
-	status.Start(message)
-
-	kubeClientSet, err := kubernetes.NewForConfig(item.config)
+// checkActiveGateways checks that at most one gateway reports itself as active in the cluster; several active
+// gateways (a split brain) cause the routes to flap between them
+func checkActiveGateways(clusterName string, clients *clusterClients, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking that a single gateway is active in %q", clusterName))
 
+	gateways, err := clients.submarinerClient.SubmarinerV1().Gateways(submariner.Namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		exitOnError("Error creating Kubernetes client", err)
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Gateways: %s", err))
+		status.End(cli.Failure)
+		return false
 	}
 
-	if !CheckDaemonset(kubeClientSet, operatorNamespace, "submariner-gateway") {
-		return false
+	activeNodes := []string{}
+
+	for i := range gateways.Items {
+		if gateways.Items[i].Status.HAStatus == subv1.HAStatusActive {
+			activeNodes = append(activeNodes, gateways.Items[i].Name)
+		}
 	}
 
-	if !CheckDaemonset(kubeClientSet, operatorNamespace, "submariner-routeagent") {
+	sort.Strings(activeNodes)
+
+	switch len(activeNodes) {
+	case 0:
+		status.QueueWarningMessageWithCode(codeNoGateway, "No gateway is active")
+		status.End(cli.Warning)
+	case 1:
+		status.QueueSuccessMessage(fmt.Sprintf("The gateway on node %q is the only active gateway", activeNodes[0]))
+		status.End(cli.Success)
+	default:
+		status.QueueFailureMessageWithCode(codeMultipleActiveGateways,
+			fmt.Sprintf("Found %d active gateways, on nodes %s; routing will flap between them",
+				len(activeNodes), strings.Join(activeNodes, ", ")))
+		status.End(cli.Failure)
+
 		return false
 	}
 
-	// Check if service-discovery components are deployed and running if enabled
-	if submariner.Spec.ServiceDiscoveryEnabled {
-		// Check lighthouse-agent
-		if !CheckDeployment(kubeClientSet, operatorNamespace, "submariner-lighthouse-agent") {
-			return false
+	return true
+}
+
+func checkPods(clusterName string, clients *clusterClients, submariner *v1alpha1.Submariner, operatorNamespace string) bool {
+	message := fmt.Sprintf("Checking Submariner pods in %q", clusterName)
+	status.Start(message)
+
+	selectedComponents := diagnoseComponentNames
+	if diagnoseComponent != "" {
+		selectedComponents = []string{diagnoseComponent}
+	}
+
+	var workloads []string
+
+	for _, name := range selectedComponents {
+		component := diagnoseComponents[name]
+		if !component.enabled(submariner) {
+			if diagnoseComponent != "" {
+				status.QueueWarningMessageWithCode(codeComponentDisabled,
+					fmt.Sprintf("The %s component is not enabled in %q", name, clusterName))
+				status.End(cli.Warning)
+				return true
+			}
+
+			continue
 		}
 
-		// Check lighthouse-coreDNS
-		if !CheckDeployment(kubeClientSet, operatorNamespace, "submariner-lighthouse-coredns") {
+		if !component.check(clients, operatorNamespace) {
+			printWorkloadLogs(clients.kubeClient, operatorNamespace, component.workloads)
+			printWorkloadEvents(clients.kubeClient, operatorNamespace, component.workloads)
 			return false
 		}
+
+		workloads = append(workloads, component.workloads...)
 	}
-	// Check if globalnet components are deployed and running if enabled
-	if submariner.Spec.GlobalCIDR != "" {
-		if !CheckDaemonset(kubeClientSet, operatorNamespace, "submariner-globalnet") {
-			return false
-		}
+
+	// When a single component is requested, only its own pods are checked
+	podSelector := ""
+	if diagnoseComponent != "" {
+		podSelector = fmt.Sprintf("app in (%s)", strings.Join(workloads, ","))
 	}
 
-	if !checkPodsStatus(kubeClientSet, operatorNamespace) {
+	if !checkPodsStatus(clients.kubeClient, operatorNamespace, podSelector) {
+		return false
+	}
+
+	if requirePodResources && !checkPodsResources(clients.kubeClient, operatorNamespace, workloads) {
 		return false
 	}
 
@@ -181,25 +425,35 @@ func checkPods(item restConfig, submariner *v1alpha1.Submariner, operatorNamespa
 	return true
 }
 
-func CheckDeployment(k8sClient kubernetes.Interface, namespace, deploymentName string) bool {
-	deployment, err := k8sClient.AppsV1().Deployments(namespace).Get(context.TODO(), deploymentName, metav1.GetOptions{})
+// checkPodsResources checks that every container of the pods of the given workloads sets CPU and memory requests and
+// limits, reporting each missing one
+func checkPodsResources(k8sClient kubernetes.Interface, operatorNamespace string, workloads []string) bool {
+	if len(workloads) == 0 {
+		return true
+	}
+
+	pods, err := k8sClient.CoreV1().Pods(operatorNamespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: fmt.Sprintf("app in (%s)", strings.Join(workloads, ","))})
 	if err != nil {
-		message := fmt.Sprintf("Error obtaining Deployment %q: %v", deploymentName, err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining Pods list: %v", err))
 		status.End(cli.Failure)
 		return false
 	}
 
-	var replicas int32 = 1
-	if deployment.Spec.Replicas != nil {
-		replicas = *deployment.Spec.Replicas
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		for j := range pod.Spec.Containers {
+			container := &pod.Spec.Containers[j]
+
+			for _, missing := range missingContainerResources(container) {
+				status.QueueFailureMessageWithCode(codeContainerResourcesMissing,
+					fmt.Sprintf("Container %q of pod %q doesn't set %s", container.Name, pod.Name, missing))
+			}
+		}
 	}
 
-	if deployment.Status.AvailableReplicas != replicas {
-		message := fmt.Sprintf("The desired number of replicas for Deployment %q (%d)"+
-			" does not match the actual number running (%d)", deploymentName, replicas,
-			deployment.Status.AvailableReplicas)
-		status.QueueFailureMessage(message)
+	if status.HasFailureMessages() {
 		status.End(cli.Failure)
 		return false
 	}
@@ -207,20 +461,103 @@ func CheckDeployment(k8sClient kubernetes.Interface, namespace, deploymentName s
 	return true
 }
 
-func CheckDaemonset(k8sClient kubernetes.Interface, namespace, daemonSetName string) bool {
-	daemonSet, err := k8sClient.AppsV1().DaemonSets(namespace).Get(context.TODO(), daemonSetName, metav1.GetOptions{})
+// missingContainerResources returns the CPU and memory requests and limits which the container doesn't set
+func missingContainerResources(container *v1.Container) []string {
+	missing := []string{}
+
+	for _, resourceName := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		if _, ok := container.Resources.Requests[resourceName]; !ok {
+			missing = append(missing, "resources.requests."+string(resourceName))
+		}
+
+		if _, ok := container.Resources.Limits[resourceName]; !ok {
+			missing = append(missing, "resources.limits."+string(resourceName))
+		}
+	}
+
+	return missing
+}
+
+func checkGatewayComponent(clients *clusterClients, namespace string) bool {
+	return CheckDaemonset(clients.kubeClient, namespace, "submariner-gateway", utils.RolloutWait{})
+}
+
+func checkRouteAgentComponent(clients *clusterClients, namespace string) bool {
+	return CheckDaemonset(clients.kubeClient, namespace, "submariner-routeagent", utils.RolloutWait{})
+}
+
+func checkGlobalnetComponent(clients *clusterClients, namespace string) bool {
+	if !CheckDaemonset(clients.kubeClient, namespace, "submariner-globalnet", utils.RolloutWait{}) {
+		return false
+	}
+
+	return checkGlobalnetAllocations(clients.submarinerClient)
+}
+
+func checkLighthouseComponent(clients *clusterClients, namespace string) bool {
+	// Check lighthouse-agent
+	if !CheckDeployment(clients.kubeClient, namespace, "submariner-lighthouse-agent", utils.RolloutWait{}) {
+		return false
+	}
+
+	// Check lighthouse-coreDNS
+	return CheckDeployment(clients.kubeClient, namespace, "submariner-lighthouse-coredns", utils.RolloutWait{})
+}
+
+// checkGlobalnetAllocations checks that the globalnet controllers are processing the global IP requests, which
+// catches a globalnet which is running but wedged
+func checkGlobalnetAllocations(submarinerClient smClientset.Interface) bool {
+	clusterEgressIP, err := submarinerClient.SubmarinerV1().ClusterGlobalEgressIPs(metav1.NamespaceAll).Get(context.TODO(),
+		clusterGlobalEgressIPName, metav1.GetOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the ClusterGlobalEgressIP"+
+			" %q: %v", clusterGlobalEgressIPName, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if !meta.IsStatusConditionTrue(clusterEgressIP.Status.Conditions, string(subv1.GlobalEgressIPAllocated)) ||
+		len(clusterEgressIP.Status.AllocatedIPs) == 0 {
+		status.QueueFailureMessageWithCode(codeGlobalIPsUnallocated,
+			fmt.Sprintf("Globalnet is running but hasn't allocated the global IPs of the"+
+				" ClusterGlobalEgressIP %q", clusterGlobalEgressIPName))
+	}
+
+	egressIPs, err := submarinerClient.SubmarinerV1().GlobalEgressIPs(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the GlobalEgressIPs: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	for i := range egressIPs.Items {
+		egressIP := &egressIPs.Items[i]
+		if time.Since(egressIP.CreationTimestamp.Time) > globalnetAllocationGracePeriod &&
+			len(egressIP.Status.Conditions) == 0 {
+			status.QueueFailureMessageWithCode(codeGlobalIPsUnallocated,
+				fmt.Sprintf("Globalnet hasn't processed the GlobalEgressIP %q in namespace %q,"+
+					" created %v ago", egressIP.Name, egressIP.Namespace, time.Since(egressIP.CreationTimestamp.Time).Round(time.Second)))
+		}
+	}
+
+	ingressIPs, err := submarinerClient.SubmarinerV1().GlobalIngressIPs(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		message := fmt.Sprintf("Error obtaining Daemonset %q: %v", daemonSetName, err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the GlobalIngressIPs: %v", err))
 		status.End(cli.Failure)
 		return false
 	}
 
-	if daemonSet.Status.CurrentNumberScheduled != daemonSet.Status.DesiredNumberScheduled {
-		message := fmt.Sprintf("The desired number of running pods for DaemonSet %q (%d)"+
-			" does not match the actual number (%d)", daemonSetName, daemonSet.Status.DesiredNumberScheduled,
-			daemonSet.Status.CurrentNumberScheduled)
-		status.QueueFailureMessage(message)
+	for i := range ingressIPs.Items {
+		ingressIP := &ingressIPs.Items[i]
+		if time.Since(ingressIP.CreationTimestamp.Time) > globalnetAllocationGracePeriod && ingressIP.Status.AllocatedIP == "" {
+			status.QueueFailureMessageWithCode(codeGlobalIPsUnallocated,
+				fmt.Sprintf("Globalnet hasn't allocated a global IP for the GlobalIngressIP %q in"+
+					" namespace %q, created %v ago", ingressIP.Name, ingressIP.Namespace,
+					time.Since(ingressIP.CreationTimestamp.Time).Round(time.Second)))
+		}
+	}
+
+	if status.HasFailureMessages() {
 		status.End(cli.Failure)
 		return false
 	}
@@ -228,30 +565,116 @@ func CheckDaemonset(k8sClient kubernetes.Interface, namespace, daemonSetName str
 	return true
 }
 
-func checkPodsStatus(k8sClient kubernetes.Interface, operatorNamespace string) bool {
-	pods, err := k8sClient.CoreV1().Pods(operatorNamespace).List(context.TODO(), metav1.ListOptions{})
+func alwaysEnabled(submariner *v1alpha1.Submariner) bool {
+	return true
+}
+
+func isValidDiagnoseComponent(component string) error {
+	if component == "" {
+		return nil
+	}
+
+	if _, found := diagnoseComponents[component]; !found {
+		return fmt.Errorf("unknown component %q, must be one of %s", component, strings.Join(diagnoseComponentNames, ","))
+	}
+
+	return nil
+}
+
+// CheckDeployment checks that all the replicas of the Deployment are available, waiting for them as configured by
+// rolloutWait
+func CheckDeployment(k8sClient kubernetes.Interface, namespace, deploymentName string, rolloutWait utils.RolloutWait) bool {
+	if err := utils.CheckDeploymentRollout(context.TODO(), k8sClient, namespace, deploymentName, rolloutWait); err != nil {
+		status.QueueFailureMessageWithCode(codeComponentNotReady, fmt.Sprintf("Deployment %q isn't ready: %v", deploymentName, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	return true
+}
+
+// CheckDaemonset checks that the DaemonSet's pods are scheduled on all the desired nodes, waiting for them as
+// configured by rolloutWait
+func CheckDaemonset(k8sClient kubernetes.Interface, namespace, daemonSetName string, rolloutWait utils.RolloutWait) bool {
+	if err := utils.CheckDaemonSetRollout(context.TODO(), k8sClient, namespace, daemonSetName, rolloutWait); err != nil {
+		status.QueueFailureMessageWithCode(codeComponentNotReady, fmt.Sprintf("DaemonSet %q isn't ready: %v", daemonSetName, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	return true
+}
+
+func checkPodsStatus(k8sClient kubernetes.Interface, operatorNamespace, podSelector string) bool {
+	pods, err := k8sClient.CoreV1().Pods(operatorNamespace).List(context.TODO(), metav1.ListOptions{LabelSelector: podSelector})
 	if err != nil {
 		message := fmt.Sprintf("Error obtaining Pods list: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeComponentNotReady, message)
 		status.End(cli.Failure)
 		return false
 	}
 
-	for _, pod := range pods.Items {
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+
+		// A pod stuck terminating keeps its Running phase, usually because its node or kubelet is unresponsive
+		if pod.DeletionTimestamp != nil {
+			terminating := time.Since(pod.DeletionTimestamp.Time)
+			if terminating > podTerminatingThreshold {
+				status.QueueWarningMessageWithCode(codeComponentNotReady, fmt.Sprintf("Pod %q has been terminating for %v", pod.Name,
+					terminating.Round(time.Second)))
+			}
+		}
+
 		if pod.Status.Phase != v1.PodRunning {
 			message := fmt.Sprintf("Pod %q is not running. (current state is %v)", pod.Name, pod.Status.Phase)
-			status.QueueFailureMessage(message)
+			status.QueueFailureMessageWithCode(codeComponentNotReady, message)
 			status.End(cli.Failure)
+			printPodLogs(k8sClient, pod)
+			printPodEvents(k8sClient, pod)
 			return false
 		}
 
 		for _, c := range pod.Status.ContainerStatuses {
 			if c.RestartCount >= 5 {
 				message := fmt.Sprintf("Pod %q has restarted %d times", pod.Name, c.RestartCount)
-				status.QueueWarningMessage(message)
+				status.QueueWarningMessageWithCode(codeComponentRestarting, message)
 			}
 		}
+
+		checkContainersOOMKilled(pod)
 	}
 
 	return true
 }
+
+// checkContainersOOMKilled reports the containers of the pod which were killed for exceeding their memory limit, or
+// because their node ran out of memory, since raising the limit rather than investigating the restarts fixes them
+func checkContainersOOMKilled(pod *v1.Pod) {
+	for i := range pod.Status.ContainerStatuses {
+		containerStatus := &pod.Status.ContainerStatuses[i]
+
+		terminated := containerStatus.LastTerminationState.Terminated
+		if containerStatus.State.Terminated != nil {
+			terminated = containerStatus.State.Terminated
+		}
+
+		if terminated == nil || terminated.Reason != "OOMKilled" {
+			continue
+		}
+
+		limit := "no memory limit, so its node ran out of memory"
+		for j := range pod.Spec.Containers {
+			if pod.Spec.Containers[j].Name != containerStatus.Name {
+				continue
+			}
+
+			if memory, found := pod.Spec.Containers[j].Resources.Limits[v1.ResourceMemory]; found {
+				limit = fmt.Sprintf("a memory limit of %s; consider raising it", memory.String())
+			}
+		}
+
+		status.QueueWarningMessageWithCode(codeContainerOOMKilled, fmt.Sprintf("Container %q of pod %q was OOMKilled"+
+			" at %s; it has %s", containerStatus.Name, pod.Name, terminated.FinishedAt.Format(time.RFC3339), limit))
+	}
+}
@@ -0,0 +1,182 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	submarinerfake "github.com/submariner-io/submariner/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	operatorfake "github.com/submariner-io/submariner-operator/pkg/client/clientset/versioned/fake"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var deploymentCheckNames = []string{"pods", "overlapping-cidrs", "active-gateways", "operator-leader", "operator-csv",
+	"component-versions"}
+
+func newTestEndpoint(clusterID string, subnets ...string) *subv1.Endpoint {
+	return &subv1.Endpoint{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterID + "-endpoint", Namespace: submarinerResourceNamespace},
+		Spec:       subv1.EndpointSpec{ClusterID: clusterID, Subnets: subnets},
+	}
+}
+
+// newTestClusterClients returns fake clients of a cluster with the given Submariner resource, if any, and Endpoints
+func newTestClusterClients(submariner runtime.Object, endpoints ...runtime.Object) *clusterClients {
+	operatorClient := operatorfake.NewSimpleClientset()
+	if submariner != nil {
+		operatorClient = operatorfake.NewSimpleClientset(submariner)
+	}
+
+	return &clusterClients{
+		kubeClient:       fake.NewSimpleClientset(),
+		submarinerClient: submarinerfake.NewSimpleClientset(endpoints...),
+		operatorClient:   operatorClient,
+		dynamicClient:    dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+	}
+}
+
+// runDeploymentChecks runs the deployment checks in the given clusters, with a status discarding their output
+func runDeploymentChecks(configs []restConfig, newClients clusterClientsFactory) []ClusterValidationResult {
+	previous := status
+	defer func() { status = previous }()
+
+	status = cli.NewStatusForWriter(&bytes.Buffer{})
+
+	return validateSubmarinerDeployment(configs, newClients)
+}
+
+func findCheckResult(result *ClusterValidationResult, name string) *CheckResult {
+	for i := range result.Checks {
+		if result.Checks[i].Name == name {
+			return &result.Checks[i]
+		}
+	}
+
+	return nil
+}
+
+func TestValidateSubmarinerDeploymentRunsChecks(t *testing.T) {
+	configs := []restConfig{{config: &rest.Config{Host: "https://east.example.com:6443"}, clusterName: "east"}}
+	newClients := fakeClusterClients(map[string]*clusterClients{
+		"https://east.example.com:6443": newTestClusterClients(
+			newTestSubmariner("east", "broker.example.com:6443", "10.96.0.0/16", "10.244.0.0/16"),
+			newTestEndpoint("east", "10.96.0.0/16", "10.244.0.0/16"),
+			newTestEndpoint("west", "100.96.0.0/16", "100.244.0.0/16")),
+	})
+
+	results := runDeploymentChecks(configs, newClients)
+	if len(results) != 1 {
+		t.Fatalf("Expected the results of one cluster, got %d", len(results))
+	}
+
+	if results[0].ClusterName != "east" || results[0].Skipped {
+		t.Fatalf("Expected the checks to run in cluster \"east\", got %+v", results[0])
+	}
+
+	for _, name := range deploymentCheckNames {
+		if findCheckResult(&results[0], name) == nil {
+			t.Errorf("The %q check wasn't run", name)
+		}
+	}
+
+	if check := findCheckResult(&results[0], "overlapping-cidrs"); check != nil && !check.Passed {
+		t.Errorf("The overlapping-cidrs check failed with non-overlapping CIDRs")
+	}
+}
+
+func TestValidateSubmarinerDeploymentDetectsOverlappingCIDRs(t *testing.T) {
+	configs := []restConfig{{config: &rest.Config{Host: "https://east.example.com:6443"}, clusterName: "east"}}
+	newClients := fakeClusterClients(map[string]*clusterClients{
+		"https://east.example.com:6443": newTestClusterClients(
+			newTestSubmariner("east", "broker.example.com:6443", "10.96.0.0/16", "10.244.0.0/16"),
+			newTestEndpoint("east", "10.96.0.0/16", "10.244.0.0/16"),
+			newTestEndpoint("west", "10.96.0.0/12", "100.244.0.0/16")),
+	})
+
+	results := runDeploymentChecks(configs, newClients)
+
+	check := findCheckResult(&results[0], "overlapping-cidrs")
+	if check == nil || check.Passed {
+		t.Errorf("The overlapping-cidrs check didn't fail with overlapping CIDRs: %+v", check)
+	}
+
+	if results[0].Passed() {
+		t.Errorf("The cluster passed the checks with overlapping CIDRs")
+	}
+}
+
+func TestValidateSubmarinerDeploymentSkipsClustersWithoutSubmariner(t *testing.T) {
+	configs := []restConfig{
+		{config: &rest.Config{Host: "https://east.example.com:6443"}, clusterName: "east"},
+		{config: &rest.Config{Host: "https://west.example.com:6443"}, clusterName: "west"},
+	}
+	newClients := fakeClusterClients(map[string]*clusterClients{
+		"https://east.example.com:6443": newTestClusterClients(nil),
+		"https://west.example.com:6443": newTestClusterClients(
+			newTestSubmariner("west", "broker.example.com:6443", "100.96.0.0/16", "100.244.0.0/16")),
+	})
+
+	results := runDeploymentChecks(configs, newClients)
+	if len(results) != 2 {
+		t.Fatalf("Expected the results of two clusters, got %d", len(results))
+	}
+
+	if !results[0].Skipped || len(results[0].Checks) != 0 {
+		t.Errorf("Expected cluster \"east\" without Submariner to be skipped, got %+v", results[0])
+	}
+
+	if results[1].Skipped || len(results[1].Checks) != len(deploymentCheckNames) {
+		t.Errorf("Expected the checks to run in cluster \"west\", got %+v", results[1])
+	}
+}
+
+func TestValidateSubmarinerDeploymentReportsClientFailures(t *testing.T) {
+	configs := []restConfig{
+		{config: &rest.Config{Host: "https://east.example.com:6443"}, clusterName: "east"},
+		{config: &rest.Config{Host: "https://west.example.com:6443"}, clusterName: "west"},
+	}
+	newClients := fakeClusterClients(map[string]*clusterClients{
+		"https://west.example.com:6443": newTestClusterClients(
+			newTestSubmariner("west", "broker.example.com:6443", "100.96.0.0/16", "100.244.0.0/16")),
+	})
+
+	results := runDeploymentChecks(configs, newClients)
+	if len(results) != 2 {
+		t.Fatalf("Expected the results of two clusters, got %d", len(results))
+	}
+
+	if check := findCheckResult(&results[0], "clients"); check == nil || check.Passed || results[0].Passed() {
+		t.Errorf("Expected cluster \"east\" without clients to fail, got %+v", results[0])
+	}
+
+	if results[1].Skipped || len(results[1].Checks) != len(deploymentCheckNames) {
+		t.Errorf("Expected the checks to run in cluster \"west\", got %+v", results[1])
+	}
+
+	if validationResultsPassed(results) {
+		t.Errorf("Expected the results to fail with a cluster without clients")
+	}
+}
@@ -0,0 +1,110 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var (
+	watchDeployment         bool
+	watchDeploymentInterval time.Duration
+)
+
+func init() {
+	validatePodsCmd.Flags().BoolVar(&watchDeployment, "watch", false,
+		"re-run the checks periodically, reporting the checks which start failing or recover, until interrupted")
+	validatePodsCmd.Flags().DurationVar(&watchDeploymentInterval, "interval", 30*time.Second,
+		"interval between the runs of the checks in watch mode")
+}
+
+// watchSubmarinerDeployment runs the deployment checks at each interval, reporting the transitions from the previous
+// run, until it receives SIGINT or SIGTERM; it returns true if none of the checks failed in the last run
+func watchSubmarinerDeployment(configs []restConfig) bool {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	defer signal.Stop(signals)
+
+	var previous []ClusterValidationResult
+
+	for {
+		current := validateSubmarinerDeployment(configs, newClusterClients)
+
+		if previous != nil {
+			reportValidationTransitions(previous, current)
+		}
+
+		previous = current
+
+		select {
+		case <-signals:
+			status.Start("Stopping the watch")
+			status.End(cli.Success)
+
+			return validationResultsPassed(current)
+		case <-time.After(watchDeploymentInterval):
+			diagnoseSeparator()
+		}
+	}
+}
+
+// reportValidationTransitions reports the checks which passed in the previous run and fail in the current one, and
+// those which recovered
+func reportValidationTransitions(previous, current []ClusterValidationResult) {
+	status.Start(fmt.Sprintf("Comparing with the checks run %v ago", watchDeploymentInterval))
+
+	passed := map[string]bool{}
+	for i := range previous {
+		for _, check := range previous[i].Checks {
+			passed[previous[i].ClusterName+"/"+check.Name] = check.Passed
+		}
+	}
+
+	changes := 0
+
+	for i := range current {
+		for _, check := range current[i].Checks {
+			wasPassing, ran := passed[current[i].ClusterName+"/"+check.Name]
+
+			switch {
+			case !check.Passed && (!ran || wasPassing):
+				status.QueueFailureMessageWithCode(codeCheckRegressed, fmt.Sprintf("The %q check is now failing in cluster %q",
+					check.Name,
+					current[i].ClusterName))
+				changes++
+			case check.Passed && ran && !wasPassing:
+				status.QueueSuccessMessage(fmt.Sprintf("The %q check has recovered in cluster %q", check.Name,
+					current[i].ClusterName))
+				changes++
+			}
+		}
+	}
+
+	if changes == 0 {
+		status.QueueSuccessMessage("No changes")
+	}
+
+	status.End(status.ResultFromMessages())
+}
@@ -0,0 +1,167 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+// nonRoutableRanges are the address ranges which aren't reachable from another network without a VPN or
+// interconnect: the RFC 1918 private ranges and the RFC 6598 shared address space used by carrier-grade NAT
+var nonRoutableRanges = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10"}
+
+var validateEndpointIPsCmd = &cobra.Command{
+	Use:   "endpoint-ips",
+	Short: "Check that the Endpoints advertise IPs their peers can reach",
+	Long: "This command compares the public and private IPs advertised by the local Endpoint with those of the remote" +
+		" Endpoints. Peers in other networks, i.e. with a different public IP, connect to the public IP when NAT" +
+		" traversal is enabled and to the private IP otherwise; peers in the same network, behind the same public" +
+		" IP, connect to the private IP. A warning is raised for each remote Endpoint whose IP used for the" +
+		" connection is missing or, across networks, not routable.",
+	Run: validateEndpointIPs,
+}
+
+func init() {
+	validateCmd.AddCommand(validateEndpointIPsCmd)
+}
+
+func validateEndpointIPs(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateEndpointIPsInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateEndpointIPsInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the IPs advertised by the Endpoints seen from cluster %q", clusterName))
+
+	endpoints, err := listEndpoints(clients.submarinerClient)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Endpoints: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	var local *subv1.Endpoint
+
+	for i := range endpoints {
+		if endpoints[i].Spec.ClusterID == submariner.Spec.ClusterID {
+			local = &endpoints[i]
+			break
+		}
+	}
+
+	if local == nil {
+		status.QueueWarningMessageWithCode(codeLocalEndpointMissing, "No local Endpoint was found")
+		status.End(cli.Warning)
+		return true
+	}
+
+	for i := range endpoints {
+		if endpoints[i].Spec.ClusterID != submariner.Spec.ClusterID {
+			checkEndpointIPs(&local.Spec, &endpoints[i])
+		}
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("The remote Endpoints advertise IPs reachable from the local Endpoint")
+	}
+
+	status.End(result)
+	return true
+}
+
+// checkEndpointIPs warns if the IP the local Endpoint would connect to on the remote Endpoint is missing or, when they
+// are in different networks, not routable
+func checkEndpointIPs(local *subv1.EndpointSpec, remote *subv1.Endpoint) {
+	ips := fmt.Sprintf("public IP %q, private IP %q", remote.Spec.PublicIP, remote.Spec.PrivateIP)
+
+	if local.PublicIP != "" && local.PublicIP == remote.Spec.PublicIP {
+		if remote.Spec.PrivateIP == "" {
+			status.QueueWarningMessageWithCode(codeEndpointPrivateIPAbsent, fmt.Sprintf("The Endpoint %q of cluster %q is"+
+				" in the same network as the local Endpoint but advertises no private IP (%s)", remote.Name, remote.Spec.ClusterID, ips))
+		}
+
+		return
+	}
+
+	if !local.NATEnabled {
+		if isNonRoutableIP(remote.Spec.PrivateIP) && local.PublicIP != "" && remote.Spec.PublicIP != "" {
+			status.QueueWarningMessageWithCode(codeNATTraversalDisabled, fmt.Sprintf("NAT traversal is disabled so the private"+
+				" IP of the Endpoint %q of cluster %q is used, but the clusters appear to be in different networks (local"+
+				" public IP %q; %s); enable NAT traversal unless the networks are interconnected", remote.Name, remote.Spec.ClusterID,
+				local.PublicIP, ips))
+		}
+
+		return
+	}
+
+	if remote.Spec.PublicIP == "" || isNonRoutableIP(remote.Spec.PublicIP) {
+		status.QueueWarningMessageWithCode(codeEndpointPublicIPAbsent, fmt.Sprintf("The Endpoint %q of cluster %q is in"+
+			" another network but only advertises a non-routable IP (%s); set its public IP, e.g. with"+
+			" gateway.submariner.io/public-ip on the gateway node",
+			remote.Name, remote.Spec.ClusterID, ips))
+	}
+}
+
+func isNonRoutableIP(address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range nonRoutableRanges {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
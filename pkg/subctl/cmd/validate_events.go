@@ -0,0 +1,126 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The maximum number of events shown for a failed component
+const maxFailedComponentEvents = 10
+
+var failedComponentEvents bool
+
+func addFailedComponentEventsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&failedComponentEvents, "watch-events", false,
+		fmt.Sprintf("show the last %d Warning events of the pods and workloads of a failed component", maxFailedComponentEvents))
+}
+
+// printWorkloadEvents prints the recent Warning events of the given workloads and their pods, if requested
+func printWorkloadEvents(k8sClient kubernetes.Interface, namespace string, workloads []string) {
+	if !failedComponentEvents {
+		return
+	}
+
+	names := stringset.New(workloads...)
+
+	pods, err := k8sClient.CoreV1().Pods(namespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: fmt.Sprintf("app in (%s)", strings.Join(workloads, ","))})
+	if err != nil {
+		diagnosePrintf("Unable to list the pods to show their events: %s\n", err)
+	} else {
+		for i := range pods.Items {
+			names.Add(pods.Items[i].Name)
+		}
+	}
+
+	printWarningEvents(k8sClient, namespace, names, strings.Join(workloads, ", "))
+}
+
+// printPodEvents prints the recent Warning events of the given pod, if requested
+func printPodEvents(k8sClient kubernetes.Interface, pod *v1.Pod) {
+	if !failedComponentEvents {
+		return
+	}
+
+	printWarningEvents(k8sClient, pod.Namespace, stringset.New(pod.Name), fmt.Sprintf("pod %q", pod.Name))
+}
+
+// printWarningEvents prints the most recent Warning events involving the named objects, up to
+// maxFailedComponentEvents
+func printWarningEvents(k8sClient kubernetes.Interface, namespace string, names stringset.Interface, description string) {
+	events, err := k8sClient.CoreV1().Events(namespace).List(context.TODO(),
+		metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("type", v1.EventTypeWarning).String()})
+	if err != nil {
+		diagnosePrintf("Unable to list the events of %s: %s\n", description, err)
+		return
+	}
+
+	var warnings []*v1.Event
+
+	for i := range events.Items {
+		if names.Contains(events.Items[i].InvolvedObject.Name) {
+			warnings = append(warnings, &events.Items[i])
+		}
+	}
+
+	if len(warnings) == 0 {
+		diagnosePrintf("No Warning events for %s\n", description)
+		return
+	}
+
+	sort.Slice(warnings, func(i, j int) bool {
+		return eventTime(warnings[i]).After(eventTime(warnings[j]))
+	})
+
+	if len(warnings) > maxFailedComponentEvents {
+		warnings = warnings[:maxFailedComponentEvents]
+	}
+
+	diagnosePrintf("Last %d Warning events of %s:\n", len(warnings), description)
+
+	for _, event := range warnings {
+		diagnosePrintf("    %s %s %s/%s: %s (x%d)\n", eventTime(event).Format(time.RFC3339), event.Reason,
+			event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Message, event.Count)
+	}
+}
+
+// eventTime returns the last time the event was seen; events recorded through the events.k8s.io API only set their
+// event time
+func eventTime(event *v1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+
+	return event.CreationTimestamp.Time
+}
@@ -0,0 +1,179 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	mcsv1a1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateExportScopeCmd = &cobra.Command{
+	Use:   "export-scope",
+	Short: "Report which namespaces export services to the other clusters",
+	Long: "This command reports what governs the export of services to the other clusters, and lists the namespaces" +
+		" which are in scope, i.e. contain ServiceExports, and those which aren't. ServiceExports which Lighthouse" +
+		" has rejected or found in conflict are reported with the reason, to explain why a service isn't discovered" +
+		" by the other clusters.",
+	Run: validateExportScope,
+}
+
+func init() {
+	validateCmd.AddCommand(validateExportScopeCmd)
+}
+
+func validateExportScope(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateExportScopeInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateExportScopeInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the scope of the service exports in cluster %q", clusterName))
+
+	if !submariner.Spec.ServiceDiscoveryEnabled {
+		status.QueueSuccessMessage("This check requires service discovery, which is not enabled")
+		status.End(cli.Success)
+		return true
+	}
+
+	exportList, err := clients.dynamicClient.Resource(serviceExportsGVR).Namespace(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the ServiceExports: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	namespaces, err := clients.kubeClient.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the namespaces: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	// Lighthouse doesn't restrict the exports with a namespace or label selector, so the scope is given by the
+	// ServiceExports alone
+	status.QueueSuccessMessage("No namespace or label selector restricts the exports: a service is exported by" +
+		" creating a ServiceExport with the same name in its namespace")
+
+	exportsByNamespace := map[string]int{}
+
+	for i := range exportList.Items {
+		export := &mcsv1a1.ServiceExport{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(exportList.Items[i].Object, export); err != nil {
+			status.QueueWarningMessageWithCode(codeServiceExportInvalid, fmt.Sprintf("Error converting the ServiceExport %s/%s: %s",
+				exportList.Items[i].GetNamespace(), exportList.Items[i].GetName(), err))
+			continue
+		}
+
+		exportsByNamespace[export.Namespace]++
+		checkServiceExportConditions(export)
+	}
+
+	inScope, outOfScope := []string{}, []string{}
+
+	for i := range namespaces.Items {
+		if namespaces.Items[i].Status.Phase == v1.NamespaceTerminating {
+			continue
+		}
+
+		name := namespaces.Items[i].Name
+		if exportsByNamespace[name] > 0 {
+			inScope = append(inScope, fmt.Sprintf("%s (%d)", name, exportsByNamespace[name]))
+		} else {
+			outOfScope = append(outOfScope, name)
+		}
+	}
+
+	sort.Strings(inScope)
+	sort.Strings(outOfScope)
+
+	if len(inScope) == 0 {
+		status.QueueSuccessMessage("No namespace exports services")
+	} else {
+		status.QueueSuccessMessage(fmt.Sprintf("Namespaces exporting services, with their number of ServiceExports: %v",
+			inScope))
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("Namespaces without ServiceExports, whose services aren't exported: %v",
+		outOfScope))
+
+	result := status.ResultFromMessages()
+	status.End(result)
+	return result != cli.Failure
+}
+
+// checkServiceExportConditions reports a ServiceExport which Lighthouse found invalid or in conflict
+func checkServiceExportConditions(export *mcsv1a1.ServiceExport) {
+	for i := range export.Status.Conditions {
+		condition := &export.Status.Conditions[i]
+
+		reason, message := "", ""
+		if condition.Reason != nil {
+			reason = *condition.Reason
+		}
+
+		if condition.Message != nil {
+			message = *condition.Message
+		}
+
+		switch {
+		case condition.Type == mcsv1a1.ServiceExportValid && condition.Status == v1.ConditionFalse:
+			status.QueueWarningMessageWithCode(codeServiceExportInvalid, fmt.Sprintf("The ServiceExport %s/%s isn't valid, so"+
+				" the service isn't exported (%s: %s)", export.Namespace, export.Name, reason, message))
+		case condition.Type == mcsv1a1.ServiceExportConflict && condition.Status == v1.ConditionTrue:
+			status.QueueWarningMessageWithCode(codeServiceExportConflict, fmt.Sprintf("The ServiceExport %s/%s conflicts with"+
+				" the exports of the other clusters (%s: %s)", export.Namespace, export.Name, reason, message))
+		}
+	}
+}
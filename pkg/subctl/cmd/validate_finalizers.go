@@ -0,0 +1,172 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+)
+
+// Finalizers added by Submariner components are qualified with this domain
+const submarinerFinalizerDomain = "submariner.io"
+
+// The resources which may carry Submariner finalizers
+var finalizerResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "namespaces"},
+	{Group: "submariner.io", Version: "v1alpha1", Resource: "submariners"},
+	{Group: "submariner.io", Version: "v1alpha1", Resource: "servicediscoveries"},
+	{Group: "submariner.io", Version: "v1alpha1", Resource: "brokers"},
+	{Group: "submariner.io", Version: "v1", Resource: "endpoints"},
+	{Group: "submariner.io", Version: "v1", Resource: "clusters"},
+	{Group: "submariner.io", Version: "v1", Resource: "gateways"},
+	{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "serviceexports"},
+	{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "serviceimports"},
+}
+
+var validateFinalizersCmd = &cobra.Command{
+	Use:   "finalizers",
+	Short: "Check for leftover Submariner finalizers",
+	Long: "This command checks for Submariner finalizers left on resources, which may block their deletion once the" +
+		" Submariner controllers are gone.",
+	Run: validateFinalizers,
+}
+
+func init() {
+	validateCmd.AddCommand(validateFinalizersCmd)
+}
+
+func validateFinalizers(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateFinalizersInCluster(clients, item.clusterName) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateFinalizersInCluster(clients *clusterClients, clusterName string) bool {
+	status.Start(fmt.Sprintf("Checking for leftover Submariner finalizers in cluster %q", clusterName))
+
+	dynClient, clientSet := clients.dynamicClient, clients.kubeClient
+
+	// The finalizers are only a concern if the operator which would remove them is gone
+	_, err := clientSet.AppsV1().Deployments(OperatorNamespace).Get(context.TODO(), names.OperatorComponent, metav1.GetOptions{})
+	if err == nil {
+		status.QueueSuccessMessage("This check is not necessary as the Submariner operator is deployed")
+		status.End(cli.Success)
+		return true
+	}
+
+	if !apierrors.IsNotFound(err) {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the Submariner operator Deployment: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	for _, gvr := range finalizerResources {
+		checkSubmarinerFinalizers(dynClient, gvr)
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("No leftover Submariner finalizers were found")
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+func checkSubmarinerFinalizers(dynClient dynamic.Interface, gvr schema.GroupVersionResource) {
+	list, err := dynClient.Resource(gvr).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		// The resource type isn't installed
+		if apierrors.IsNotFound(err) {
+			return
+		}
+
+		status.QueueWarningMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the %s: %s", gvr.Resource, err))
+
+		return
+	}
+
+	for i := range list.Items {
+		obj := &list.Items[i]
+		for _, finalizer := range obj.GetFinalizers() {
+			if !strings.Contains(finalizer, submarinerFinalizerDomain) {
+				continue
+			}
+
+			status.QueueWarningMessageWithCode(codeFinalizerPresent, fmt.Sprintf("%s has the finalizer %q, which may block"+
+				" its deletion; if it is stuck, remove it with \"kubectl patch %s --type=merge -p '{\"metadata\":{\"finalizers\":null}}'\"",
+				describeObject(obj), finalizer, kubectlObjectRef(gvr, obj)))
+		}
+	}
+}
+
+func describeObject(obj *unstructured.Unstructured) string {
+	description := fmt.Sprintf("%s %q", obj.GetKind(), obj.GetName())
+	if obj.GetNamespace() != "" {
+		description += fmt.Sprintf(" in namespace %q", obj.GetNamespace())
+	}
+
+	if obj.GetDeletionTimestamp() != nil {
+		description += " (being deleted)"
+	}
+
+	return description
+}
+
+func kubectlObjectRef(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) string {
+	resource := gvr.Resource
+	if gvr.Group != "" {
+		resource += "." + gvr.Group
+	}
+
+	ref := fmt.Sprintf("%s %s", resource, obj.GetName())
+	if obj.GetNamespace() != "" {
+		ref += " -n " + obj.GetNamespace()
+	}
+
+	return ref
+}
@@ -19,9 +19,6 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes"
-
-	"github.com/submariner-io/submariner-operator/pkg/subctl/resource"
 )
 
 var validateFirewallConfigCmd = &cobra.Command{
@@ -42,41 +39,3 @@ func addValidateFWConfigFlags(cmd *cobra.Command) {
 func init() {
 	validateCmd.AddCommand(validateFirewallConfigCmd)
 }
-
-func spawnSnifferPodOnGatewayNode(clientSet *kubernetes.Clientset,
-	namespace, podCommand string) (*resource.NetworkPod, error) {
-	scheduling := resource.PodScheduling{ScheduleOn: resource.GatewayNode, Networking: resource.HostNetworking}
-	return spawnPod(clientSet, scheduling, "validate-sniffer",
-		namespace, podCommand)
-}
-
-func spawnSnifferPodOnNode(clientSet *kubernetes.Clientset,
-	nodeName, namespace, podCommand string) (*resource.NetworkPod, error) {
-	scheduling := resource.PodScheduling{ScheduleOn: resource.CustomNode, NodeName: nodeName,
-		Networking: resource.HostNetworking}
-	return spawnPod(clientSet, scheduling, "validate-sniffer",
-		namespace, podCommand)
-}
-
-func spawnClientPodOnNonGatewayNode(clientSet *kubernetes.Clientset,
-	namespace, podCommand string) (*resource.NetworkPod, error) {
-	scheduling := resource.PodScheduling{ScheduleOn: resource.NonGatewayNode, Networking: resource.PodNetworking}
-	return spawnPod(clientSet, scheduling, "validate-client",
-		namespace, podCommand)
-}
-
-func spawnPod(clientSet *kubernetes.Clientset, scheduling resource.PodScheduling, podName, namespace,
-	podCommand string) (*resource.NetworkPod, error) {
-	pod, err := resource.SchedulePod(&resource.PodConfig{
-		Name:       podName,
-		ClientSet:  clientSet,
-		Scheduling: scheduling,
-		Namespace:  namespace,
-		Command:    podCommand,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-	return pod, nil
-}
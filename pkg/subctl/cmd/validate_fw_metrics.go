@@ -19,12 +19,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 const (
@@ -52,30 +49,37 @@ func validateFirewallMetricsConfig(cmd *cobra.Command, args []string) {
 	validationStatus := true
 
 	for _, item := range configs {
-		validationStatus = validationStatus && validateFirewallMetricsConfigWithinCluster(item.config, item.clusterName)
-	}
+		status.SetCluster(item.clusterName)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
 
-	if !validationStatus {
-		os.Exit(1)
+		validationStatus = validateFirewallMetricsConfigWithinCluster(clients, item.clusterName) && validationStatus
 	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
 }
 
-func validateFirewallMetricsConfigWithinCluster(config *rest.Config, clusterName string) bool {
+func validateFirewallMetricsConfigWithinCluster(clients *clusterClients, clusterName string) bool {
 	status.Start(fmt.Sprintf("Checking the firewall configuration to determine if metrics port (8080)"+
 		" is allowed in cluster %q", clusterName))
 
-	clientSet, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		message := fmt.Sprintf("Error creating API server client: %s", err)
-		status.QueueFailureMessage(message)
-		return false
+	if skipInReadOnlyMode("firewall metrics", clusterName) {
+		return true
 	}
 
+	clientSet := clients.kubeClient
+
 	podCommand := fmt.Sprintf("timeout %d %s", validationTimeout, TCPSniffMetricsCommand)
 	sPod, err := spawnSnifferPodOnGatewayNode(clientSet, namespace, podCommand)
 	if err != nil {
 		message := fmt.Sprintf("Error while spawning the sniffer pod on the GatewayNode: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		return false
 	}
 
@@ -85,20 +89,20 @@ func validateFirewallMetricsConfigWithinCluster(config *rest.Config, clusterName
 	cPod, err := spawnClientPodOnNonGatewayNode(clientSet, namespace, podCommand)
 	if err != nil {
 		message := fmt.Sprintf("Error while spawning the client pod on non-Gateway node: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		return false
 	}
 
 	defer cPod.DeletePod()
 	if err = cPod.AwaitPodCompletion(); err != nil {
 		message := fmt.Sprintf("Error while waiting for client pod to be finish its execution: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		return false
 	}
 
 	if err = sPod.AwaitPodCompletion(); err != nil {
 		message := fmt.Sprintf("Error while waiting for sniffer pod to be finish its execution: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		return false
 	}
 
@@ -112,7 +116,7 @@ func validateFirewallMetricsConfigWithinCluster(config *rest.Config, clusterName
 		message := fmt.Sprintf("The tcpdump output from the sniffer pod does not contain the"+
 			" client pod HostIP. Please check that your firewall configuration allows TCP/8080 traffic"+
 			" on the %q node.", sPod.Pod.Spec.NodeName)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeMetricsTrafficBlocked, message)
 		return false
 	}
 
@@ -19,12 +19,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 
 	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
@@ -55,52 +52,59 @@ func validateFirewallVxLANConfig(cmd *cobra.Command, args []string) {
 	validationStatus := true
 
 	for _, item := range configs {
+		status.SetCluster(item.clusterName)
 		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
-		submariner := getSubmarinerResource(item.config)
+		submariner, err := retrieveSubmariner(item.config)
 		if submariner == nil {
-			status.QueueWarningMessage(submMissingMessage)
-			status.End(cli.Warning)
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
 			continue
 		}
 
 		status.End(cli.Success)
-		validationStatus = validationStatus && validateVxLANConfigWithinCluster(item.config, item.clusterName, submariner)
-	}
 
-	if !validationStatus {
-		os.Exit(1)
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateVxLANConfigWithinCluster(clients, item.clusterName, submariner) && validationStatus
 	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
 }
 
-func validateVxLANConfigWithinCluster(config *rest.Config, clusterName string, submariner *v1alpha1.Submariner) bool {
+func validateVxLANConfigWithinCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
 	status.Start(fmt.Sprintf("Checking the firewall configuration to determine if VXLAN traffic is allowed"+
 		" in cluster %q", clusterName))
-	validationStatus := validateFWConfigWithinCluster(config, submariner)
+
+	if skipInReadOnlyMode("firewall VXLAN", clusterName) {
+		return true
+	}
+
+	validationStatus := validateFWConfigWithinCluster(clients, submariner)
 	status.End(status.ResultFromMessages())
 	return validationStatus
 }
 
-func validateFWConfigWithinCluster(config *rest.Config, submariner *v1alpha1.Submariner) bool {
+func validateFWConfigWithinCluster(clients *clusterClients, submariner *v1alpha1.Submariner) bool {
 	if submariner.Status.NetworkPlugin == "OVNKubernetes" {
 		status.QueueSuccessMessage("This check is not necessary for the OVNKubernetes CNI plugin")
 		return true
 	}
 
-	clientSet, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		message := fmt.Sprintf("Error creating API server client: %s", err)
-		status.QueueFailureMessage(message)
-		return false
-	}
+	clientSet := clients.kubeClient
 
-	gateways := getGatewaysResource(config)
+	gateways := getGatewaysResourceWithClient(clients.submarinerClient)
 	if gateways == nil || len(gateways.Items) == 0 {
-		status.QueueWarningMessage("There are no gateways detected on the cluster.")
+		status.QueueWarningMessageWithCode(codeNoGateway, "There are no gateways detected on the cluster.")
 		return false
 	}
 
 	if len(gateways.Items[0].Status.Connections) == 0 {
-		status.QueueWarningMessage("There are no active connections to remote clusters.")
+		status.QueueWarningMessageWithCode(codeNoConnections, "There are no active connections to remote clusters.")
 		return false
 	}
 
@@ -108,7 +112,7 @@ func validateFWConfigWithinCluster(config *rest.Config, submariner *v1alpha1.Sub
 	sPod, err := spawnSnifferPodOnGatewayNode(clientSet, namespace, podCommand)
 	if err != nil {
 		message := fmt.Sprintf("Error while spawning the sniffer pod on the GatewayNode: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		return false
 	}
 
@@ -118,20 +122,20 @@ func validateFWConfigWithinCluster(config *rest.Config, submariner *v1alpha1.Sub
 	cPod, err := spawnClientPodOnNonGatewayNode(clientSet, namespace, podCommand)
 	if err != nil {
 		message := fmt.Sprintf("Error while spawning the client pod on non-Gateway node: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		return false
 	}
 
 	defer cPod.DeletePod()
 	if err = cPod.AwaitPodCompletion(); err != nil {
 		message := fmt.Sprintf("Error while waiting for client pod to be finish its execution: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		return false
 	}
 
 	if err = sPod.AwaitPodCompletion(); err != nil {
 		message := fmt.Sprintf("Error while waiting for sniffer pod to be finish its execution: %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		return false
 	}
 
@@ -144,7 +148,7 @@ func validateFWConfigWithinCluster(config *rest.Config, submariner *v1alpha1.Sub
 	if !strings.Contains(sPod.PodOutput, remoteClusterIP) {
 		message := fmt.Sprintf("The tcpdump output from the sniffer pod does not contain the expected remote"+
 			" endpoint IP %s. Please check that your firewall configuration allows UDP/4800 traffic.", remoteClusterIP)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeVXLANTrafficBlocked, message)
 		return false
 	}
 
@@ -152,7 +156,7 @@ func validateFWConfigWithinCluster(config *rest.Config, submariner *v1alpha1.Sub
 	if !strings.Contains(sPod.PodOutput, cPod.Pod.Status.PodIP) {
 		message := fmt.Sprintf("The tcpdump output from the sniffer pod does not contain the client pod's IP."+
 			" There seems to be some issue with the IPTable rules programmed on the %q node", cPod.Pod.Spec.NodeName)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeVXLANTrafficMangled, message)
 		return false
 	}
 
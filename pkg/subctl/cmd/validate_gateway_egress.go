@@ -0,0 +1,210 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/spf13/cobra"
+	smClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	DefaultRouteCommand = "ip route show default"
+	RouteToCommand      = "ip route get %s"
+	// Connects over TCP with bash's /dev/tcp, so as not to depend on other tools in the gateway image
+	EgressConnectCommand = "timeout 5 bash -c '</dev/tcp/%s/%s'"
+)
+
+var egressTarget string
+
+var validateGatewayEgressCmd = &cobra.Command{
+	Use:   "gateway-egress",
+	Short: "Check the outbound connectivity of the gateways",
+	Long: "This command checks, from each gateway pod, that the gateway node has a default route. With --egress-target," +
+		" it also checks that the gateway node can connect to the given target over TCP, which catches egress" +
+		" problems such as missing egress NAT. Without it, the command only checks that the node has routes to the" +
+		" public IPs of the remote Endpoints: a route doesn't prove that they're reachable.",
+	Run: validateGatewayEgress,
+}
+
+func init() {
+	addGatewayEgressFlags(validateGatewayEgressCmd)
+	validateCmd.AddCommand(validateGatewayEgressCmd)
+}
+
+func addGatewayEgressFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&egressTarget, "egress-target", "",
+		"external host:port the gateways should be able to connect to; without it, only the routes to the remote"+
+			" Endpoints' public IPs are checked, not their reachability")
+}
+
+func validateGatewayEgress(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateGatewayEgressInCluster(item.config, clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateGatewayEgressInCluster(config *rest.Config, clients *clusterClients, clusterName string,
+	submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the outbound connectivity of the gateways in cluster %q", clusterName))
+
+	if skipInReadOnlyMode("gateway egress", clusterName) {
+		return true
+	}
+
+	targetHost, targetPort := "", ""
+	if egressTarget != "" {
+		var err error
+		if targetHost, targetPort, err = net.SplitHostPort(egressTarget); err != nil {
+			status.QueueFailureMessageWithCode(codeInputInvalid, fmt.Sprintf("Invalid egress target %q: %s", egressTarget, err))
+			status.End(cli.Failure)
+			return false
+		}
+	}
+
+	clientSet := clients.kubeClient
+
+	var remotePublicIPs []string
+	if egressTarget == "" {
+		var err error
+		if remotePublicIPs, err = getRemotePublicIPs(clients.submarinerClient, submariner.Spec.ClusterID); err != nil {
+			status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner endpoints: %s", err))
+			status.End(cli.Failure)
+			return false
+		}
+	}
+
+	pods, err := clientSet.CoreV1().Pods(OperatorNamespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: "app=submariner-gateway"})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Gateway pods: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	checked := 0
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		checked++
+
+		output, err := execInPod(config, clientSet, pod, DefaultRouteCommand)
+		if err != nil || strings.TrimSpace(output) == "" {
+			status.QueueFailureMessageWithCode(codeGatewayNoDefaultRoute, fmt.Sprintf("The gateway node %q has no default route",
+				pod.Spec.NodeName))
+			continue
+		}
+
+		if egressTarget != "" {
+			if _, err := execInPod(config, clientSet, pod, fmt.Sprintf(EgressConnectCommand, targetHost, targetPort)); err != nil {
+				status.QueueFailureMessageWithCode(codeGatewayEgressBlocked, fmt.Sprintf("The gateway node %q can't connect to"+
+					" %q: %s", pod.Spec.NodeName,
+					egressTarget, err))
+			}
+
+			continue
+		}
+
+		for _, publicIP := range remotePublicIPs {
+			if _, err := execInPod(config, clientSet, pod, fmt.Sprintf(RouteToCommand, publicIP)); err != nil {
+				status.QueueFailureMessageWithCode(codeGatewayNoRouteToPeer, fmt.Sprintf("The gateway node %q has no route to"+
+					" the remote public IP %q: %s",
+					pod.Spec.NodeName, publicIP, err))
+			}
+		}
+	}
+
+	if checked == 0 {
+		status.QueueFailureMessageWithCode(codeNoGateway, "No running Gateway pod was found")
+		status.End(cli.Failure)
+		return false
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	if egressTarget != "" {
+		status.QueueSuccessMessage(fmt.Sprintf("All the gateways can connect to %q", egressTarget))
+	} else {
+		status.QueueSuccessMessage(fmt.Sprintf("All the gateways have a default route and routes to the remote public"+
+			" IPs %v; use --egress-target to check that they can actually connect out", remotePublicIPs))
+	}
+
+	status.End(cli.Success)
+	return true
+}
+
+// getRemotePublicIPs returns the public IPs of the Endpoints of the other clusters
+func getRemotePublicIPs(submarinerClient smClientset.Interface, localClusterID string) ([]string, error) {
+	endpoints, err := submarinerClient.SubmarinerV1().Endpoints(OperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	publicIPs := []string{}
+
+	for i := range endpoints.Items {
+		endpoint := &endpoints.Items[i]
+		if endpoint.Spec.ClusterID != localClusterID && endpoint.Spec.PublicIP != "" {
+			publicIPs = append(publicIPs, endpoint.Spec.PublicIP)
+		}
+	}
+
+	return publicIPs, nil
+}
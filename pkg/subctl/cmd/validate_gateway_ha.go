@@ -0,0 +1,170 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+// The gateway HA modes of a cluster, as deduced from its Gateways
+const (
+	singleGatewayMode = "single"
+	activePassiveMode = "active-passive"
+	activeActiveMode  = "active-active"
+	noGatewayMode     = "none"
+)
+
+var validateGatewayHAModeCmd = &cobra.Command{
+	Use:   "gateway-ha-mode",
+	Short: "Check that the clusters use the same gateway HA mode",
+	Long: "This command reports the gateway HA mode of each cluster: single, with one gateway, or active-passive," +
+		" with several gateways of which one is active. Clusters whose mode differs from the intended one, given" +
+		" with --gateway-ha-mode or otherwise the mode of the majority, are flagged; so are clusters with more than" +
+		" one active gateway, which isn't supported.",
+	Run: validateGatewayHAMode,
+}
+
+var intendedGatewayHAMode string
+
+func init() {
+	addGatewayHAModeFlags(validateGatewayHAModeCmd)
+	validateCmd.AddCommand(validateGatewayHAModeCmd)
+}
+
+func addGatewayHAModeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&intendedGatewayHAMode, "gateway-ha-mode", "",
+		fmt.Sprintf("intended gateway HA mode of the clusters - any of %s,%s; defaults to the mode of the majority",
+			singleGatewayMode, activePassiveMode))
+}
+
+func validateGatewayHAMode(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	finishValidation(validateGatewayHAModeAcrossClusters(configs))
+}
+
+// validateGatewayHAModeAcrossClusters reports the gateway HA mode of each cluster, and flags the clusters whose mode
+// differs from the intended mode
+func validateGatewayHAModeAcrossClusters(configs []restConfig) bool {
+	status.Start("Checking that the gateway HA mode is consistent across the clusters")
+
+	if intendedGatewayHAMode != "" && intendedGatewayHAMode != singleGatewayMode && intendedGatewayHAMode != activePassiveMode {
+		status.QueueFailureMessageWithCode(codeInputInvalid, fmt.Sprintf("Invalid gateway HA mode %q, expected one of"+
+			" %s,%s", intendedGatewayHAMode, singleGatewayMode, activePassiveMode))
+		status.End(cli.Failure)
+		return false
+	}
+
+	clustersByMode := map[string][]string{}
+
+	for _, item := range configs {
+		submariner, err := retrieveSubmariner(item.config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the Submariner resource from"+
+				" cluster %q: %s", item.clusterName, err))
+			continue
+		}
+
+		if submariner == nil {
+			if requireInstalled {
+				status.QueueFailureMessageWithCode(codeSubmarinerNotInstalled, fmt.Sprintf("Submariner is not installed in"+
+					" cluster %q", item.clusterName))
+			}
+
+			continue
+		}
+
+		mode, detail := gatewayHAMode(getGatewaysResource(item.config))
+		status.QueueSuccessMessage(fmt.Sprintf("Cluster %q uses the %s gateway mode (%s)", item.clusterName, mode, detail))
+
+		clustersByMode[mode] = append(clustersByMode[mode], item.clusterName)
+	}
+
+	for _, clusterName := range clustersByMode[activeActiveMode] {
+		status.QueueFailureMessageWithCode(codeMultipleActiveGateways,
+			fmt.Sprintf("Cluster %q has more than one active gateway, which isn't supported",
+				clusterName))
+	}
+
+	for _, clusterName := range clustersByMode[noGatewayMode] {
+		status.QueueFailureMessageWithCode(codeNoGateway, fmt.Sprintf("Cluster %q has no gateway", clusterName))
+	}
+
+	intended := intendedGatewayHAMode
+	if intended == "" {
+		intended = majorityGatewayHAMode(clustersByMode)
+	}
+
+	for _, mode := range []string{singleGatewayMode, activePassiveMode} {
+		if mode == intended || len(clustersByMode[mode]) == 0 {
+			continue
+		}
+
+		clusters := clustersByMode[mode]
+		sort.Strings(clusters)
+		status.QueueWarningMessageWithCode(codeGatewayHAModeMismatch,
+			fmt.Sprintf("Clusters %v use the %s gateway mode instead of the intended %s mode",
+				clusters, mode, intended))
+	}
+
+	result := status.ResultFromMessages()
+	status.End(result)
+	return result != cli.Failure
+}
+
+// gatewayHAMode returns the gateway HA mode deduced from the Gateways of a cluster, with the gateway counts
+func gatewayHAMode(gateways *subv1.GatewayList) (string, string) {
+	if gateways == nil || len(gateways.Items) == 0 {
+		return noGatewayMode, "no gateways"
+	}
+
+	active := 0
+	for i := range gateways.Items {
+		if gateways.Items[i].Status.HAStatus == subv1.HAStatusActive {
+			active++
+		}
+	}
+
+	detail := fmt.Sprintf("%d gateways, %d active", len(gateways.Items), active)
+
+	switch {
+	case active > 1:
+		return activeActiveMode, detail
+	case len(gateways.Items) > 1:
+		return activePassiveMode, detail
+	default:
+		return singleGatewayMode, detail
+	}
+}
+
+// majorityGatewayHAMode returns the supported gateway HA mode used by the most clusters, preferring active-passive
+// on a tie
+func majorityGatewayHAMode(clustersByMode map[string][]string) string {
+	if len(clustersByMode[singleGatewayMode]) > len(clustersByMode[activePassiveMode]) {
+		return singleGatewayMode
+	}
+
+	return activePassiveMode
+}
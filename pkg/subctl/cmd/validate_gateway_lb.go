@@ -0,0 +1,141 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateGatewayLoadBalancerCmd = &cobra.Command{
+	Use:   "gateway-loadbalancer",
+	Short: "Check the LoadBalancer Service exposing the Gateway",
+	Long: "This command checks that any LoadBalancer Service exposing the Gateway pods has been provisioned with" +
+		" an external address.",
+	Run: validateGatewayLoadBalancer,
+}
+
+func init() {
+	validateCmd.AddCommand(validateGatewayLoadBalancerCmd)
+}
+
+func validateGatewayLoadBalancer(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateGatewayLoadBalancerInCluster(clients, item.clusterName) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+// validateGatewayLoadBalancerInCluster checks the LoadBalancer Services selecting the Gateway pods. The Submariner
+// resource has no load balancer setting, so load balancer mode is detected from the presence of such a Service.
+func validateGatewayLoadBalancerInCluster(clients *clusterClients, clusterName string) bool {
+	status.Start(fmt.Sprintf("Checking the Gateway LoadBalancer Service in cluster %q", clusterName))
+
+	clientSet := clients.kubeClient
+
+	services, err := clientSet.CoreV1().Services(OperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Services: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	found := false
+
+	for i := range services.Items {
+		service := &services.Items[i]
+		if service.Spec.Type != v1.ServiceTypeLoadBalancer || service.Spec.Selector["app"] != "submariner-gateway" {
+			continue
+		}
+
+		found = true
+
+		if len(service.Status.LoadBalancer.Ingress) > 0 {
+			continue
+		}
+
+		status.QueueFailureMessageWithCode(codeGatewayLBNoAddress,
+			fmt.Sprintf("The LoadBalancer Service %q has no external address assigned", service.Name))
+		queueServiceEvents(clientSet, service)
+	}
+
+	if !found {
+		status.QueueSuccessMessage("This check is not necessary as the Gateway is not exposed by a LoadBalancer Service")
+		status.End(cli.Success)
+		return true
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage("The Gateway LoadBalancer Service has an external address")
+	status.End(cli.Success)
+	return true
+}
+
+// queueServiceEvents reports the Warning events of the Service, which explain why no address was assigned; the Normal
+// events, e.g. EnsuringLoadBalancer, only record the progress of the cloud provider
+func queueServiceEvents(clientSet kubernetes.Interface, service *v1.Service) {
+	selector := fields.Set{
+		"involvedObject.kind": "Service",
+		"involvedObject.name": service.Name,
+	}.AsSelector()
+
+	events, err := clientSet.CoreV1().Events(service.Namespace).List(context.TODO(),
+		metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		status.QueueWarningMessageWithCode(codeAPIListFailed, fmt.Sprintf("Unable to list the events for Service %q: %s", service.Name, err))
+		return
+	}
+
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.Type != v1.EventTypeWarning {
+			continue
+		}
+
+		status.QueueFailureMessageWithCode(codeGatewayLBEvent, fmt.Sprintf("Service %q event: %s %s: %s", service.Name,
+			event.Type, event.Reason, event.Message))
+	}
+}
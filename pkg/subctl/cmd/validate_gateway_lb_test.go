@@ -0,0 +1,53 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newServiceEvent(name, eventType, reason string, service *v1.Service) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: service.Namespace},
+		InvolvedObject: v1.ObjectReference{Kind: "Service", Name: service.Name, Namespace: service.Namespace},
+		Type:           eventType,
+		Reason:         reason,
+	}
+}
+
+func TestQueueServiceEventsReportsOnlyWarnings(t *testing.T) {
+	service := newGatewayService("submariner-gateway", v1.ServiceTypeLoadBalancer)
+	clientSet := fake.NewSimpleClientset(
+		newServiceEvent("ensuring", v1.EventTypeNormal, "EnsuringLoadBalancer", service),
+		newServiceEvent("ensured", v1.EventTypeNormal, "EnsuredLoadBalancer", service),
+		newServiceEvent("failed", v1.EventTypeWarning, "SyncLoadBalancerFailed", service))
+
+	record := recordCheck(t, func() {
+		queueServiceEvents(clientSet, service)
+	})
+
+	if len(record.Failures) != 1 || !strings.Contains(record.Failures[0], "SyncLoadBalancerFailed") {
+		t.Fatalf("Expected only the Warning event to be reported, got %q", record.Failures)
+	}
+}
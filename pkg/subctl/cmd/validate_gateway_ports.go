@@ -0,0 +1,344 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+)
+
+// The ports used by the gateways when the Submariner resource doesn't set them, as defaulted by subctl join
+const (
+	defaultIKEPort  = 500
+	defaultNATTPort = 4500
+)
+
+// exposedPort is a port exposed for the gateway pods, by a Service or by the pods themselves
+type exposedPort struct {
+	port     int32
+	protocol v1.Protocol
+}
+
+var validateGatewayPortsCmd = &cobra.Command{
+	Use:   "gateway-ports",
+	Short: "Check that the ports exposed for the gateways match the cable driver",
+	Long: "This command checks that the Services selecting the gateway pods, and the gateway pods if they declare" +
+		" ports, expose the UDP ports used by the active cable driver: the IKE and NAT-T ports with libreswan, the" +
+		" tunnel port with WireGuard. It also reports the libreswan ports which are still exposed after switching to" +
+		" another driver.",
+	Run: validateGatewayPorts,
+}
+
+func init() {
+	validateCmd.AddCommand(validateGatewayPortsCmd)
+}
+
+func validateGatewayPorts(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateGatewayPortsInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateGatewayPortsInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the ports exposed for the gateways of cluster %q", clusterName))
+
+	clientSet := clients.kubeClient
+
+	endpoints, err := listEndpoints(clients.submarinerClient)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Endpoints: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	var localEndpoint *subv1.Endpoint
+
+	for i := range endpoints {
+		if endpoints[i].Spec.ClusterID == submariner.Spec.ClusterID {
+			localEndpoint = &endpoints[i]
+			break
+		}
+	}
+
+	// The local Endpoint advertises the driver actually in use, which may lag behind the Submariner resource
+	cableDriver := submariner.Spec.CableDriver
+	if localEndpoint != nil && localEndpoint.Spec.Backend != "" {
+		cableDriver = localEndpoint.Spec.Backend
+	}
+
+	if cableDriver == "" {
+		cableDriver = defaultCableDriver
+	}
+
+	expected, ok := expectedGatewayPorts(cableDriver, submariner, localEndpoint)
+	if !ok {
+		status.QueueWarningMessageWithCode(codeCableDriverUnknown, fmt.Sprintf("The ports used by the %q cable driver are"+
+			" unknown", cableDriver))
+		status.End(cli.Warning)
+		return true
+	}
+
+	exposed, err := exposedGatewayPorts(clientSet)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, err.Error())
+		status.End(cli.Failure)
+		return false
+	}
+
+	if len(exposed) == 0 {
+		status.QueueSuccessMessage("This check is not necessary as the gateways aren't exposed by a Service and their" +
+			" pods don't declare ports")
+		status.End(cli.Success)
+		return true
+	}
+
+	// The ports configured for libreswan, which may still be exposed after switching drivers
+	libreswanPorts, _ := expectedGatewayPorts(defaultCableDriver, submariner, nil)
+
+	owners := make([]string, 0, len(exposed))
+	for owner := range exposed {
+		owners = append(owners, owner)
+	}
+
+	sort.Strings(owners)
+
+	for _, owner := range owners {
+		checkExposedGatewayPorts(owner, exposed[owner], cableDriver, expected, libreswanPorts)
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage(fmt.Sprintf("The ports exposed for the gateways match the %q cable driver", cableDriver))
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+// expectedGatewayPorts returns the UDP ports used by the cable driver, along with their purpose
+func expectedGatewayPorts(cableDriver string, submariner *v1alpha1.Submariner, endpoint *subv1.Endpoint) (map[int32]string, bool) {
+	nattPort := int32(portOrDefault(submariner.Spec.CeIPSecNATTPort, defaultNATTPort))
+	if endpoint != nil {
+		if port, err := endpoint.Spec.GetBackendPort(subv1.UDPPortConfig, nattPort); err == nil {
+			nattPort = port
+		}
+	}
+
+	switch cableDriver {
+	case defaultCableDriver:
+		return map[int32]string{
+			int32(portOrDefault(submariner.Spec.CeIPSecIKEPort, defaultIKEPort)): "IKE",
+			nattPort: "NAT-T",
+		}, true
+	case wireGuardCableDriver:
+		return map[int32]string{nattPort: "tunnel"}, true
+	default:
+		return nil, false
+	}
+}
+
+func portOrDefault(port, defaultPort int) int {
+	if port == 0 {
+		return defaultPort
+	}
+
+	return port
+}
+
+// exposedGatewayPorts returns the ports exposed by the Services carrying the tunnel traffic to the gateway pods, and by the gateway pods
+// themselves, indexed by the description of the object exposing them
+func exposedGatewayPorts(clientSet kubernetes.Interface) (map[string][]exposedPort, error) {
+	exposed := map[string][]exposedPort{}
+
+	services, err := clientSet.CoreV1().Services(OperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing the Services: %s", err)
+	}
+
+	for i := range services.Items {
+		service := &services.Items[i]
+		if service.Spec.Selector["app"] != names.GatewayComponent || !carriesTunnelTraffic(service) {
+			continue
+		}
+
+		ports := []exposedPort{}
+		for _, port := range service.Spec.Ports {
+			target := port.Port
+			if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal != 0 {
+				target = port.TargetPort.IntVal
+			}
+
+			ports = append(ports, exposedPort{port: target, protocol: protocolOrDefault(port.Protocol)})
+		}
+
+		exposed[fmt.Sprintf("Service %q", service.Name)] = ports
+	}
+
+	daemonSet, err := clientSet.AppsV1().DaemonSets(OperatorNamespace).Get(context.TODO(), names.GatewayComponent,
+		metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return exposed, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the gateway DaemonSet: %s", err)
+	}
+
+	ports := []exposedPort{}
+	for i := range daemonSet.Spec.Template.Spec.Containers {
+		for _, port := range daemonSet.Spec.Template.Spec.Containers[i].Ports {
+			target := port.ContainerPort
+			if port.HostPort != 0 {
+				target = port.HostPort
+			}
+
+			ports = append(ports, exposedPort{port: target, protocol: protocolOrDefault(port.Protocol)})
+		}
+	}
+
+	if len(ports) > 0 {
+		exposed[fmt.Sprintf("DaemonSet %q", daemonSet.Name)] = ports
+	}
+
+	return exposed, nil
+}
+
+// carriesTunnelTraffic returns true if the Service selecting the gateway pods exposes them outside the cluster or
+// exposes UDP ports; the metrics Services only expose TCP ports to the cluster
+func carriesTunnelTraffic(service *v1.Service) bool {
+	if strings.HasSuffix(service.Name, "-metrics") {
+		return false
+	}
+
+	if service.Spec.Type == v1.ServiceTypeLoadBalancer || service.Spec.Type == v1.ServiceTypeNodePort {
+		return true
+	}
+
+	for _, port := range service.Spec.Ports {
+		if protocolOrDefault(port.Protocol) == v1.ProtocolUDP {
+			return true
+		}
+	}
+
+	return false
+}
+
+func protocolOrDefault(protocol v1.Protocol) v1.Protocol {
+	if protocol == "" {
+		return v1.ProtocolTCP
+	}
+
+	return protocol
+}
+
+// checkExposedGatewayPorts checks that the object exposes the expected UDP ports, and reports the libreswan ports it
+// exposes which the active cable driver doesn't use
+func checkExposedGatewayPorts(owner string, ports []exposedPort, cableDriver string, expected, libreswanPorts map[int32]string) {
+	expectedPorts := make([]int, 0, len(expected))
+	for port := range expected {
+		expectedPorts = append(expectedPorts, int(port))
+	}
+
+	sort.Ints(expectedPorts)
+
+	for _, expectedPort := range expectedPorts {
+		port := int32(expectedPort)
+		purpose := expected[port]
+		found := false
+		for _, exposed := range ports {
+			if exposed.port == port && exposed.protocol == v1.ProtocolUDP {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			status.QueueFailureMessageWithCode(codeGatewayPortMissing,
+				fmt.Sprintf("%s doesn't expose the UDP %s port %d used by the %q cable driver; it"+
+					" exposes %s", owner, purpose, port, cableDriver, describeExposedPorts(ports)))
+		}
+	}
+
+	for _, exposed := range ports {
+		purpose, isLibreswanPort := libreswanPorts[exposed.port]
+		if _, isExpected := expected[exposed.port]; isExpected || !isLibreswanPort || exposed.protocol != v1.ProtocolUDP {
+			continue
+		}
+
+		status.QueueWarningMessageWithCode(codeGatewayStalePort,
+			fmt.Sprintf("%s exposes the libreswan %s port %d, which the %q cable driver doesn't"+
+				" use", owner, purpose, exposed.port, cableDriver))
+	}
+}
+
+func describeExposedPorts(ports []exposedPort) string {
+	if len(ports) == 0 {
+		return "no ports"
+	}
+
+	description := ""
+	for i, port := range ports {
+		if i > 0 {
+			description += ", "
+		}
+
+		description += fmt.Sprintf("%d/%s", port.port, port.protocol)
+	}
+
+	return description
+}
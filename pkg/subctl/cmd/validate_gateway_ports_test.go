@@ -0,0 +1,79 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/submariner-io/submariner-operator/pkg/names"
+)
+
+func newGatewayService(name string, serviceType v1.ServiceType, ports ...v1.ServicePort) *v1.Service {
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: OperatorNamespace},
+		Spec: v1.ServiceSpec{
+			Type:     serviceType,
+			Selector: map[string]string{"app": names.GatewayComponent},
+			Ports:    ports,
+		},
+	}
+}
+
+func newMetricsService() *v1.Service {
+	return newGatewayService("submariner-gateway-metrics", v1.ServiceTypeClusterIP,
+		v1.ServicePort{Name: "metrics", Port: 8080, Protocol: v1.ProtocolTCP, TargetPort: intstr.FromInt(32780)})
+}
+
+func TestExposedGatewayPortsIgnoresMetricsService(t *testing.T) {
+	exposed, err := exposedGatewayPorts(fake.NewSimpleClientset(newMetricsService()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(exposed) != 0 {
+		t.Fatalf("Expected the metrics Service to be ignored, got %v", exposed)
+	}
+}
+
+func TestExposedGatewayPortsReturnsTunnelServices(t *testing.T) {
+	nodePort := newGatewayService("submariner-gateway", v1.ServiceTypeNodePort,
+		v1.ServicePort{Name: "ipsec-encaps", Port: 4500, Protocol: v1.ProtocolUDP})
+	udp := newGatewayService("gateway-udp", v1.ServiceTypeClusterIP,
+		v1.ServicePort{Name: "ike", Port: 500, Protocol: v1.ProtocolUDP})
+
+	exposed, err := exposedGatewayPorts(fake.NewSimpleClientset(newMetricsService(), nodePort, udp))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := map[string][]exposedPort{
+		"Service \"submariner-gateway\"": {{port: 4500, protocol: v1.ProtocolUDP}},
+		"Service \"gateway-udp\"":        {{port: 500, protocol: v1.ProtocolUDP}},
+	}
+
+	if !reflect.DeepEqual(exposed, expected) {
+		t.Fatalf("Expected %v, got %v", expected, exposed)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	smClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateGlobalnetAllocationCmd = &cobra.Command{
+	Use:   "globalnet-allocation",
+	Short: "Check that the clusters use the global CIDRs allocated by the broker",
+	Long: "This command checks that the global CIDR used by each cluster matches the one recorded for it in the" +
+		" broker's globalnet ConfigMap. It requires access to the broker cluster.",
+	Run: validateGlobalnetAllocation,
+}
+
+func init() {
+	addBrokerFlags(validateGlobalnetAllocationCmd)
+	validateCmd.AddCommand(validateGlobalnetAllocationCmd)
+}
+
+func validateGlobalnetAllocation(cmd *cobra.Command, args []string) {
+	brokerConfig, err := getRestConfig(kubeConfig, brokerContext)
+	exitOnError("Error getting REST config for the broker cluster", err)
+
+	brokerClientSet, err := kubernetes.NewForConfig(brokerConfig)
+	exitOnError("Error creating the broker API server client", err)
+
+	globalnetInfo, _, err := globalnet.ReadGlobalNetworks(brokerClientSet, diagnoseBrokerNamespace)
+	exitOnError("Error reading the globalnet ConfigMap from the broker", err)
+
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+		validationStatus = validateGlobalnetAllocationInCluster(item.config, item.clusterName, submariner,
+			globalnetInfo) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+// validateGlobalnetAllocationInCluster compares the global CIDR configured for the cluster, and the one advertised
+// by its Endpoint, with the allocation recorded in the broker
+func validateGlobalnetAllocationInCluster(config *rest.Config, clusterName string, submariner *v1alpha1.Submariner,
+	globalnetInfo *globalnet.GlobalnetInfo) bool {
+	status.Start(fmt.Sprintf("Checking the global CIDR allocation of cluster %q", clusterName))
+
+	if submariner.Spec.GlobalCIDR == "" {
+		status.QueueSuccessMessage("This check is only necessary when Globalnet is enabled")
+		status.End(cli.Success)
+		return true
+	}
+
+	clusterID := submariner.Spec.ClusterID
+
+	allocation, found := globalnetInfo.GlobalCidrInfo[clusterID]
+	if !found || len(allocation.GlobalCIDRs) == 0 {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRMismatch,
+			fmt.Sprintf("The broker has no global CIDR recorded for cluster %q, which uses %q",
+				clusterID, submariner.Spec.GlobalCIDR))
+		status.End(cli.Failure)
+		return false
+	}
+
+	allocated := allocation.GlobalCIDRs[0]
+	if submariner.Spec.GlobalCIDR != allocated {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRMismatch,
+			fmt.Sprintf("Cluster %q is configured with the global CIDR %q but the broker"+
+				" allocated %q", clusterID, submariner.Spec.GlobalCIDR, allocated))
+	}
+
+	submarinerClient, err := smClientset.NewForConfig(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating the Submariner client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	endpoints, err := listEndpoints(submarinerClient)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner endpoints: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if endpoint.Spec.ClusterID != clusterID {
+			continue
+		}
+
+		if len(endpoint.Spec.Subnets) != 1 || endpoint.Spec.Subnets[0] != allocated {
+			status.QueueFailureMessageWithCode(codeGlobalCIDRMismatch,
+				fmt.Sprintf("The Endpoint %q of cluster %q advertises the global CIDRs %v but the"+
+					" broker allocated %q", endpoint.Name, clusterID, endpoint.Spec.Subnets, allocated))
+		}
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("Cluster %q uses the global CIDR %q allocated by the broker", clusterID, allocated))
+	status.End(cli.Success)
+	return true
+}
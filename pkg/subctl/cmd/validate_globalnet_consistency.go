@@ -0,0 +1,114 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateGlobalnetConsistencyCmd = &cobra.Command{
+	Use:   "globalnet-consistency",
+	Short: "Check that Globalnet is either enabled or disabled in all the clusters",
+	Long: "This command checks that the clusters in the clusterset don't mix Globalnet and non-Globalnet" +
+		" deployments.",
+	Run: validateGlobalnetConsistency,
+}
+
+func init() {
+	validateCmd.AddCommand(validateGlobalnetConsistencyCmd)
+}
+
+func validateGlobalnetConsistency(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateGlobalnetConsistencyInCluster(clients, item.clusterName) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+// validateGlobalnetConsistencyInCluster checks the Cluster resources synced from the broker, which record the
+// global CIDRs of every cluster in the clusterset
+func validateGlobalnetConsistencyInCluster(clients *clusterClients, clusterName string) bool {
+	status.Start(fmt.Sprintf("Checking that the clusters seen from cluster %q don't mix Globalnet and non-Globalnet",
+		clusterName))
+
+	submarinerClient := clients.submarinerClient
+
+	clusters, err := submarinerClient.SubmarinerV1().Clusters(OperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner clusters: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	globalnetClusters := []string{}
+	nonGlobalnetClusters := []string{}
+
+	for i := range clusters.Items {
+		if len(clusters.Items[i].Spec.GlobalCIDR) > 0 {
+			globalnetClusters = append(globalnetClusters, clusters.Items[i].Spec.ClusterID)
+		} else {
+			nonGlobalnetClusters = append(nonGlobalnetClusters, clusters.Items[i].Spec.ClusterID)
+		}
+	}
+
+	if len(globalnetClusters) == 0 || len(nonGlobalnetClusters) == 0 {
+		status.QueueSuccessMessage(fmt.Sprintf("All the %d clusters use the same Globalnet setting", len(clusters.Items)))
+		status.End(cli.Success)
+		return true
+	}
+
+	sort.Strings(globalnetClusters)
+	sort.Strings(nonGlobalnetClusters)
+
+	// Point out the smaller group, which is the most likely to be misconfigured
+	if len(globalnetClusters) <= len(nonGlobalnetClusters) {
+		status.QueueFailureMessageWithCode(codeGlobalnetInconsistent, fmt.Sprintf("Globalnet is enabled in clusters %v but"+
+			" disabled in the other clusters %v",
+			globalnetClusters, nonGlobalnetClusters))
+	} else {
+		status.QueueFailureMessageWithCode(codeGlobalnetInconsistent, fmt.Sprintf("Globalnet is disabled in clusters %v but"+
+			" enabled in the other clusters %v",
+			nonGlobalnetClusters, globalnetClusters))
+	}
+
+	status.End(cli.Failure)
+	return false
+}
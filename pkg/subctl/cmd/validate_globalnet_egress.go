@@ -0,0 +1,269 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateGlobalnetEgressIPsCmd = &cobra.Command{
+	Use:   "globalnet-egress-ips",
+	Short: "Check the GlobalEgressIPs assigned to namespaces and pods",
+	Long: "This command checks that each GlobalEgressIP has been allocated the requested number of global IPs from" +
+		" the cluster's global CIDR, that its namespace exists and, if it selects pods, that it selects at least one" +
+		" pod. Pods selected by several GlobalEgressIPs of the same kind, whose egress IP is therefore undetermined, are" +
+		" reported too; a GlobalEgressIP with a pod selector takes precedence over the namespace-wide ones.",
+	Run: validateGlobalnetEgressIPs,
+}
+
+func init() {
+	validateCmd.AddCommand(validateGlobalnetEgressIPsCmd)
+}
+
+func validateGlobalnetEgressIPs(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateGlobalnetEgressIPsInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateGlobalnetEgressIPsInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the GlobalEgressIPs of cluster %q", clusterName))
+
+	globalCIDR := getGlobalCIDR(submariner)
+	if globalCIDR == "" {
+		status.QueueSuccessMessage("Globalnet is not enabled")
+		status.End(cli.Success)
+		return true
+	}
+
+	_, network, err := net.ParseCIDR(globalCIDR)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRInvalid, fmt.Sprintf("Error parsing the global CIDR %q: %s",
+			globalCIDR, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	egressIPs, err := clients.submarinerClient.SubmarinerV1().GlobalEgressIPs(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the GlobalEgressIPs: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	// The GlobalEgressIPs selecting each pod, to detect pods whose egress IP is undetermined
+	selectingEgressIPs := map[string]*egressIPSelection{}
+
+	for i := range egressIPs.Items {
+		egressIP := &egressIPs.Items[i]
+		checkGlobalEgressIPAllocation(egressIP, network)
+
+		pods, ok := getGlobalEgressIPPods(clients, egressIP)
+		if !ok {
+			continue
+		}
+
+		for _, pod := range pods {
+			key := egressIP.Namespace + "/" + pod
+			if selectingEgressIPs[key] == nil {
+				selectingEgressIPs[key] = &egressIPSelection{}
+			}
+
+			if egressIP.Spec.PodSelector != nil {
+				selectingEgressIPs[key].podSelectors = append(selectingEgressIPs[key].podSelectors, egressIP.Name)
+			} else {
+				selectingEgressIPs[key].namespaceWide = append(selectingEgressIPs[key].namespaceWide, egressIP.Name)
+			}
+		}
+	}
+
+	pods := make([]string, 0, len(selectingEgressIPs))
+	for pod := range selectingEgressIPs {
+		pods = append(pods, pod)
+	}
+
+	sort.Strings(pods)
+
+	for _, pod := range pods {
+		if conflicting := selectingEgressIPs[pod].conflicting(); len(conflicting) > 1 {
+			status.QueueWarningMessageWithCode(codeGlobalEgressIPConflict, fmt.Sprintf("Pod %q is selected by the"+
+				" GlobalEgressIPs %v, so its egress IP could come from any of them", pod, conflicting))
+		}
+	}
+
+	if len(egressIPs.Items) == 0 {
+		status.QueueSuccessMessage("No GlobalEgressIPs are defined")
+	} else if !status.HasFailureMessages() && !status.HasWarningMessages() {
+		status.QueueSuccessMessage(fmt.Sprintf("All the %d GlobalEgressIPs are allocated within %q and select existing pods",
+			len(egressIPs.Items), globalCIDR))
+	}
+
+	result := status.ResultFromMessages()
+	status.End(result)
+	return result != cli.Failure
+}
+
+// checkGlobalEgressIPAllocation reports a GlobalEgressIP which isn't allocated past the grace period, or whose
+// allocated IPs are outside the cluster's global CIDR or not as many as requested
+func checkGlobalEgressIPAllocation(egressIP *subv1.GlobalEgressIP, network *net.IPNet) {
+	name := egressIP.Namespace + "/" + egressIP.Name
+
+	allocated := meta.FindStatusCondition(egressIP.Status.Conditions, string(subv1.GlobalEgressIPAllocated))
+	if allocated == nil || allocated.Status != metav1.ConditionTrue {
+		if time.Since(egressIP.CreationTimestamp.Time) <= globalnetAllocationGracePeriod {
+			return
+		}
+
+		reason := "globalnet hasn't processed it"
+		if allocated != nil {
+			reason = fmt.Sprintf("%s: %s", allocated.Reason, allocated.Message)
+		}
+
+		status.QueueFailureMessageWithCode(codeGlobalEgressIPUnallocated, fmt.Sprintf("The GlobalEgressIP %q isn't allocated (%s)",
+			name, reason))
+
+		return
+	}
+
+	for _, ip := range egressIP.Status.AllocatedIPs {
+		if parsed := net.ParseIP(ip); parsed == nil || !network.Contains(parsed) {
+			status.QueueFailureMessageWithCode(codeGlobalEgressIPOutsideCIDR, fmt.Sprintf("The GlobalEgressIP %q is allocated"+
+				" %q, outside the cluster's global CIDR %q", name, ip, network))
+		}
+	}
+
+	requested := 1
+	if egressIP.Spec.NumberOfIPs != nil {
+		requested = *egressIP.Spec.NumberOfIPs
+	}
+
+	if len(egressIP.Status.AllocatedIPs) != requested {
+		status.QueueFailureMessageWithCode(codeGlobalEgressIPCountMismatch, fmt.Sprintf("The GlobalEgressIP %q requests %d"+
+			" global IPs but is allocated %d", name, requested, len(egressIP.Status.AllocatedIPs)))
+	}
+}
+
+// egressIPSelection lists the GlobalEgressIPs selecting a pod, by kind
+type egressIPSelection struct {
+	podSelectors  []string
+	namespaceWide []string
+}
+
+// conflicting returns the GlobalEgressIPs which compete to provide the pod's egress IP: a GlobalEgressIP with a pod
+// selector takes precedence over the namespace-wide ones, so only the GlobalEgressIPs of the same kind conflict
+func (s *egressIPSelection) conflicting() []string {
+	if len(s.podSelectors) > 0 {
+		return s.podSelectors
+	}
+
+	return s.namespaceWide
+}
+
+// getGlobalEgressIPPods returns the names of the running pods selected by the GlobalEgressIP, reporting a missing
+// namespace or a pod selector which selects no pods; it returns false if the pods couldn't be determined
+func getGlobalEgressIPPods(clients *clusterClients, egressIP *subv1.GlobalEgressIP) ([]string, bool) {
+	name := egressIP.Namespace + "/" + egressIP.Name
+
+	namespace, err := clients.kubeClient.CoreV1().Namespaces().Get(context.TODO(), egressIP.Namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || (err == nil && namespace.DeletionTimestamp != nil) {
+		status.QueueFailureMessageWithCode(codeGlobalEgressIPOrphaned, fmt.Sprintf("The namespace of the GlobalEgressIP %q no"+
+			" longer exists", name))
+		return nil, false
+	}
+
+	if err != nil {
+		status.QueueWarningMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving the namespace of the"+
+			" GlobalEgressIP %q: %s", name, err))
+		return nil, false
+	}
+
+	selector := ""
+	if egressIP.Spec.PodSelector != nil {
+		parsed, err := metav1.LabelSelectorAsSelector(egressIP.Spec.PodSelector)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeGlobalEgressIPBadSelector, fmt.Sprintf("The GlobalEgressIP %q has an invalid"+
+				" pod selector: %s", name, err))
+			return nil, false
+		}
+
+		selector = parsed.String()
+	}
+
+	pods, err := clients.kubeClient.CoreV1().Pods(egressIP.Namespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		status.QueueWarningMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the pods selected by the"+
+			" GlobalEgressIP %q: %s", name, err))
+		return nil, false
+	}
+
+	names := []string{}
+
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == v1.PodRunning {
+			names = append(names, pods.Items[i].Name)
+		}
+	}
+
+	if egressIP.Spec.PodSelector != nil && len(names) == 0 {
+		status.QueueWarningMessageWithCode(codeGlobalEgressIPSelectsNone, fmt.Sprintf("The pod selector %q of the"+
+			" GlobalEgressIP %q selects no running pods", selector, name))
+	}
+
+	return names, true
+}
@@ -0,0 +1,42 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEgressIPSelectionConflicting(t *testing.T) {
+	tests := []struct {
+		selection egressIPSelection
+		expected  []string
+	}{
+		{egressIPSelection{podSelectors: []string{"pods"}, namespaceWide: []string{"namespace"}}, []string{"pods"}},
+		{egressIPSelection{podSelectors: []string{"pods1", "pods2"}, namespaceWide: []string{"namespace"}},
+			[]string{"pods1", "pods2"}},
+		{egressIPSelection{namespaceWide: []string{"namespace1", "namespace2"}}, []string{"namespace1", "namespace2"}},
+	}
+
+	for i := range tests {
+		if actual := tests[i].selection.conflicting(); !reflect.DeepEqual(actual, tests[i].expected) {
+			t.Errorf("conflicting() returned %v for %+v, expected %v", actual, tests[i].selection, tests[i].expected)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	smClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	mcsv1a1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/resource"
+)
+
+const globalnetFlowCheckPort = 8080
+
+var validateGlobalnetFlowCmd = &cobra.Command{
+	Use:   "globalnet-flow <localkubeconfig> <remotekubeconfig>",
+	Short: "Check that Globalnet translates the traffic to an exported service",
+	Long: "This command exports a test service from the local cluster, waits for Globalnet to allocate its global" +
+		" ingress IP, and connects to that IP from a pod in the remote cluster, checking that the connection reaches" +
+		" the service's pod. The test service, its export and its pods are deleted afterwards.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("two kubeconfigs must be specified")
+		}
+		same, err := compareFiles(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if same {
+			return fmt.Errorf("the specified kubeconfig files are the same")
+		}
+		return nil
+	},
+	Run: validateGlobalnetFlow,
+}
+
+func init() {
+	addValidateFWConfigFlags(validateGlobalnetFlowCmd)
+	validateCmd.AddCommand(validateGlobalnetFlowCmd)
+}
+
+func validateGlobalnetFlow(cmd *cobra.Command, args []string) {
+	localCfg, err := getRestConfig(args[0], "")
+	exitOnError("The provided local kubeconfig is invalid", err)
+
+	remoteCfg, err := getRestConfig(args[1], "")
+	exitOnError("The provided remote kubeconfig is invalid", err)
+
+	finishValidation(validateGlobalnetFlowAcrossClusters(localCfg, remoteCfg))
+}
+
+func validateGlobalnetFlowAcrossClusters(localCfg, remoteCfg *rest.Config) bool {
+	localSubmariner := getSubmarinerResource(localCfg)
+	if localSubmariner == nil {
+		exitWithErrorMsg(submMissingMessage)
+	}
+
+	remoteSubmariner := getSubmarinerResource(remoteCfg)
+	if remoteSubmariner == nil {
+		exitWithErrorMsg(submMissingMessage)
+	}
+
+	status.Start(fmt.Sprintf("Checking that Globalnet translates the traffic from cluster %q to a service exported from"+
+		" cluster %q", remoteSubmariner.Spec.ClusterID, localSubmariner.Spec.ClusterID))
+
+	if skipInReadOnlyMode("Globalnet flow", localSubmariner.Spec.ClusterID) {
+		return true
+	}
+
+	if localSubmariner.Spec.GlobalCIDR == "" || remoteSubmariner.Spec.GlobalCIDR == "" {
+		status.QueueSuccessMessage("This check is only necessary when Globalnet is enabled in both clusters")
+		status.End(cli.Success)
+		return true
+	}
+
+	lClientSet, err := kubernetes.NewForConfig(localCfg)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	rClientSet, err := kubernetes.NewForConfig(remoteCfg)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	lDynClient, err := dynamic.NewForConfig(localCfg)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	lSubmarinerClient, err := smClientset.NewForConfig(localCfg)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Unable to get the Submariner client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	return checkGlobalnetFlow(lClientSet, rClientSet, lDynClient, lSubmarinerClient)
+}
+
+// checkGlobalnetFlow exports a test service from the local cluster and connects to its global ingress IP from the
+// remote cluster
+func checkGlobalnetFlow(lClientSet, rClientSet *kubernetes.Clientset, lDynClient dynamic.Interface,
+	lSubmarinerClient smClientset.Interface) bool {
+	name := "validate-globalnet-" + string(uuid.NewUUID())[:8]
+	message := fmt.Sprintf("globalnet-flow-%s", uuid.NewUUID())
+
+	lPod, err := spawnPod(lClientSet, resource.PodScheduling{ScheduleOn: resource.NonGatewayNode,
+		Networking: resource.PodNetworking}, name, namespace,
+		// The listener also waits for the global ingress IP to be allocated
+		fmt.Sprintf("timeout %d nc -l -p %d", 2*validationTimeout, globalnetFlowCheckPort))
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while spawning the listening pod on a"+
+			" non-Gateway node: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	defer lPod.DeletePod()
+
+	err = exportGlobalnetFlowService(lClientSet, lDynClient, name)
+
+	defer deleteGlobalnetFlowService(lClientSet, lDynClient, name)
+
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCheckServiceFailed, err.Error())
+		status.End(cli.Failure)
+		return false
+	}
+
+	globalIP, err := awaitGlobalnetFlowIngressIP(lSubmarinerClient, name)
+	if err == wait.ErrWaitTimeout {
+		status.QueueFailureMessageWithCode(codeGlobalIngressIPUnallocated, fmt.Sprintf("No global ingress IP was allocated"+
+			" to the test service within %d seconds", validationTimeout))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the GlobalIngressIPs: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("Globalnet allocated the global ingress IP %s to the test service", globalIP))
+
+	cPod, err := spawnClientPodOnNonGatewayNode(rClientSet, namespace,
+		fmt.Sprintf("echo %s | nc -w %d %s %d", message, validationTimeout/2, globalIP, globalnetFlowCheckPort))
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while spawning the client pod on a"+
+			" non-Gateway node: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	defer cPod.DeletePod()
+
+	if err = cPod.AwaitPodCompletion(); err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while waiting for the client pod to"+
+			" finish its execution: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if err = lPod.AwaitPodCompletion(); err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while waiting for the listening pod to"+
+			" finish its execution: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if !strings.Contains(lPod.PodOutput, message) {
+		status.QueueFailureMessageWithCode(codeGlobalnetFlowBroken, fmt.Sprintf("The connection from the remote cluster"+
+			" to the global IP %s of the test service didn't reach the service's pod within %d seconds", globalIP, validationTimeout))
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("The connection from the remote cluster to the global IP %s of the test service"+
+		" reached the service's pod", globalIP))
+	status.End(cli.Success)
+	return true
+}
+
+// exportGlobalnetFlowService creates and exports a service for the listening pod
+func exportGlobalnetFlowService(clientSet kubernetes.Interface, dynClient dynamic.Interface, name string) error {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": name},
+			Ports: []v1.ServicePort{{
+				Port:       globalnetFlowCheckPort,
+				TargetPort: intstr.FromInt(globalnetFlowCheckPort),
+				Protocol:   v1.ProtocolTCP,
+			}},
+		},
+	}
+
+	if _, err := clientSet.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error creating the test service: %s", err)
+	}
+
+	serviceExport := &mcsv1a1.ServiceExport{
+		TypeMeta:   metav1.TypeMeta{APIVersion: mcsv1a1.GroupVersion.String(), Kind: "ServiceExport"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(serviceExport)
+	if err != nil {
+		return fmt.Errorf("error converting the ServiceExport: %s", err)
+	}
+
+	_, err = dynClient.Resource(serviceExportsGVR).Namespace(namespace).Create(context.TODO(),
+		&unstructured.Unstructured{Object: obj}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("error exporting the test service: %s", err)
+	}
+
+	return nil
+}
+
+// awaitGlobalnetFlowIngressIP waits for Globalnet to allocate a global ingress IP to the test service and returns it;
+// it returns wait.ErrWaitTimeout if none was allocated within the validation timeout
+func awaitGlobalnetFlowIngressIP(submarinerClient smClientset.Interface, name string) (string, error) {
+	globalIP := ""
+
+	err := wait.PollImmediate(2*time.Second, time.Duration(validationTimeout)*time.Second, func() (bool, error) {
+		ingressIPs, err := submarinerClient.SubmarinerV1().GlobalIngressIPs(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		for i := range ingressIPs.Items {
+			spec := &ingressIPs.Items[i].Spec
+			if spec.Target == subv1.ClusterIPService && spec.ServiceRef != nil && spec.ServiceRef.Name == name {
+				globalIP = ingressIPs.Items[i].Status.AllocatedIP
+			}
+		}
+
+		return globalIP != "", nil
+	})
+
+	return globalIP, err
+}
+
+func deleteGlobalnetFlowService(clientSet kubernetes.Interface, dynClient dynamic.Interface, name string) {
+	_ = dynClient.Resource(serviceExportsGVR).Namespace(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	_ = clientSet.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+}
@@ -0,0 +1,145 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/discovery/globalnet"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateGlobalnetSettingsCmd = &cobra.Command{
+	Use:   "globalnet-settings",
+	Short: "Check that the clusters joined with the broker's current globalnet settings",
+	Long: "This command checks that the globalnet settings each cluster was joined with match the broker's globalnet" +
+		" ConfigMap: whether globalnet is enabled, the global CIDR range and the default cluster size. A mismatch" +
+		" usually means the cluster was joined with a stale broker-info file, e.g. after the broker's global CIDR" +
+		" range changed. It requires access to the broker cluster.",
+	Run: validateGlobalnetSettings,
+}
+
+func init() {
+	addBrokerFlags(validateGlobalnetSettingsCmd)
+	validateCmd.AddCommand(validateGlobalnetSettingsCmd)
+}
+
+func validateGlobalnetSettings(cmd *cobra.Command, args []string) {
+	brokerConfig, err := getRestConfig(kubeConfig, brokerContext)
+	exitOnError("Error getting REST config for the broker cluster", err)
+
+	brokerClientSet, err := kubernetes.NewForConfig(brokerConfig)
+	exitOnError("Error creating the broker API server client", err)
+
+	globalnetInfo, _, err := globalnet.ReadGlobalNetworks(brokerClientSet, diagnoseBrokerNamespace)
+	exitOnError("Error reading the globalnet ConfigMap from the broker", err)
+
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+		validationStatus = validateGlobalnetSettingsInCluster(item.clusterName, submariner, globalnetInfo) &&
+			validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+// validateGlobalnetSettingsInCluster compares the globalnet settings the cluster was joined with, as reflected by its
+// global CIDR, with the broker's live globalnet settings; the allocation itself is checked by globalnet-allocation
+func validateGlobalnetSettingsInCluster(clusterName string, submariner *v1alpha1.Submariner,
+	globalnetInfo *globalnet.GlobalnetInfo) bool {
+	status.Start(fmt.Sprintf("Checking the globalnet settings cluster %q was joined with", clusterName))
+
+	clusterID := submariner.Spec.ClusterID
+	globalCIDR := submariner.Spec.GlobalCIDR
+
+	switch {
+	case globalnetInfo.GlobalnetEnabled && globalCIDR == "":
+		status.QueueFailureMessageWithCode(codeGlobalnetSettingsMismatch, fmt.Sprintf("Globalnet is enabled on the"+
+			" broker but cluster %q was joined without it", clusterID))
+	case !globalnetInfo.GlobalnetEnabled && globalCIDR != "":
+		status.QueueFailureMessageWithCode(codeGlobalnetSettingsMismatch, fmt.Sprintf("Globalnet is disabled on the"+
+			" broker but cluster %q was joined with the global CIDR %q", clusterID, globalCIDR))
+	case globalCIDR != "":
+		checkGlobalCIDRSettings(clusterID, globalCIDR, globalnetInfo)
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("The cluster was joined with the broker's current globalnet settings")
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+// checkGlobalCIDRSettings checks that the global CIDR of the cluster lies within the broker's global CIDR range and
+// has the broker's default cluster size; the size can be overridden when joining, so a different size is only a warning
+func checkGlobalCIDRSettings(clusterID, globalCIDR string, globalnetInfo *globalnet.GlobalnetInfo) {
+	_, clusterNet, err := net.ParseCIDR(globalCIDR)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRInvalid, fmt.Sprintf("The global CIDR %q of cluster %q is invalid: %s",
+			globalCIDR, clusterID, err))
+		return
+	}
+
+	_, rangeNet, err := net.ParseCIDR(globalnetInfo.GlobalnetCidrRange)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeGlobalnetConfigMapMalformed, fmt.Sprintf("The broker's global CIDR range %q is"+
+			" invalid: %s",
+			globalnetInfo.GlobalnetCidrRange, err))
+		return
+	}
+
+	clusterOnes, bits := clusterNet.Mask.Size()
+	rangeOnes, _ := rangeNet.Mask.Size()
+
+	if !rangeNet.Contains(clusterNet.IP) || clusterOnes < rangeOnes {
+		status.QueueFailureMessageWithCode(codeGlobalnetSettingsMismatch, fmt.Sprintf("The global CIDR %q of cluster"+
+			" %q is outside the broker's global CIDR range %q; the cluster was probably joined with a stale"+
+			" broker-info file", globalCIDR, clusterID, globalnetInfo.GlobalnetCidrRange))
+		return
+	}
+
+	clusterSize := uint(1) << uint(bits-clusterOnes)
+	if globalnetInfo.GlobalnetClusterSize != 0 && clusterSize != globalnetInfo.GlobalnetClusterSize {
+		status.QueueWarningMessageWithCode(codeGlobalnetSettingsMismatch, fmt.Sprintf("The global CIDR %q of cluster"+
+			" %q has %d addresses but the broker's default cluster size is %d; this is expected if the cluster size"+
+			" was overridden when joining", globalCIDR, clusterID, clusterSize, globalnetInfo.GlobalnetClusterSize))
+	}
+}
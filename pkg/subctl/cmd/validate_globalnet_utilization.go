@@ -0,0 +1,183 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	smClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateGlobalnetUtilizationCmd = &cobra.Command{
+	Use:   "globalnet-utilization",
+	Short: "Check that the global IPs of the clusters aren't close to exhaustion",
+	Long: "This command computes the share of each cluster's global CIDR allocated to GlobalIngressIPs, GlobalEgressIPs" +
+		" and ClusterGlobalEgressIPs, and warns when it exceeds the threshold.",
+	Run: validateGlobalnetUtilization,
+}
+
+var globalnetUtilizationThreshold int
+
+func init() {
+	addGlobalnetUtilizationFlags(validateGlobalnetUtilizationCmd)
+	validateCmd.AddCommand(validateGlobalnetUtilizationCmd)
+}
+
+func addGlobalnetUtilizationFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&globalnetUtilizationThreshold, "utilization-threshold", 80,
+		"percentage of a cluster's global CIDR allocated beyond which a warning is raised")
+}
+
+func validateGlobalnetUtilization(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateGlobalnetUtilizationInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateGlobalnetUtilizationInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the utilization of the global CIDR of cluster %q", clusterName))
+
+	globalCIDR := getGlobalCIDR(submariner)
+	if globalCIDR == "" {
+		status.QueueSuccessMessage("Globalnet is not enabled")
+		status.End(cli.Success)
+		return true
+	}
+
+	size, err := globalCIDRSize(globalCIDR)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRInvalid, fmt.Sprintf("Error parsing the global CIDR %q: %s", globalCIDR, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	submarinerClient := clients.submarinerClient
+
+	allocated, err := getAllocatedGlobalIPs(submarinerClient)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the allocated global IPs: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	utilization := int64(allocated.Size()) * 100 / size
+	message := fmt.Sprintf("%d of the %d global IPs of %q are allocated (%d%%)", allocated.Size(), size, globalCIDR, utilization)
+
+	if utilization >= int64(globalnetUtilizationThreshold) {
+		status.QueueWarningMessageWithCode(codeGlobalIPsNearlyExhausted,
+			message+fmt.Sprintf(", above the %d%% threshold; new exports and egress IPs will fail"+
+				" once they are exhausted", globalnetUtilizationThreshold))
+		status.End(cli.Warning)
+		return true
+	}
+
+	status.QueueSuccessMessage(message)
+	status.End(cli.Success)
+	return true
+}
+
+// globalCIDRSize returns the number of IPs in the global CIDR; globalnet only allocates IPv4 addresses, so the other
+// ranges are rejected
+func globalCIDRSize(globalCIDR string) (int64, error) {
+	_, network, err := net.ParseCIDR(globalCIDR)
+	if err != nil {
+		return 0, err
+	}
+
+	if network.IP.To4() == nil {
+		return 0, fmt.Errorf("globalnet only supports IPv4 CIDRs")
+	}
+
+	ones, bits := network.Mask.Size()
+	return int64(1) << uint(bits-ones), nil
+}
+
+// getAllocatedGlobalIPs returns the global IPs allocated to the GlobalIngressIPs, GlobalEgressIPs and
+// ClusterGlobalEgressIPs of the cluster
+func getAllocatedGlobalIPs(submarinerClient smClientset.Interface) (stringset.Interface, error) {
+	allocated := stringset.New()
+
+	clusterEgressIPs, err := submarinerClient.SubmarinerV1().ClusterGlobalEgressIPs(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range clusterEgressIPs.Items {
+		for _, ip := range clusterEgressIPs.Items[i].Status.AllocatedIPs {
+			allocated.Add(ip)
+		}
+	}
+
+	egressIPs, err := submarinerClient.SubmarinerV1().GlobalEgressIPs(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range egressIPs.Items {
+		for _, ip := range egressIPs.Items[i].Status.AllocatedIPs {
+			allocated.Add(ip)
+		}
+	}
+
+	ingressIPs, err := submarinerClient.SubmarinerV1().GlobalIngressIPs(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ingressIPs.Items {
+		if ingressIPs.Items[i].Status.AllocatedIP != "" {
+			allocated.Add(ingressIPs.Items[i].Status.AllocatedIP)
+		}
+	}
+
+	return allocated, nil
+}
@@ -0,0 +1,49 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import "testing"
+
+func TestGlobalCIDRSize(t *testing.T) {
+	tests := []struct {
+		globalCIDR string
+		size       int64
+		valid      bool
+	}{
+		{globalCIDR: "242.0.0.0/16", size: 65536, valid: true},
+		{globalCIDR: "242.0.0.1/32", size: 1, valid: true},
+		{globalCIDR: "0.0.0.0/0", size: 1 << 32, valid: true},
+		{globalCIDR: "fd00::/64"},
+		{globalCIDR: "::/0"},
+		{globalCIDR: "242.0.0.0"},
+	}
+
+	for i := range tests {
+		test := &tests[i]
+
+		size, err := globalCIDRSize(test.globalCIDR)
+		if test.valid && (err != nil || size != test.size) {
+			t.Errorf("Expected %q to hold %d IPs, got %d, %v", test.globalCIDR, test.size, size, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("Expected %q to be rejected, got %d IPs", test.globalCIDR, size)
+		}
+	}
+}
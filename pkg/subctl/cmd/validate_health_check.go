@@ -0,0 +1,179 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	HealthCheckPingCommand = "ping -c 3 -W 2 %s"
+)
+
+var validateHealthCheckCmd = &cobra.Command{
+	Use:   "health-check",
+	Short: "Check the Gateway health check configuration",
+	Long: "This command checks that the Endpoints have a health check IP and that the remote health check IPs" +
+		" are reachable across the tunnels from the active Gateway.",
+	Run: validateHealthCheck,
+}
+
+func init() {
+	validateCmd.AddCommand(validateHealthCheckCmd)
+}
+
+func validateHealthCheck(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateHealthCheckInCluster(item.config, clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateHealthCheckInCluster(config *rest.Config, clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the health check IPs of the Endpoints in cluster %q", clusterName))
+
+	if skipInReadOnlyMode("health check", clusterName) {
+		return true
+	}
+
+	if submariner.Spec.ConnectionHealthCheck != nil && !submariner.Spec.ConnectionHealthCheck.Enabled {
+		status.QueueSuccessMessage("This check is not necessary as the connection health check is disabled")
+		status.End(cli.Success)
+		return true
+	}
+
+	submarinerClient := clients.submarinerClient
+
+	clientSet := clients.kubeClient
+
+	endpoints, err := submarinerClient.SubmarinerV1().Endpoints(OperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner endpoints: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	var localEndpoint *subv1.Endpoint
+
+	for i := range endpoints.Items {
+		endpoint := &endpoints.Items[i]
+		if endpoint.Spec.ClusterID == submariner.Spec.ClusterID {
+			localEndpoint = endpoint
+		}
+
+		if endpoint.Spec.HealthCheckIP == "" {
+			status.QueueFailureMessageWithCode(codeHealthCheckIPMissing, fmt.Sprintf("The Endpoint %q for cluster %q has no"+
+				" health check IP",
+				endpoint.Name, endpoint.Spec.ClusterID))
+		}
+	}
+
+	if localEndpoint == nil {
+		status.QueueFailureMessageWithCode(codeLocalEndpointMissing, fmt.Sprintf("Could not find the local Endpoint for"+
+			" cluster %q", submariner.Spec.ClusterID))
+		status.End(cli.Failure)
+		return false
+	}
+
+	gatewayPod, err := getGatewayPodOnNode(clientSet, getActiveGatewayNodeName(clientSet, localEndpoint.Spec.Hostname))
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeGatewayPodUnavailable, err.Error())
+		status.End(cli.Failure)
+		return false
+	}
+
+	for i := range endpoints.Items {
+		endpoint := &endpoints.Items[i]
+		if endpoint.Spec.ClusterID == submariner.Spec.ClusterID || endpoint.Spec.HealthCheckIP == "" {
+			continue
+		}
+
+		command := fmt.Sprintf(HealthCheckPingCommand, endpoint.Spec.HealthCheckIP)
+		if _, err := execInPod(config, clientSet, gatewayPod, command); err != nil {
+			status.QueueFailureMessageWithCode(codeHealthCheckUnreachable, fmt.Sprintf("The health check IP %q of the"+
+				" Endpoint for cluster %q is not reachable from the Gateway pod %q: %s", endpoint.Spec.HealthCheckIP,
+				endpoint.Spec.ClusterID, gatewayPod.Name, err))
+		}
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage("All the Endpoints have reachable health check IPs")
+	status.End(cli.Success)
+	return true
+}
+
+func getGatewayPodOnNode(clientSet kubernetes.Interface, nodeName string) (*v1.Pod, error) {
+	if nodeName == "" {
+		return nil, fmt.Errorf("could not determine the active Gateway node")
+	}
+
+	pods, err := clientSet.CoreV1().Pods(OperatorNamespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: "app=submariner-gateway",
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing the Gateway pods: %s", err)
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == v1.PodRunning {
+			return &pods.Items[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no running Gateway pod found on node %q", nodeName)
+}
@@ -0,0 +1,265 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/images"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	"github.com/submariner-io/submariner-operator/pkg/versions"
+)
+
+const (
+	imagePullCheckName = "submariner-image-pull-check"
+	// The command run by the image pull check containers; it doesn't exist in the images so that nothing is run
+	// once the images are pulled
+	imagePullCheckCommand = "/submariner-image-pull-check"
+)
+
+// Waiting reasons reported by the kubelet when an image can't be pulled
+var imagePullFailureReasons = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+var (
+	imagesRepository  string
+	imagesVersion     string
+	imagesPullTimeout time.Duration
+)
+
+var validateImagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Check that the Submariner images can be pulled",
+	Long: "This command checks that the cluster can pull the images used by the Submariner components, by running" +
+		" a pod using them. It can be run before deploying Submariner to validate access to the registry.",
+	Run: validateImages,
+}
+
+func init() {
+	validateImagesCmd.Flags().StringVar(&imagesRepository, "repository", "",
+		"image repository to check, defaults to the one configured in the Submariner resource")
+	validateImagesCmd.Flags().StringVar(&imagesVersion, "version", "",
+		"image version to check, defaults to the one configured in the Submariner resource")
+	validateImagesCmd.Flags().DurationVar(&imagesPullTimeout, "pull-timeout", 2*time.Minute,
+		"maximum time to wait for the images to be pulled")
+	validateImagesCmd.Flags().StringVar(&namespace, "namespace", "default",
+		"namespace in which validation pods should be deployed")
+	validateCmd.AddCommand(validateImagesCmd)
+}
+
+func validateImages(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		// Without a Submariner resource, the default images are checked
+		submariner, err := retrieveSubmariner(item.config)
+		if err != nil {
+			status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		validationStatus = validateImagesInCluster(item.config, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+// getComponentImages returns the images of the enabled components, indexed by component name. Without a Submariner
+// resource, e.g. before deploying, the default images of the core components are used.
+func getComponentImages(submariner *v1alpha1.Submariner) map[string]string {
+	spec := v1alpha1.SubmarinerSpec{Repository: versions.DefaultRepo, Version: versions.DefaultSubmarinerVersion}
+	if submariner != nil {
+		spec = submariner.Spec
+	}
+
+	if imagesRepository != "" {
+		spec.Repository = imagesRepository
+	}
+
+	if imagesVersion != "" {
+		spec.Version = imagesVersion
+	}
+
+	components := map[string]string{
+		names.GatewayComponent:    names.GatewayImage,
+		names.RouteAgentComponent: names.RouteAgentImage,
+	}
+
+	if spec.GlobalCIDR != "" {
+		components[names.GlobalnetComponent] = names.GlobalnetImage
+	}
+
+	if spec.ServiceDiscoveryEnabled {
+		components[names.ServiceDiscoveryComponent] = names.ServiceDiscoveryImage
+		components[names.LighthouseCoreDNSComponent] = names.LighthouseCoreDNSImage
+	}
+
+	componentImages := map[string]string{}
+	for component, image := range components {
+		componentImages[component] = images.GetImagePath(spec.Repository, spec.Version, image, component, spec.ImageOverrides)
+	}
+
+	return componentImages
+}
+
+func validateImagesInCluster(config *rest.Config, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking that the Submariner images can be pulled in cluster %q", clusterName))
+
+	if skipInReadOnlyMode("image pull", clusterName) {
+		return true
+	}
+
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: imagePullCheckName,
+			Labels:       map[string]string{"app": imagePullCheckName},
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			Tolerations:   []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			NodeSelector:  diagnosePodNodeSelector,
+		},
+	}
+
+	if len(diagnosePodTolerations) > 0 {
+		pod.Spec.Tolerations = diagnosePodTolerations
+	}
+
+	for component, image := range getComponentImages(submariner) {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{
+			Name:            component,
+			Image:           image,
+			ImagePullPolicy: v1.PullAlways,
+			Command:         []string{imagePullCheckCommand},
+		})
+	}
+
+	pods := clientSet.CoreV1().Pods(namespace)
+
+	pod, err = pods.Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error creating the image pull check pod: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	defer func() {
+		_ = pods.Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})
+	}()
+
+	var containerStatuses []v1.ContainerStatus
+
+	err = wait.PollImmediate(2*time.Second, imagesPullTimeout, func() (bool, error) {
+		current, err := pods.Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+
+		containerStatuses = current.Status.ContainerStatuses
+
+		return len(containerStatuses) == len(pod.Spec.Containers) && allImagePullsResolved(containerStatuses), nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error obtaining the image pull check pod: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	for i := range pod.Spec.Containers {
+		checkImagePull(&pod.Spec.Containers[i], containerStatuses)
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("All the %d Submariner images can be pulled", len(pod.Spec.Containers)))
+	status.End(cli.Success)
+	return true
+}
+
+// allImagePullsResolved returns true once every container has either pulled its image or failed to
+func allImagePullsResolved(containerStatuses []v1.ContainerStatus) bool {
+	for i := range containerStatuses {
+		if !imagePulled(&containerStatuses[i]) && !imagePullFailed(&containerStatuses[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func imagePulled(containerStatus *v1.ContainerStatus) bool {
+	return containerStatus.ImageID != "" || containerStatus.State.Running != nil || containerStatus.State.Terminated != nil
+}
+
+func imagePullFailed(containerStatus *v1.ContainerStatus) bool {
+	return containerStatus.State.Waiting != nil && imagePullFailureReasons[containerStatus.State.Waiting.Reason]
+}
+
+func checkImagePull(container *v1.Container, containerStatuses []v1.ContainerStatus) {
+	for i := range containerStatuses {
+		containerStatus := &containerStatuses[i]
+		if containerStatus.Name != container.Name {
+			continue
+		}
+
+		if imagePullFailed(containerStatus) {
+			status.QueueFailureMessageWithCode(codeImagePullFailed, fmt.Sprintf("The image %q can't be pulled: %s", container.Image,
+				containerStatus.State.Waiting.Message))
+		} else if !imagePulled(containerStatus) {
+			status.QueueFailureMessageWithCode(codeImagePullTimeout, fmt.Sprintf("The image %q wasn't pulled within %v",
+				container.Image, imagesPullTimeout))
+		}
+
+		return
+	}
+
+	status.QueueFailureMessageWithCode(codeImagePullTimeout, fmt.Sprintf("The image %q wasn't pulled within %v",
+		container.Image, imagesPullTimeout))
+}
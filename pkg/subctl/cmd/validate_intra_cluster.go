@@ -0,0 +1,131 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/resource"
+)
+
+const intraClusterListenerPort = 8081
+
+var validateIntraClusterCmd = &cobra.Command{
+	Use:   "intra-cluster",
+	Short: "Check the pod-to-pod connectivity within the cluster",
+	Long: "This command checks that a pod on a non-Gateway node can reach a pod on the Gateway node. If it can't," +
+		" the cluster's own networking must be fixed before looking into the connectivity between the clusters.",
+	Run: validateIntraCluster,
+}
+
+var checkIntraClusterFirst bool
+
+func init() {
+	addValidateFWConfigFlags(validateIntraClusterCmd)
+	validateCmd.AddCommand(validateIntraClusterCmd)
+}
+
+func validateIntraCluster(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		validationStatus = validateIntraClusterConnectivity(item.config, item.clusterName) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateIntraClusterConnectivity(config *rest.Config, clusterName string) bool {
+	status.Start(fmt.Sprintf("Checking the pod-to-pod connectivity within cluster %q", clusterName))
+
+	if skipInReadOnlyMode("intra-cluster connectivity", clusterName) {
+		return true
+	}
+
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	clientMessage := string(uuid.NewUUID())[0:8]
+
+	scheduling := resource.PodScheduling{ScheduleOn: resource.GatewayNode, Networking: resource.PodNetworking}
+	lPod, err := spawnPod(clientSet, scheduling, "validate-listener", namespace,
+		fmt.Sprintf("timeout %d nc -l -p %d", validationTimeout, intraClusterListenerPort))
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while spawning the listener pod on"+
+			" the Gateway node: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	defer lPod.DeletePod()
+
+	podCommand := fmt.Sprintf("for i in $(seq 10); do echo %s | nc -w 2 %s %d && break; sleep 1; done", clientMessage,
+		lPod.Pod.Status.PodIP, intraClusterListenerPort)
+	cPod, err := spawnClientPodOnNonGatewayNode(clientSet, namespace, podCommand)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while spawning the client pod on"+
+			" a non-Gateway node: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	defer cPod.DeletePod()
+
+	if err = cPod.AwaitPodCompletion(); err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while waiting for the client pod"+
+			" to finish its execution: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if err = lPod.AwaitPodCompletion(); err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while waiting for the listener pod"+
+			" to finish its execution: %v", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if !strings.Contains(lPod.PodOutput, clientMessage) {
+		status.QueueFailureMessageWithCode(codeIntraClusterBroken, fmt.Sprintf("A pod on a non-Gateway node could not reach a"+
+			" pod on the Gateway node (%s) in cluster %q; the cluster's CNI must be fixed before diagnosing the connectivity"+
+			" between the clusters", lPod.Pod.Spec.NodeName, clusterName))
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage("Pods on different nodes can reach each other")
+	status.End(cli.Success)
+	return true
+}
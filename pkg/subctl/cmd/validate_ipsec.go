@@ -0,0 +1,220 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	XfrmStateCommand = "ip xfrm state"
+)
+
+// The kernel (xfrm) names of the FIPS 140-2 approved algorithms usable for IPsec
+var defaultAllowedIPsecCiphers = []string{
+	"rfc4106(gcm(aes))",
+	"gcm(aes)",
+	"cbc(aes)",
+	"hmac(sha1)",
+	"hmac(sha256)",
+	"hmac(sha384)",
+	"hmac(sha512)",
+}
+
+var (
+	allowedIPsecCiphers     []string
+	allowedIPsecCiphersFile string
+)
+
+var validateIPsecCiphersCmd = &cobra.Command{
+	Use:   "ipsec-ciphers",
+	Short: "Check the IPsec ciphers used by the Gateway",
+	Long: "This command checks that the ciphers negotiated for the IPsec tunnels on the Gateway nodes are within" +
+		" an allowed list, by default the FIPS-approved ciphers.",
+	Run: validateIPsecCiphers,
+}
+
+func init() {
+	addIPsecCiphersFlags(validateIPsecCiphersCmd)
+	validateCmd.AddCommand(validateIPsecCiphersCmd)
+}
+
+func addIPsecCiphersFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&allowedIPsecCiphers, "allowed-ciphers", defaultAllowedIPsecCiphers,
+		"comma-separated list of the allowed IPsec ciphers, using their kernel (xfrm) names")
+	cmd.Flags().StringVar(&allowedIPsecCiphersFile, "allowed-ciphers-file", "",
+		"file containing the allowed IPsec ciphers, one per line; overrides --allowed-ciphers")
+}
+
+func validateIPsecCiphers(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateIPsecCiphersInCluster(item.config, clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateIPsecCiphersInCluster(config *rest.Config, clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the IPsec ciphers used by the Gateway in cluster %q", clusterName))
+
+	if skipInReadOnlyMode("IPsec ciphers", clusterName) {
+		return true
+	}
+
+	if submariner.Spec.CableDriver != "" && submariner.Spec.CableDriver != "libreswan" {
+		status.QueueSuccessMessage(fmt.Sprintf("This check is not necessary for the %q cable driver",
+			submariner.Spec.CableDriver))
+		status.End(cli.Success)
+		return true
+	}
+
+	allowed, err := getAllowedIPsecCiphers()
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeInputInvalid, fmt.Sprintf("Error reading the allowed ciphers: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	clientSet := clients.kubeClient
+
+	pods, err := clientSet.CoreV1().Pods(OperatorNamespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: "app=submariner-gateway"})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Gateway pods: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	running := runningPods(pods.Items)
+	outputs := make([]string, len(running))
+	errs := make([]error, len(running))
+
+	probeConcurrently(len(running), func(i int) {
+		outputs[i], errs[i] = execInPod(config, clientSet, running[i], XfrmStateCommand)
+	})
+
+	for i, pod := range running {
+		if errs[i] != nil {
+			status.QueueFailureMessageWithCode(codeNodeProbeFailed, fmt.Sprintf("Error running %q on pod %q: %s", XfrmStateCommand, pod.Name,
+				errs[i]))
+			continue
+		}
+
+		ciphers := parseXfrmCiphers(outputs[i])
+		if len(ciphers) == 0 {
+			status.QueueWarningMessageWithCode(codeIPsecNoSecurityAssociations, fmt.Sprintf("No IPsec security associations"+
+				" found on pod %q", pod.Name))
+			continue
+		}
+
+		for _, cipher := range ciphers {
+			if !allowed.Contains(cipher) {
+				status.QueueWarningMessageWithCode(codeIPsecCipherNonCompliant, fmt.Sprintf("The Gateway pod %q on node %q"+
+					" uses the non-compliant cipher %q", pod.Name, pod.Spec.NodeName, cipher))
+			}
+		}
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("The IPsec ciphers in use are all in the allowed list")
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+func getAllowedIPsecCiphers() (stringset.Interface, error) {
+	if allowedIPsecCiphersFile == "" {
+		return stringset.New(allowedIPsecCiphers...), nil
+	}
+
+	contents, err := ioutil.ReadFile(allowedIPsecCiphersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := stringset.New()
+	for _, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			allowed.Add(line)
+		}
+	}
+
+	return allowed, nil
+}
+
+// parseXfrmCiphers returns the sorted list of the encryption and authentication algorithms
+// found in the output of "ip xfrm state"
+func parseXfrmCiphers(output string) []string {
+	ciphers := stringset.New()
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "aead", "enc", "auth", "auth-trunc":
+			ciphers.Add(fields[1])
+		}
+	}
+
+	elements := ciphers.Elements()
+	sort.Strings(elements)
+
+	return elements
+}
@@ -19,10 +19,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/rest"
 
 	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
 )
@@ -45,30 +43,39 @@ func validateK8sVersion(cmd *cobra.Command, args []string) {
 	validationStatus := true
 
 	for _, item := range configs {
-		validationStatus = validationStatus && validateK8sVersionInCluster(item.config, item.clusterName)
-	}
-	if !validationStatus {
-		os.Exit(1)
+		status.SetCluster(item.clusterName)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateK8sVersionInCluster(clients, item.clusterName) && validationStatus
 	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
 }
 
-func validateK8sVersionInCluster(config *rest.Config, clusterName string) bool {
+func validateK8sVersionInCluster(clients *clusterClients, clusterName string) bool {
 	message := fmt.Sprintf("Checking Submariner support for the Kubernetes version"+
 		" used in cluster %q", clusterName)
 	status.Start(message)
 
-	failedRequirements, err := checkRequirements(config)
+	failedRequirements, err := checkRequirementsWithClient(clients.kubeClient)
 	if len(failedRequirements) > 0 {
-		status.QueueFailureMessage("The Kubernetes version does not meet Submariner's requirements:")
+		status.QueueFailureMessageWithCode(codeK8sVersionUnsupported, "The Kubernetes version does not meet Submariner's requirements:")
 		for i := range failedRequirements {
 			message = fmt.Sprintf("* %s\n", (failedRequirements)[i])
-			status.QueueFailureMessage(message)
+			status.QueueFailureMessageWithCode(codeK8sVersionUnsupported, message)
 		}
 		status.End(cli.Failure)
 		return false
 	}
 	if err != nil {
-		status.QueueFailureMessage(err.Error())
+		status.QueueFailureMessageWithCode(codeK8sVersionUnknown, err.Error())
 		status.End(cli.Failure)
 		return false
 	}
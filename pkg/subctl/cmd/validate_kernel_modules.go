@@ -0,0 +1,190 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	defaultCableDriver = "libreswan"
+
+	// Prints "missing <module>" for each module, given as arguments, which isn't loaded or built into the kernel
+	missingKernelModulesCommand = "for module in %s; do [ -d /sys/module/$module ] || echo \"missing $module\"; done"
+)
+
+// requiredKernelModules lists the kernel modules each cable driver needs on the gateway nodes
+var requiredKernelModules = map[string][]string{
+	defaultCableDriver:   {"xfrm_user", "esp4"},
+	"vxlan":              {"vxlan"},
+	wireGuardCableDriver: {"wireguard"},
+}
+
+var validateKernelModulesCmd = &cobra.Command{
+	Use:   "kernel-modules",
+	Short: "Check that the gateway nodes have the kernel modules needed by the cable driver",
+	Long: "This command runs a pod on each gateway node to check that the kernel modules needed by the configured" +
+		" cable driver, such as xfrm_user and esp4 for IPsec, vxlan or wireguard, are loaded. Without them the" +
+		" tunnels between the clusters can't be established.",
+	Run: validateKernelModules,
+}
+
+func init() {
+	addValidateFWConfigFlags(validateKernelModulesCmd)
+	validateCmd.AddCommand(validateKernelModulesCmd)
+}
+
+func validateKernelModules(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateKernelModulesInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateKernelModulesInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the kernel modules on the gateway nodes of cluster %q", clusterName))
+
+	if skipInReadOnlyMode("kernel modules", clusterName) {
+		return true
+	}
+
+	cableDriver := submariner.Spec.CableDriver
+	if cableDriver == "" {
+		cableDriver = defaultCableDriver
+	}
+
+	modules, ok := requiredKernelModules[cableDriver]
+	if !ok {
+		status.QueueWarningMessageWithCode(codeCableDriverUnknown, fmt.Sprintf("The kernel modules needed by the %q cable"+
+			" driver are unknown", cableDriver))
+		status.End(cli.Warning)
+		return true
+	}
+
+	clientSet := clients.kubeClient
+
+	nodes, err := clientSet.CoreV1().Nodes().List(context.TODO(),
+		metav1.ListOptions{LabelSelector: "submariner.io/gateway=true"})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the gateway nodes: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if len(nodes.Items) == 0 {
+		status.QueueFailureMessageWithCode(codeNoGateway, "There are no gateway nodes")
+		status.End(cli.Failure)
+		return false
+	}
+
+	missing := make([][]string, len(nodes.Items))
+	errs := make([]error, len(nodes.Items))
+
+	probeConcurrently(len(nodes.Items), func(i int) {
+		missing[i], errs[i] = findMissingKernelModules(clientSet, nodes.Items[i].Name, modules)
+	})
+
+	for i := range nodes.Items {
+		nodeName := nodes.Items[i].Name
+
+		if errs[i] != nil {
+			status.QueueWarningMessageWithCode(codeNodeProbeFailed, fmt.Sprintf("Unable to check the kernel modules on node %q: %s", nodeName,
+				errs[i]))
+			continue
+		}
+
+		if len(missing[i]) > 0 {
+			status.QueueFailureMessageWithCode(codeKernelModulesMissing,
+				fmt.Sprintf("The kernel modules %v needed by the %q cable driver aren't loaded on"+
+					" gateway node %q", missing[i], cableDriver, nodeName))
+		}
+	}
+
+	if !status.HasFailureMessages() && !status.HasWarningMessages() {
+		status.QueueSuccessMessage(fmt.Sprintf("The kernel modules %v needed by the %q cable driver are loaded on all"+
+			" the gateway nodes", modules, cableDriver))
+	}
+
+	result := status.ResultFromMessages()
+	status.End(result)
+	return result != cli.Failure
+}
+
+// findMissingKernelModules runs a pod on the node to list the given kernel modules which aren't loaded
+func findMissingKernelModules(clientSet kubernetes.Interface, nodeName string, modules []string) ([]string, error) {
+	sPod, err := spawnSnifferPodOnNode(clientSet, nodeName, namespace,
+		fmt.Sprintf(missingKernelModulesCommand, strings.Join(modules, " ")))
+	if err != nil {
+		return nil, fmt.Errorf("error while spawning the pod: %v", err)
+	}
+
+	defer sPod.DeletePod()
+
+	if err = sPod.AwaitPodCompletion(); err != nil {
+		return nil, fmt.Errorf("error while waiting for the pod to finish its execution: %v", err)
+	}
+
+	return parseMissingKernelModules(sPod.PodOutput), nil
+}
+
+// parseMissingKernelModules returns the modules reported as missing in the output of missingKernelModulesCommand
+func parseMissingKernelModules(output string) []string {
+	missing := []string{}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "missing ") {
+			missing = append(missing, strings.TrimPrefix(line, "missing "))
+		}
+	}
+
+	return missing
+}
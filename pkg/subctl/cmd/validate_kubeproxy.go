@@ -18,14 +18,15 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
+	"github.com/submariner-io/submariner/pkg/routeagent_driver/constants"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
 	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
 	"github.com/submariner-io/submariner-operator/pkg/subctl/resource"
 )
@@ -46,10 +47,22 @@ var validateKubeProxyModeCmd = &cobra.Command{
 	Run:   validateKubeProxyMode,
 }
 
+var validateKubeProxyPresenceCmd = &cobra.Command{
+	Use:   "kube-proxy-presence",
+	Short: "Check whether kube-proxy is running",
+	Long: "This command checks whether kube-proxy is running and whether that is expected with the detected CNI" +
+		" network plugin.",
+	Run: validateKubeProxyPresence,
+}
+
+// The network plugins which handle services themselves, so that kube-proxy isn't needed
+var kubeProxyReplacingNetworkPlugins = []string{constants.NetworkPluginOVNKubernetes, constants.NetworkPluginOpenShiftSDN}
+
 func init() {
 	validateKubeProxyModeCmd.Flags().StringVar(&namespace, "namespace", "default",
 		"namespace in which validation pods should be deployed")
 	validateCmd.AddCommand(validateKubeProxyModeCmd)
+	validateCmd.AddCommand(validateKubeProxyPresenceCmd)
 }
 
 func validateKubeProxyMode(cmd *cobra.Command, args []string) {
@@ -59,39 +72,45 @@ func validateKubeProxyMode(cmd *cobra.Command, args []string) {
 	validationStatus := true
 
 	for _, item := range configs {
-		validationStatus = validationStatus && validateKubeProxyModeInCluster(item.config, item.clusterName)
-	}
+		status.SetCluster(item.clusterName)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
 
-	if !validationStatus {
-		os.Exit(1)
+		validationStatus = validateKubeProxyModeInCluster(clients, item.clusterName) && validationStatus
 	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
 }
 
-func validateKubeProxyModeInCluster(config *rest.Config, clusterName string) bool {
+func validateKubeProxyModeInCluster(clients *clusterClients, clusterName string) bool {
 	message := fmt.Sprintf("Checking Submariner support for the kube-proxy mode"+
 		" used in cluster %q", clusterName)
 	status.Start(message)
 
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		message := fmt.Sprintf("Error creating API server client: %s", err)
-		status.QueueFailureMessage(message)
-		status.End(cli.Failure)
-		return false
+	if skipInReadOnlyMode("kube-proxy mode", clusterName) {
+		return true
 	}
 
+	clientset := clients.kubeClient
+
 	scheduling := resource.PodScheduling{ScheduleOn: resource.GatewayNode, Networking: resource.HostNetworking}
 	podOutput, err := resource.SchedulePodAwaitCompletion(&resource.PodConfig{
 		Name:       "query-iface-list",
 		ClientSet:  clientset,
-		Scheduling: scheduling,
+		Scheduling: withDiagnosePodPlacement(scheduling),
 		Namespace:  namespace,
 		Command:    KubeProxyIPVSIfaceCommand,
 	})
 
 	if err != nil {
 		message := fmt.Sprintf("Error while spawning the Network Pod. %v", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, message)
 		status.End(cli.Failure)
 		return false
 	}
@@ -100,10 +119,83 @@ func validateKubeProxyModeInCluster(config *rest.Config, clusterName string) boo
 		status.QueueSuccessMessage("Cluster is not deployed with kube-proxy ipvs mode.")
 		status.End(cli.Success)
 	} else {
-		status.QueueFailureMessage("Cluster is deployed with kube-proxy ipvs mode." +
+		status.QueueFailureMessageWithCode(codeKubeProxyIPVS, "Cluster is deployed with kube-proxy ipvs mode."+
 			" Submariner does not support this mode.")
 		status.End(cli.Failure)
 		return false
 	}
 	return true
 }
+
+func validateKubeProxyPresence(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateKubeProxyPresenceInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateKubeProxyPresenceInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking whether kube-proxy is running in cluster %q", clusterName))
+
+	clientSet := clients.kubeClient
+
+	pods, err := clientSet.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: "k8s-app=kube-proxy"})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the kube-proxy pods: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	kubeProxyRunning := len(pods.Items) > 0
+	networkPlugin := submariner.Status.NetworkPlugin
+
+	replacesKubeProxy := false
+	for _, np := range kubeProxyReplacingNetworkPlugins {
+		if networkPlugin == np {
+			replacesKubeProxy = true
+			break
+		}
+	}
+
+	kubeProxyState := "not running"
+	if kubeProxyRunning {
+		kubeProxyState = fmt.Sprintf("running (%d pods)", len(pods.Items))
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("The detected CNI network plugin is %q and kube-proxy is %s", networkPlugin,
+		kubeProxyState))
+
+	// Only a missing kube-proxy with a network plugin relying on it is a problem
+	if !kubeProxyRunning && !replacesKubeProxy {
+		status.QueueWarningMessageWithCode(codeKubeProxyMissing, fmt.Sprintf("kube-proxy isn't running but the %q network"+
+			" plugin relies on it to implement services, unless it was explicitly configured to replace kube-proxy", networkPlugin))
+	}
+
+	status.End(status.ResultFromMessages())
+	return true
+}
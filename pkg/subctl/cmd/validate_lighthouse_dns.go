@@ -0,0 +1,359 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+)
+
+const (
+	lighthouseDNSServiceName    = "submariner-lighthouse-coredns"
+	lighthouseForwardPluginName = "lighthouse"
+	clusterSetDomain            = "clusterset.local"
+)
+
+var validateLighthouseDNSCmd = &cobra.Command{
+	Use:   "lighthouse-dns",
+	Short: "Check that the cluster DNS forwards to the lighthouse DNS server",
+	Long: "This command checks that the forward targets configured in the cluster DNS for the clusterset domains" +
+		" match the ClusterIP of the lighthouse DNS service, which can drift if the service is recreated, and that" +
+		" the lighthouse DNS server serves the domains the cluster DNS forwards to it.",
+	Run: validateLighthouseDNS,
+}
+
+var dnsesGVR = schema.GroupVersionResource{
+	Group:    operatorv1.GroupName,
+	Version:  operatorv1.GroupVersion.Version,
+	Resource: "dnses",
+}
+
+func init() {
+	validateCmd.AddCommand(validateLighthouseDNSCmd)
+}
+
+func validateLighthouseDNS(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateLighthouseDNSInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateLighthouseDNSInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking that the cluster DNS forwards to the lighthouse DNS service in cluster %q", clusterName))
+
+	if !submariner.Spec.ServiceDiscoveryEnabled {
+		status.QueueSuccessMessage("This check is only necessary when service discovery is enabled")
+		status.End(cli.Success)
+		return true
+	}
+
+	dynClient, clientSet := clients.dynamicClient, clients.kubeClient
+
+	operatorClient := clients.operatorClient
+
+	service, err := clientSet.CoreV1().Services(OperatorNamespace).Get(context.TODO(), lighthouseDNSServiceName, metav1.GetOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving the lighthouse DNS service: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	clusterIP := service.Spec.ClusterIP
+	if clusterIP == "" || clusterIP == "None" {
+		status.QueueFailureMessageWithCode(codeLighthouseDNSNoClusterIP, fmt.Sprintf("The lighthouse DNS service %q has no"+
+			" ClusterIP", lighthouseDNSServiceName))
+		status.End(cli.Failure)
+		return false
+	}
+
+	serviceDiscovery, err := operatorClient.SubmarinerV1alpha1().ServiceDiscoveries(OperatorNamespace).Get(context.TODO(),
+		names.ServiceDiscoveryCrName, metav1.GetOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving the ServiceDiscovery resource: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	forwardTargets, source, err := getClusterDNSForwardTargets(dynClient, clientSet, serviceDiscovery)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving the cluster DNS configuration: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	domains := append([]string{clusterSetDomain}, serviceDiscovery.Spec.CustomDomains...)
+
+	for _, domain := range domains {
+		targets, ok := forwardTargets[domain]
+		if !ok || len(targets) == 0 {
+			status.QueueFailureMessageWithCode(codeDNSNotForwarded,
+				fmt.Sprintf("The %s does not forward the %q domain to the lighthouse DNS service"+
+					" with ClusterIP %q", source, domain, clusterIP))
+			continue
+		}
+
+		for _, target := range targets {
+			if target != clusterIP {
+				status.QueueFailureMessageWithCode(codeDNSNotForwarded,
+					fmt.Sprintf("The %s forwards the %q domain to %q but the lighthouse DNS service"+
+						" has ClusterIP %q", source, domain, target, clusterIP))
+			}
+		}
+	}
+
+	checkLighthouseServedDomains(clientSet, domains, forwardTargets, clusterIP)
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("The %s forwards to the lighthouse DNS service ClusterIP %q", source, clusterIP))
+	status.End(cli.Success)
+	return true
+}
+
+// checkLighthouseServedDomains checks that the lighthouse DNS server serves the clusterset domains and those which
+// the cluster DNS forwards to it; a custom domain missing on either side breaks its resolution
+func checkLighthouseServedDomains(clientSet kubernetes.Interface, domains []string, forwardTargets map[string][]string,
+	clusterIP string) {
+	configMap, err := clientSet.CoreV1().ConfigMaps(OperatorNamespace).Get(context.TODO(), lighthouseDNSServiceName,
+		metav1.GetOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving the lighthouse DNS configuration: %s", err))
+		return
+	}
+
+	served := parseCorefilePluginZones(configMap.Data["Corefile"], lighthouseForwardPluginName)
+
+	for _, domain := range domains {
+		if !served[domain] {
+			status.QueueFailureMessageWithCode(codeDNSDomainNotServed,
+				fmt.Sprintf("The lighthouse DNS server doesn't serve the %q domain", domain))
+		}
+	}
+
+	expected := stringset.New(domains...)
+	forwarded := []string{}
+
+	for domain, targets := range forwardTargets {
+		for _, target := range targets {
+			if target == clusterIP && !served[domain] && !expected.Contains(domain) {
+				forwarded = append(forwarded, domain)
+				break
+			}
+		}
+	}
+
+	sort.Strings(forwarded)
+
+	for _, domain := range forwarded {
+		status.QueueFailureMessageWithCode(codeDNSNotForwarded,
+			fmt.Sprintf("The cluster DNS forwards the %q domain to the lighthouse DNS server,"+
+				" which doesn't serve it; is it missing from the custom domains?", domain))
+	}
+}
+
+// getClusterDNSForwardTargets returns the forward targets for each domain handed over to lighthouse by the cluster
+// DNS, along with a description of where they were read from. The lookup mirrors the service discovery controller:
+// the OpenShift DNS operator if present, otherwise the custom CoreDNS ConfigMap if one is configured, otherwise the
+// CoreDNS Corefile.
+func getClusterDNSForwardTargets(dynClient dynamic.Interface, clientSet kubernetes.Interface,
+	serviceDiscovery *v1alpha1.ServiceDiscovery) (map[string][]string, string, error) {
+	dnsOperator, err := getOpenShiftDNSOperator(dynClient)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if dnsOperator != nil {
+		targets := map[string][]string{}
+		for i := range dnsOperator.Spec.Servers {
+			server := &dnsOperator.Spec.Servers[i]
+			if server.Name != lighthouseForwardPluginName {
+				continue
+			}
+
+			for _, zone := range server.Zones {
+				targets[zone] = append(targets[zone], server.ForwardPlugin.Upstreams...)
+			}
+		}
+
+		return targets, "OpenShift DNS operator", nil
+	}
+
+	namespace := "kube-system"
+	name := "coredns"
+	key := "Corefile"
+
+	if serviceDiscovery.Spec.CoreDNSCustomConfig != nil && serviceDiscovery.Spec.CoreDNSCustomConfig.ConfigMapName != "" {
+		name = serviceDiscovery.Spec.CoreDNSCustomConfig.ConfigMapName
+		key = "lighthouse.server"
+
+		if serviceDiscovery.Spec.CoreDNSCustomConfig.Namespace != "" {
+			namespace = serviceDiscovery.Spec.CoreDNSCustomConfig.Namespace
+		}
+	}
+
+	configMap, err := clientSet.CoreV1().ConfigMaps(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return parseCorefileForwardTargets(configMap.Data[key]), fmt.Sprintf("CoreDNS ConfigMap \"%s/%s\"", namespace, name), nil
+}
+
+// getOpenShiftDNSOperator returns the default OpenShift DNS operator resource, or nil if the cluster doesn't have one
+func getOpenShiftDNSOperator(dynClient dynamic.Interface) (*operatorv1.DNS, error) {
+	obj, err := dynClient.Resource(dnsesGVR).Get(context.TODO(), "default", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	dnsOperator := &operatorv1.DNS{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, dnsOperator); err != nil {
+		return nil, err
+	}
+
+	return dnsOperator, nil
+}
+
+// parseCorefileForwardTargets returns the "forward ." targets of each server block in a Corefile, keyed by zone
+func parseCorefileForwardTargets(corefile string) map[string][]string {
+	targets := map[string][]string{}
+
+	walkCorefileDirectives(corefile, func(zones, fields []string) {
+		if fields[0] != "forward" || len(fields) < 3 || fields[1] != "." {
+			return
+		}
+
+		for _, target := range fields[2:] {
+			if target == "{" {
+				break
+			}
+
+			for _, zone := range zones {
+				targets[zone] = append(targets[zone], strings.SplitN(target, ":", 2)[0])
+			}
+		}
+	})
+
+	return targets
+}
+
+// parseCorefilePluginZones returns the zones of the server blocks of a Corefile which use the given plugin
+func parseCorefilePluginZones(corefile, plugin string) map[string]bool {
+	served := map[string]bool{}
+
+	walkCorefileDirectives(corefile, func(zones, fields []string) {
+		if fields[0] == plugin {
+			for _, zone := range zones {
+				served[zone] = true
+			}
+		}
+	})
+
+	return served
+}
+
+// walkCorefileDirectives calls visit with the fields of each directive of the server blocks in a Corefile, along with
+// the zones of its server block, without their ports and trailing dots. The directives nested in a plugin's block
+// and the comments are skipped.
+func walkCorefileDirectives(corefile string, visit func(zones, fields []string)) {
+	zones := []string{}
+	depth := 0
+
+	for _, line := range strings.Split(corefile, "\n") {
+		fields := strings.Fields(strings.SplitN(line, "#", 2)[0])
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "}" {
+			depth--
+			if depth == 0 {
+				zones = []string{}
+			}
+
+			continue
+		}
+
+		if depth == 0 && fields[len(fields)-1] == "{" {
+			for _, zone := range fields[:len(fields)-1] {
+				zones = append(zones, strings.TrimSuffix(strings.SplitN(zone, ":", 2)[0], "."))
+			}
+
+			depth++
+
+			continue
+		}
+
+		if depth == 1 {
+			visit(zones, fields)
+		}
+
+		if fields[len(fields)-1] == "{" {
+			depth++
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testCorefile = `# The cluster zone
+.:53 {
+    errors
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+        pods insecure
+        forward . 10.0.0.1
+    }
+    forward . /etc/resolv.conf {
+        max_concurrent 1000
+    }
+}
+clusterset.local:53 supercluster.local. {
+    # forward . 10.0.0.2
+    forward . 100.1.2.3:53 100.1.2.4 # the lighthouse DNS servers
+}
+example.org {
+    lighthouse
+    cache 30
+}
+`
+
+func TestParseCorefile(t *testing.T) {
+	tests := []struct {
+		name     string
+		corefile string
+		targets  map[string][]string
+		plugins  map[string]bool
+	}{
+		{
+			name:     "server blocks with nested blocks, ports and comments",
+			corefile: testCorefile,
+			targets: map[string][]string{
+				"":                   {"/etc/resolv.conf"},
+				"clusterset.local":   {"100.1.2.3", "100.1.2.4"},
+				"supercluster.local": {"100.1.2.3", "100.1.2.4"},
+			},
+			plugins: map[string]bool{"example.org": true},
+		},
+		{
+			name:     "empty Corefile",
+			corefile: "",
+			targets:  map[string][]string{},
+			plugins:  map[string]bool{},
+		},
+		{
+			name:     "forward without a target",
+			corefile: "clusterset.local {\n    forward .\n    lighthouse\n}\n",
+			targets:  map[string][]string{},
+			plugins:  map[string]bool{"clusterset.local": true},
+		},
+	}
+
+	for i := range tests {
+		test := &tests[i]
+
+		if targets := parseCorefileForwardTargets(test.corefile); !reflect.DeepEqual(targets, test.targets) {
+			t.Errorf("%s: expected the forward targets %v, got %v", test.name, test.targets, targets)
+		}
+
+		if zones := parseCorefilePluginZones(test.corefile, "lighthouse"); !reflect.DeepEqual(zones, test.plugins) {
+			t.Errorf("%s: expected the lighthouse zones %v, got %v", test.name, test.plugins, zones)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The maximum size of the logs shown for each container of a failed component
+const maxFailedComponentLogBytes = 16 * 1024
+
+var failedComponentLogLines int64
+
+func addFailedComponentLogsFlag(cmd *cobra.Command) {
+	cmd.Flags().Int64Var(&failedComponentLogLines, "log-lines", 0,
+		"number of log lines to show from the pods of a failed component (0 to disable)")
+}
+
+// printWorkloadLogs prints the last log lines of the pods of the given workloads, if requested
+func printWorkloadLogs(k8sClient kubernetes.Interface, namespace string, workloads []string) {
+	if failedComponentLogLines <= 0 {
+		return
+	}
+
+	pods, err := k8sClient.CoreV1().Pods(namespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: fmt.Sprintf("app in (%s)", strings.Join(workloads, ","))})
+	if err != nil {
+		diagnosePrintf("Unable to list the pods to show their logs: %s\n", err)
+		return
+	}
+
+	for i := range pods.Items {
+		printPodLogs(k8sClient, &pods.Items[i])
+	}
+}
+
+// printPodLogs prints the last log lines of each container of the given pod, if requested
+func printPodLogs(k8sClient kubernetes.Interface, pod *v1.Pod) {
+	if failedComponentLogLines <= 0 {
+		return
+	}
+
+	limitBytes := int64(maxFailedComponentLogBytes)
+
+	for _, container := range pod.Spec.Containers {
+		logs, err := k8sClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+			Container:  container.Name,
+			TailLines:  &failedComponentLogLines,
+			LimitBytes: &limitBytes,
+		}).DoRaw(context.TODO())
+		if err != nil {
+			diagnosePrintf("Unable to retrieve the logs of container %q in pod %q: %s\n", container.Name, pod.Name, err)
+			continue
+		}
+
+		diagnosePrintf("Last %d log lines of container %q in pod %q:\n", failedComponentLogLines, container.Name, pod.Name)
+
+		for _, line := range strings.Split(strings.TrimRight(string(logs), "\n"), "\n") {
+			diagnosePrintf("    %s\n", line)
+		}
+	}
+}
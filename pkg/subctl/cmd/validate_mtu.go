@@ -0,0 +1,324 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	InterfaceMTUCommand = "cat /sys/class/net/%s/mtu"
+	// The interface the Route Agent uses to forward traffic between the nodes and the Gateway
+	routeAgentVxLANInterface = "vx-submariner"
+	// The interface the vxlan cable driver uses for the tunnels between the clusters
+	cableDriverVxLANInterface = "vxlan-tunnel"
+	// The interface the WireGuard cable driver uses for the tunnels between the clusters
+	cableDriverWireGuardInterface = "submariner"
+	// The largest overhead of the ESP in UDP encapsulation libreswan applies on the default route interface
+	ipsecTunnelOverhead = 73
+)
+
+var validateMTUCmd = &cobra.Command{
+	Use:   "mtu",
+	Short: "Check the MTU of the Route Agent and Gateway interfaces",
+	Long: "This command checks that the MTU of the Route Agent VXLAN interface is consistent across the nodes" +
+		" and doesn't exceed the MTU of the Gateway tunnels: the tunnel interface of the vxlan and wireguard cable" +
+		" drivers, or the default route interface less the IPsec overhead for libreswan. It also checks that the" +
+		" gateway nodes use the same MTU on their default route interface, so that a gateway failover doesn't change" +
+		" the MTU of the tunnels.",
+	Run: validateMTU,
+}
+
+func init() {
+	validateCmd.AddCommand(validateMTUCmd)
+}
+
+func validateMTU(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateMTUInCluster(item.config, clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateMTUInCluster(config *rest.Config, clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the MTU of the Route Agent and Gateway interfaces in cluster %q", clusterName))
+
+	if skipInReadOnlyMode("MTU", clusterName) {
+		return true
+	}
+
+	clientSet := clients.kubeClient
+
+	routeAgentMTUs := getInterfaceMTUs(config, clientSet, "app=submariner-routeagent", routeAgentVxLANInterface)
+
+	nodes := make([]string, 0, len(routeAgentMTUs))
+	for node := range routeAgentMTUs {
+		nodes = append(nodes, node)
+	}
+
+	sort.Strings(nodes)
+
+	routeAgentMTU := 0
+	routeAgentNode := ""
+
+	for _, node := range nodes {
+		mtu := routeAgentMTUs[node]
+		if routeAgentMTU == 0 {
+			routeAgentMTU, routeAgentNode = mtu, node
+		} else if mtu != routeAgentMTU {
+			status.QueueWarningMessageWithCode(codeMTUMismatch,
+				fmt.Sprintf("The MTU of the %q interface on node %q (%d) does not match the"+
+					" MTU on node %q (%d)", routeAgentVxLANInterface, node, mtu, routeAgentNode, routeAgentMTU))
+		}
+	}
+
+	defaultRouteMTUs := checkGatewayNodeMTUs(config, clientSet)
+
+	if routeAgentMTU == 0 {
+		status.QueueWarningMessageWithCode(codeMTUUnmeasured, fmt.Sprintf("The MTU of the Route Agent %q interface"+
+			" couldn't be measured on any node", routeAgentVxLANInterface))
+	} else {
+		checkGatewayTunnelMTUs(config, clientSet, submariner.Spec.CableDriver, routeAgentMTU, defaultRouteMTUs)
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage(fmt.Sprintf("The MTU of the Route Agent and Gateway interfaces is consistent (%d)",
+			routeAgentMTU))
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+// gatewayTunnelInterface returns the interface carrying the tunnels of the cable driver, along with the overhead of the
+// encapsulation on that interface; libreswan has no tunnel interface, it encapsulates the packets on the default route
+// interface, which is returned as an empty name. It returns false if the cable driver is unknown.
+func gatewayTunnelInterface(cableDriver string) (string, int, bool) {
+	switch cableDriver {
+	case "", "libreswan":
+		return "", ipsecTunnelOverhead, true
+	case "vxlan":
+		return cableDriverVxLANInterface, 0, true
+	case wireGuardCableDriver:
+		return cableDriverWireGuardInterface, 0, true
+	}
+
+	return "", 0, false
+}
+
+// checkGatewayTunnelMTUs warns if the packets forwarded by the Route Agents, once encapsulated, don't fit in the MTU
+// of the Gateway tunnels
+func checkGatewayTunnelMTUs(config *rest.Config, clientSet kubernetes.Interface, cableDriver string, routeAgentMTU int,
+	defaultRouteMTUs map[string]int) {
+	iface, overhead, known := gatewayTunnelInterface(cableDriver)
+	if !known {
+		status.QueueWarningMessageWithCode(codeCableDriverUnknown, fmt.Sprintf("The tunnel interface of the %q cable"+
+			" driver is unknown", cableDriver))
+		return
+	}
+
+	tunnelMTUs := defaultRouteMTUs
+	description := "default route interface"
+
+	if iface != "" {
+		tunnelMTUs = getInterfaceMTUs(config, clientSet, "app=submariner-gateway", iface)
+		description = fmt.Sprintf("%q interface", iface)
+	}
+
+	if len(tunnelMTUs) == 0 {
+		status.QueueWarningMessageWithCode(codeMTUUnmeasured, fmt.Sprintf("The MTU of the Gateway %s couldn't be measured"+
+			" on any gateway node", description))
+		return
+	}
+
+	nodes := make([]string, 0, len(tunnelMTUs))
+	for node := range tunnelMTUs {
+		nodes = append(nodes, node)
+	}
+
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		mtu := tunnelMTUs[node]
+		if routeAgentMTU+overhead <= mtu {
+			continue
+		}
+
+		if overhead == 0 {
+			status.QueueWarningMessageWithCode(codeMTUMismatch, fmt.Sprintf("The MTU of the Route Agent %q interface (%d)"+
+				" is larger than the MTU of the Gateway %s on node %q (%d)", routeAgentVxLANInterface, routeAgentMTU,
+				description, node, mtu))
+		} else {
+			status.QueueWarningMessageWithCode(codeMTUMismatch, fmt.Sprintf("The MTU of the Route Agent %q interface (%d)"+
+				" plus the IPsec overhead (%d) is larger than the MTU of the Gateway %s on node %q (%d)",
+				routeAgentVxLANInterface, routeAgentMTU, overhead, description, node, mtu))
+		}
+	}
+}
+
+// getInterfaceMTUs returns the MTU of the given interface on the nodes running the selected (host network) pods
+func getInterfaceMTUs(config *rest.Config, clientSet kubernetes.Interface, podSelector, iface string) map[string]int {
+	mtus := map[string]int{}
+
+	pods, err := clientSet.CoreV1().Pods(OperatorNamespace).List(context.TODO(), metav1.ListOptions{LabelSelector: podSelector})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the pods with selector %q: %s", podSelector, err))
+		return mtus
+	}
+
+	running := runningPods(pods.Items)
+	outputs := make([]string, len(running))
+	errs := make([]error, len(running))
+
+	probeConcurrently(len(running), func(i int) {
+		outputs[i], errs[i] = execInPod(config, clientSet, running[i], fmt.Sprintf(InterfaceMTUCommand, iface))
+	})
+
+	for i, pod := range running {
+		if errs[i] != nil {
+			status.QueueWarningMessageWithCode(codeNodeProbeFailed, fmt.Sprintf("Unable to read the MTU of the %q interface on node %q: %s",
+				iface, pod.Spec.NodeName, errs[i]))
+			continue
+		}
+
+		mtu, err := strconv.Atoi(strings.TrimSpace(outputs[i]))
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeNodeProbeFailed, fmt.Sprintf("Unable to parse the MTU of the %q interface on node %q: %s",
+				iface, pod.Spec.NodeName, err))
+			continue
+		}
+
+		mtus[pod.Spec.NodeName] = mtu
+	}
+
+	return mtus
+}
+
+// checkGatewayNodeMTUs warns if the gateway nodes use different MTUs on their default route interface: a failover from
+// one gateway node to another would then change the MTU of the tunnels. It returns the MTUs measured on each node.
+func checkGatewayNodeMTUs(config *rest.Config, clientSet kubernetes.Interface) map[string]int {
+	measured := map[string]int{}
+
+	pods, err := clientSet.CoreV1().Pods(OperatorNamespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: "app=submariner-gateway"})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the gateway pods: %s", err))
+		return measured
+	}
+
+	nodeMTUs := []string{}
+	mtus := map[int]bool{}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+
+		output, err := execInPod(config, clientSet, pod, DefaultRouteCommand)
+		iface := defaultRouteInterface(output)
+
+		if err != nil || iface == "" {
+			status.QueueWarningMessageWithCode(codeNodeProbeFailed, fmt.Sprintf("Unable to find the default route interface of the gateway node %q",
+				pod.Spec.NodeName))
+			continue
+		}
+
+		output, err = execInPod(config, clientSet, pod, fmt.Sprintf(InterfaceMTUCommand, iface))
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeNodeProbeFailed, fmt.Sprintf("Unable to read the MTU of the %q interface on node %q: %s",
+				iface, pod.Spec.NodeName, err))
+			continue
+		}
+
+		mtu, err := strconv.Atoi(strings.TrimSpace(output))
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeNodeProbeFailed, fmt.Sprintf("Unable to parse the MTU of the %q interface on node %q: %s",
+				iface, pod.Spec.NodeName, err))
+			continue
+		}
+
+		nodeMTUs = append(nodeMTUs, fmt.Sprintf("%q (%s): %d", pod.Spec.NodeName, iface, mtu))
+		mtus[mtu] = true
+		measured[pod.Spec.NodeName] = mtu
+	}
+
+	sort.Strings(nodeMTUs)
+
+	if len(mtus) > 1 {
+		status.QueueWarningMessageWithCode(codeMTUMismatch,
+			fmt.Sprintf("The gateway nodes use different MTUs on their default route interface,"+
+				" so a gateway failover changes the MTU of the tunnels: %s", strings.Join(nodeMTUs, ", ")))
+	} else if len(nodeMTUs) > 1 {
+		status.QueueSuccessMessage(fmt.Sprintf("The gateway nodes use the same MTU on their default route interface: %s",
+			strings.Join(nodeMTUs, ", ")))
+	}
+
+	return measured
+}
+
+// defaultRouteInterface returns the interface of the default route in the given "ip route show default" output
+func defaultRouteInterface(routes string) string {
+	fields := strings.Fields(routes)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "dev" {
+			return fields[i+1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,44 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import "testing"
+
+func TestGatewayTunnelInterface(t *testing.T) {
+	tests := []struct {
+		cableDriver string
+		iface       string
+		overhead    int
+		known       bool
+	}{
+		{"", "", ipsecTunnelOverhead, true},
+		{"libreswan", "", ipsecTunnelOverhead, true},
+		{"vxlan", cableDriverVxLANInterface, 0, true},
+		{"wireguard", cableDriverWireGuardInterface, 0, true},
+		{"unknown", "", 0, false},
+	}
+
+	for _, test := range tests {
+		iface, overhead, known := gatewayTunnelInterface(test.cableDriver)
+		if iface != test.iface || overhead != test.overhead || known != test.known {
+			t.Errorf("gatewayTunnelInterface(%q) returned (%q, %d, %t), expected (%q, %d, %t)", test.cableDriver, iface,
+				overhead, known, test.iface, test.overhead, test.known)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateNATTraversalCmd = &cobra.Command{
+	Use:   "nat-traversal",
+	Short: "Check that the NAT traversal setting of the Endpoints matches their network",
+	Long: "This command checks the NAT traversal flag of the local Endpoints against their IPs: a gateway whose public" +
+		" IP differs from its non-routable private IP is behind NAT, so NAT traversal should be enabled for peers in" +
+		" other networks to reach it, while a gateway whose public IP is its private IP isn't behind NAT. It also checks" +
+		" that the Endpoints agree with the NAT traversal setting of the Submariner resource.",
+	Run: validateNATTraversal,
+}
+
+func init() {
+	validateCmd.AddCommand(validateNATTraversalCmd)
+}
+
+func validateNATTraversal(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateNATTraversalInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateNATTraversalInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the NAT traversal setting of the Endpoints of cluster %q", clusterName))
+
+	endpoints, err := listEndpoints(clients.submarinerClient)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Endpoints: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	found := false
+
+	for i := range endpoints {
+		if endpoints[i].Spec.ClusterID != submariner.Spec.ClusterID {
+			continue
+		}
+
+		found = true
+
+		checkEndpointNATTraversal(&endpoints[i], submariner.Spec.NatEnabled)
+	}
+
+	if !found {
+		status.QueueWarningMessageWithCode(codeLocalEndpointMissing, "No local Endpoint was found")
+		status.End(cli.Warning)
+		return true
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("The NAT traversal setting of the Endpoints matches their IPs")
+	}
+
+	status.End(result)
+	return true
+}
+
+// checkEndpointNATTraversal warns if the NAT traversal flag of the Endpoint is inconsistent with the Submariner
+// resource or with the gateway being behind NAT, as detected from its public and private IPs
+func checkEndpointNATTraversal(endpoint *subv1.Endpoint, natEnabled bool) {
+	spec := &endpoint.Spec
+	ips := fmt.Sprintf("public IP %q, private IP %q", spec.PublicIP, spec.PrivateIP)
+
+	if spec.NATEnabled != natEnabled {
+		status.QueueWarningMessageWithCode(codeNATTraversalMismatch,
+			fmt.Sprintf("The Endpoint %q has NAT traversal set to %t but the Submariner resource"+
+				" sets it to %t; the gateway may not have picked up the change", endpoint.Name, spec.NATEnabled, natEnabled))
+	}
+
+	if spec.PublicIP == "" {
+		if spec.NATEnabled {
+			status.QueueWarningMessageWithCode(codeNATTraversalMismatch,
+				fmt.Sprintf("The Endpoint %q has NAT traversal enabled but no public IP (%s), so"+
+					" peers in other networks have no address to connect to", endpoint.Name, ips))
+		}
+
+		return
+	}
+
+	behindNAT := spec.PublicIP != spec.PrivateIP && isNonRoutableIP(spec.PrivateIP)
+
+	if behindNAT && !spec.NATEnabled {
+		status.QueueWarningMessageWithCode(codeNATTraversalMismatch,
+			fmt.Sprintf("The Endpoint %q is behind NAT (%s) but has NAT traversal disabled, so"+
+				" peers in other networks connect to its non-routable private IP; enable NAT traversal unless the networks"+
+				" are interconnected", endpoint.Name, ips))
+	}
+
+	if spec.NATEnabled && spec.PublicIP == spec.PrivateIP {
+		status.QueueWarningMessageWithCode(codeNATTraversalMismatch,
+			fmt.Sprintf("The Endpoint %q has NAT traversal enabled but isn't behind NAT (%s);"+
+				" NAT traversal isn't needed", endpoint.Name, ips))
+	}
+}
@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	// OLM names the CSVs of the operator after its package, followed by the version
+	operatorCSVPrefix   = "submariner."
+	csvSucceededPhase   = "Succeeded"
+	operatorPackageName = "submariner"
+)
+
+var clusterServiceVersionsGVR = schema.GroupVersionResource{
+	Group:    "operators.coreos.com",
+	Version:  "v1alpha1",
+	Resource: "clusterserviceversions",
+}
+
+// checkOperatorCSV checks that the ClusterServiceVersion of the operator, when it is installed by OLM, is in the
+// Succeeded phase; OLM can leave it installing or failed while a pod from an earlier install keeps running
+func checkOperatorCSV(clusterName string, clients *clusterClients, operatorNamespace string) bool {
+	status.Start(fmt.Sprintf("Checking the operator's ClusterServiceVersion in %q", clusterName))
+
+	csvs, err := clients.dynamicClient.Resource(clusterServiceVersionsGVR).Namespace(operatorNamespace).List(context.TODO(),
+		metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		status.QueueSuccessMessage("OLM is not installed, so the operator wasn't installed by OLM")
+		status.End(cli.Success)
+		return true
+	}
+
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the ClusterServiceVersions: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	found := false
+
+	for i := range csvs.Items {
+		csv := &csvs.Items[i]
+		if !strings.HasPrefix(csv.GetName(), operatorCSVPrefix) {
+			continue
+		}
+
+		found = true
+
+		phase, _, _ := unstructured.NestedString(csv.Object, "status", "phase")
+		reason, _, _ := unstructured.NestedString(csv.Object, "status", "reason")
+		message, _, _ := unstructured.NestedString(csv.Object, "status", "message")
+
+		if phase == csvSucceededPhase {
+			status.QueueSuccessMessage(fmt.Sprintf("The ClusterServiceVersion %q is in the %s phase", csv.GetName(), phase))
+			continue
+		}
+
+		if phase == "" {
+			phase = "unknown"
+		}
+
+		status.QueueFailureMessageWithCode(codeOperatorCSVFailed, fmt.Sprintf("The ClusterServiceVersion %q is in the %s"+
+			" phase instead of %s (%s: %s)",
+			csv.GetName(), phase, csvSucceededPhase, reason, message))
+	}
+
+	if !found {
+		status.QueueSuccessMessage(fmt.Sprintf("No ClusterServiceVersion of the %s package was found, so the operator"+
+			" wasn't installed by OLM", operatorPackageName))
+	}
+
+	result := status.ResultFromMessages()
+	status.End(result)
+	return result != cli.Failure
+}
@@ -0,0 +1,123 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+// operatorLeaderLockName is the lock used by the operator for its leader-for-life election; it is owned by the pod
+// which is the leader, and is only released when that pod is deleted
+const operatorLeaderLockName = "submariner-operator-lock"
+
+// checkOperatorLeader checks that the operator's leader lock is held by a running pod on a ready node. The operator
+// elects its leader for life, so the lock isn't renewed; a lock held by a pod which no longer runs wedges the operator
+// while the other operator pods wait to become the leader.
+func checkOperatorLeader(clusterName string, clients *clusterClients, operatorNamespace string) bool {
+	status.Start(fmt.Sprintf("Checking the operator leader election in %q", clusterName))
+
+	lock, err := clients.kubeClient.CoreV1().ConfigMaps(operatorNamespace).Get(context.TODO(), operatorLeaderLockName,
+		metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		status.QueueFailureMessageWithCode(codeOperatorLeaderMissing, fmt.Sprintf("The operator leader lock %q doesn't exist, so"+
+			" no operator pod is reconciling", operatorLeaderLockName))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving the operator leader lock"+
+			" %q: %s", operatorLeaderLockName, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	var holder string
+	for _, owner := range lock.OwnerReferences {
+		if owner.Kind == "Pod" {
+			holder = owner.Name
+			break
+		}
+	}
+
+	if holder == "" {
+		status.QueueFailureMessageWithCode(codeOperatorLeaderUnowned, fmt.Sprintf("The operator leader lock %q isn't owned by a"+
+			" pod", operatorLeaderLockName))
+		status.End(cli.Failure)
+		return false
+	}
+
+	pod, err := clients.kubeClient.CoreV1().Pods(operatorNamespace).Get(context.TODO(), holder, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		status.QueueFailureMessageWithCode(codeOperatorLeaderStale, fmt.Sprintf("The operator leader lock is held by pod %q,"+
+			" which no longer exists", holder))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving the operator leader pod %q: %s", holder, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if pod.DeletionTimestamp != nil {
+		status.QueueWarningMessageWithCode(codeOperatorLeaderTerminating, fmt.Sprintf("The operator leader lock is held by pod"+
+			" %q, which is being deleted", holder))
+	}
+
+	if pod.Status.Phase != v1.PodRunning {
+		status.QueueFailureMessageWithCode(codeOperatorLeaderUnhealthy, fmt.Sprintf("The operator leader lock is held by pod"+
+			" %q, which is %s instead of %s", holder, pod.Status.Phase, v1.PodRunning))
+	} else if !isNodeReady(clients, pod.Spec.NodeName) {
+		status.QueueFailureMessageWithCode(codeOperatorLeaderUnhealthy, fmt.Sprintf("The operator leader lock is held by pod"+
+			" %q, on node %q which isn't ready", holder, pod.Spec.NodeName))
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("The operator leader lock is held by running pod %q", holder))
+	status.End(status.ResultFromMessages())
+	return true
+}
+
+func isNodeReady(clients *clusterClients, nodeName string) bool {
+	node, err := clients.kubeClient.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		// Without access to the node, give the pod the benefit of the doubt
+		return true
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/submariner-io/submariner-operator/pkg/subctl/resource"
+)
+
+var (
+	diagnosePodTolerationSpecs     []string
+	diagnosePodTolerations         []v1.Toleration
+	diagnosePodNodeSelector        map[string]string
+	diagnoseGatewayPodNodeSelector map[string]string
+)
+
+func addDiagnosePodPlacementFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringSliceVar(&diagnosePodTolerationSpecs, "pod-tolerations", nil,
+		"tolerations of the pods scheduled by the checks, as key[=value][:effect], instead of tolerating all the"+
+			" taints; for clusters whose policies reject the default toleration")
+	cmd.PersistentFlags().StringToStringVar(&diagnosePodNodeSelector, "pod-node-selector", nil,
+		"node labels, as key=value, restricting the non-gateway nodes the pods scheduled by the checks run on")
+	cmd.PersistentFlags().StringToStringVar(&diagnoseGatewayPodNodeSelector, "gateway-pod-node-selector", nil,
+		"node labels, as key=value, restricting the gateway nodes the pods scheduled by the checks of the gateways run on")
+}
+
+// parseDiagnosePodTolerations parses the tolerations given as key[=value][:effect], like the taints given to kubectl; a
+// toleration without a value tolerates any value, and one without an effect tolerates any effect
+func parseDiagnosePodTolerations(specs []string) ([]v1.Toleration, error) {
+	tolerations := make([]v1.Toleration, 0, len(specs))
+
+	for _, spec := range specs {
+		toleration := v1.Toleration{Operator: v1.TolerationOpExists}
+
+		keyValue := spec
+		if i := strings.LastIndex(spec, ":"); i >= 0 {
+			keyValue = spec[:i]
+			toleration.Effect = v1.TaintEffect(spec[i+1:])
+
+			switch toleration.Effect {
+			case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+			default:
+				return nil, fmt.Errorf("invalid effect %q in toleration %q, expected one of %s, %s or %s",
+					toleration.Effect, spec, v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule,
+					v1.TaintEffectNoExecute)
+			}
+		}
+
+		toleration.Key = keyValue
+		if i := strings.Index(keyValue, "="); i >= 0 {
+			toleration.Key = keyValue[:i]
+			toleration.Value = keyValue[i+1:]
+			toleration.Operator = v1.TolerationOpEqual
+		}
+
+		if toleration.Key == "" {
+			return nil, fmt.Errorf("missing key in toleration %q", spec)
+		}
+
+		tolerations = append(tolerations, toleration)
+	}
+
+	return tolerations, nil
+}
+
+// withDiagnosePodPlacement applies the tolerations and node selectors given on the command line to the scheduling of a
+// pod; the gateway or non-gateway node selector narrows the nodes of that kind the pod would otherwise be scheduled on
+func withDiagnosePodPlacement(scheduling resource.PodScheduling) resource.PodScheduling {
+	scheduling.Tolerations = diagnosePodTolerations
+
+	switch scheduling.ScheduleOn {
+	case resource.GatewayNode:
+		scheduling.NodeSelector = diagnoseGatewayPodNodeSelector
+	case resource.NonGatewayNode:
+		scheduling.NodeSelector = diagnosePodNodeSelector
+	}
+
+	return scheduling
+}
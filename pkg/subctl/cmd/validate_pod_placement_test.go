@@ -0,0 +1,80 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/submariner-io/submariner-operator/pkg/subctl/resource"
+)
+
+func TestParseDiagnosePodTolerations(t *testing.T) {
+	tolerations, err := parseDiagnosePodTolerations([]string{"dedicated=infra:NoSchedule", "gpu:NoExecute",
+		"maintenance=planned", "node-role.kubernetes.io/master"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []v1.Toleration{
+		{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "infra", Effect: v1.TaintEffectNoSchedule},
+		{Key: "gpu", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+		{Key: "maintenance", Operator: v1.TolerationOpEqual, Value: "planned"},
+		{Key: "node-role.kubernetes.io/master", Operator: v1.TolerationOpExists},
+	}
+
+	if !reflect.DeepEqual(tolerations, expected) {
+		t.Errorf("Unexpected tolerations %+v, expected %+v", tolerations, expected)
+	}
+}
+
+func TestParseDiagnosePodTolerationsRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{"dedicated=infra:NoWay", ":NoSchedule", "=infra", ""} {
+		if _, err := parseDiagnosePodTolerations([]string{spec}); err == nil {
+			t.Errorf("Expected an error parsing toleration %q", spec)
+		}
+	}
+}
+
+func TestWithDiagnosePodPlacementSelectsNodesByKind(t *testing.T) {
+	previousSelector, previousGatewaySelector := diagnosePodNodeSelector, diagnoseGatewayPodNodeSelector
+	defer func() {
+		diagnosePodNodeSelector, diagnoseGatewayPodNodeSelector = previousSelector, previousGatewaySelector
+	}()
+
+	diagnosePodNodeSelector = map[string]string{"pool": "workers"}
+	diagnoseGatewayPodNodeSelector = map[string]string{"zone": "edge"}
+
+	tests := []struct {
+		scheduling resource.PodScheduling
+		expected   map[string]string
+	}{
+		{resource.PodScheduling{ScheduleOn: resource.GatewayNode}, diagnoseGatewayPodNodeSelector},
+		{resource.PodScheduling{ScheduleOn: resource.NonGatewayNode}, diagnosePodNodeSelector},
+		{resource.PodScheduling{ScheduleOn: resource.CustomNode, NodeName: "node-1"}, nil},
+	}
+
+	for _, test := range tests {
+		if actual := withDiagnosePodPlacement(test.scheduling).NodeSelector; !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Pods scheduled on %v got the node selector %v, expected %v", test.scheduling.ScheduleOn, actual,
+				test.expected)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+// privilegedWorkload describes a data plane DaemonSet whose pods need host networking and privileges
+type privilegedWorkload struct {
+	name       string
+	capability v1.Capability
+}
+
+var privilegedWorkloads = []privilegedWorkload{
+	{name: "submariner-gateway", capability: "NET_ADMIN"},
+	{name: "submariner-routeagent", capability: "NET_ADMIN"},
+}
+
+const (
+	podSecurityEnforceLabel  = "pod-security.kubernetes.io/enforce"
+	podSecurityPrivileged    = "privileged"
+	podSecurityLabelsPrefix  = "pod-security.kubernetes.io/"
+	podSecurityVersionSuffix = "-version"
+)
+
+var validatePodPrivilegesCmd = &cobra.Command{
+	Use:   "pod-privileges",
+	Short: "Check the privileges of the Gateway and Route Agent pods",
+	Long: "This command checks that the Gateway and Route Agent pods run with host networking and the privileges" +
+		" they need, that these weren't removed by an admission controller, and that the Pod Security labels of" +
+		" their namespace allow privileged pods.",
+	Run: validatePodPrivileges,
+}
+
+func init() {
+	validateCmd.AddCommand(validatePodPrivilegesCmd)
+}
+
+func validatePodPrivileges(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validatePodSecurityLabelsInCluster(clients, item.clusterName) && validationStatus
+		validationStatus = validatePodPrivilegesInCluster(clients, item.clusterName) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validatePodPrivilegesInCluster(clients *clusterClients, clusterName string) bool {
+	status.Start(fmt.Sprintf("Checking the privileges of the Gateway and Route Agent pods in cluster %q", clusterName))
+
+	clientSet := clients.kubeClient
+
+	for _, workload := range privilegedWorkloads {
+		checkWorkloadPrivileges(clientSet, workload)
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage("The Gateway and Route Agent pods have the required privileges")
+	status.End(cli.Success)
+	return true
+}
+
+// validatePodSecurityLabelsInCluster checks that the Pod Security Admission level enforced on the Submariner namespace
+// allows the privileged Gateway and Route Agent pods
+func validatePodSecurityLabelsInCluster(clients *clusterClients, clusterName string) bool {
+	status.Start(fmt.Sprintf("Checking the Pod Security labels of namespace %q in cluster %q", OperatorNamespace, clusterName))
+
+	clientSet := clients.kubeClient
+
+	namespace, err := clientSet.CoreV1().Namespaces().Get(context.TODO(), OperatorNamespace, metav1.GetOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error retrieving namespace %q: %s", OperatorNamespace, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	level, ok := namespace.Labels[podSecurityEnforceLabel]
+	if !ok {
+		status.QueueSuccessMessage(fmt.Sprintf("The namespace has no %q label, so the cluster's default Pod Security level"+
+			" applies; it must be %q for the Gateway and Route Agent pods to be admitted", podSecurityEnforceLabel,
+			podSecurityPrivileged))
+		status.End(cli.Success)
+		return true
+	}
+
+	if level != podSecurityPrivileged {
+		status.QueueWarningMessageWithCode(codePodSecurityEnforced, fmt.Sprintf("The namespace has label %s=%s, which"+
+			" prevents the privileged Gateway and Route Agent pods from starting; it should be %s=%s", podSecurityEnforceLabel,
+			level, podSecurityEnforceLabel, podSecurityPrivileged))
+	}
+
+	labels := make([]string, 0, len(namespace.Labels))
+	for label := range namespace.Labels {
+		labels = append(labels, label)
+	}
+
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		value := namespace.Labels[label]
+		if strings.HasPrefix(label, podSecurityLabelsPrefix) && label != podSecurityEnforceLabel &&
+			!strings.HasSuffix(label, podSecurityVersionSuffix) && value != podSecurityPrivileged {
+			status.QueueWarningMessageWithCode(codePodSecurityReported, fmt.Sprintf("The namespace has label %s=%s, so the"+
+				" privileged pods will be reported as Pod Security violations", label, value))
+		}
+	}
+
+	if status.HasWarningMessages() {
+		status.End(cli.Warning)
+		return true
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("The namespace enforces the %q Pod Security level", podSecurityPrivileged))
+	status.End(cli.Success)
+	return true
+}
+
+func checkWorkloadPrivileges(clientSet kubernetes.Interface, workload privilegedWorkload) {
+	daemonSet, err := clientSet.AppsV1().DaemonSets(OperatorNamespace).Get(context.TODO(), workload.name, metav1.GetOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining DaemonSet %q: %s", workload.name, err))
+		return
+	}
+
+	missingInTemplate := stringset.New(missingPodPrivileges(&daemonSet.Spec.Template.Spec, workload)...)
+	for _, missing := range missingInTemplate.Elements() {
+		status.QueueFailureMessageWithCode(codePrivilegesNotRequested, fmt.Sprintf("The DaemonSet %q does not request %s",
+			workload.name, missing))
+	}
+
+	pods, err := clientSet.CoreV1().Pods(OperatorNamespace).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: "app=" + workload.name})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the pods for %q: %s", workload.name, err))
+		return
+	}
+
+	// Privileges requested by the DaemonSet but missing from a pod were removed on admission
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, missing := range missingPodPrivileges(&pod.Spec, workload) {
+			if !missingInTemplate.Contains(missing) {
+				status.QueueFailureMessageWithCode(codePrivilegesRemoved, fmt.Sprintf("Pod %q on node %q is missing %s, which"+
+					" was likely removed by an admission controller", pod.Name, pod.Spec.NodeName, missing))
+			}
+		}
+	}
+}
+
+// missingPodPrivileges returns descriptions of the privileges required by the workload which the pod spec doesn't grant
+func missingPodPrivileges(spec *v1.PodSpec, workload privilegedWorkload) []string {
+	missing := []string{}
+
+	if !spec.HostNetwork {
+		missing = append(missing, "host networking")
+	}
+
+	var container *v1.Container
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == workload.name {
+			container = &spec.Containers[i]
+			break
+		}
+	}
+
+	if container == nil {
+		return append(missing, fmt.Sprintf("the %q container", workload.name))
+	}
+
+	securityContext := container.SecurityContext
+	if securityContext == nil || securityContext.Privileged == nil || !*securityContext.Privileged {
+		missing = append(missing, "privileged mode")
+	}
+
+	if securityContext == nil || securityContext.Capabilities == nil ||
+		!hasCapability(securityContext.Capabilities.Add, workload.capability) {
+		missing = append(missing, fmt.Sprintf("the %s capability", workload.capability))
+	}
+
+	return missing
+}
+
+func hasCapability(capabilities []v1.Capability, capability v1.Capability) bool {
+	for _, c := range capabilities {
+		if strings.EqualFold(string(c), string(capability)) || strings.EqualFold(string(c), "ALL") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/pkg/subctl/resource"
+)
+
+func spawnSnifferPodOnGatewayNode(clientSet kubernetes.Interface,
+	namespace, podCommand string) (*resource.NetworkPod, error) {
+	scheduling := resource.PodScheduling{ScheduleOn: resource.GatewayNode, Networking: resource.HostNetworking}
+	return spawnPod(clientSet, scheduling, "validate-sniffer",
+		namespace, podCommand)
+}
+
+func spawnSnifferPodOnNode(clientSet kubernetes.Interface,
+	nodeName, namespace, podCommand string) (*resource.NetworkPod, error) {
+	scheduling := resource.PodScheduling{ScheduleOn: resource.CustomNode, NodeName: nodeName,
+		Networking: resource.HostNetworking}
+	return spawnPod(clientSet, scheduling, "validate-sniffer",
+		namespace, podCommand)
+}
+
+func spawnClientPodOnNonGatewayNode(clientSet kubernetes.Interface,
+	namespace, podCommand string) (*resource.NetworkPod, error) {
+	scheduling := resource.PodScheduling{ScheduleOn: resource.NonGatewayNode, Networking: resource.PodNetworking}
+	return spawnPod(clientSet, scheduling, "validate-client",
+		namespace, podCommand)
+}
+
+func spawnPod(clientSet kubernetes.Interface, scheduling resource.PodScheduling, podName, namespace,
+	podCommand string) (*resource.NetworkPod, error) {
+	pod, err := resource.SchedulePod(&resource.PodConfig{
+		Name:       podName,
+		ClientSet:  clientSet,
+		Scheduling: withDiagnosePodPlacement(scheduling),
+		Namespace:  namespace,
+		Command:    podCommand,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// execInPod runs the command in the pod with bash and returns its standard output
+func execInPod(config *rest.Config, clientSet kubernetes.Interface, pod *v1.Pod, command string) (string, error) {
+	execOptions := resource.ExecOptionsFromPod(pod)
+	execOptions.Command = []string{"/bin/bash", "-c", command}
+	stdout, _, err := resource.ExecWithOptions(resource.ExecConfig{
+		RestConfig: config,
+		ClientSet:  clientSet,
+	}, execOptions)
+
+	return stdout, err
+}
+
+// runningPods returns the pods in the running phase
+func runningPods(pods []v1.Pod) []*v1.Pod {
+	running := []*v1.Pod{}
+
+	for i := range pods {
+		if pods[i].Status.Phase == v1.PodRunning {
+			running = append(running, &pods[i])
+		}
+	}
+
+	return running
+}
+
+// probeConcurrently calls probe for each of the count nodes, probing up to --concurrency-per-cluster of them at once,
+// and returns once they have all been probed. The probes run concurrently, so they must not report through the status;
+// they store their results for the caller to report in order.
+func probeConcurrently(count int, probe func(i int)) {
+	limit := make(chan struct{}, diagnoseConcurrencyPerCluster)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		limit <- struct{}{}
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-limit }()
+
+			probe(i)
+		}(i)
+	}
+
+	wg.Wait()
+}
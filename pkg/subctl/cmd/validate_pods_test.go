@@ -0,0 +1,62 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProbeConcurrentlyBoundsTheProbes(t *testing.T) {
+	previous := diagnoseConcurrencyPerCluster
+	defer func() { diagnoseConcurrencyPerCluster = previous }()
+
+	diagnoseConcurrencyPerCluster = 3
+
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	probed := make([]bool, 10)
+
+	probeConcurrently(len(probed), func(i int) {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		probed[i] = true
+		mutex.Unlock()
+	})
+
+	for i := range probed {
+		if !probed[i] {
+			t.Errorf("Node %d wasn't probed", i)
+		}
+	}
+
+	if maxRunning > diagnoseConcurrencyPerCluster {
+		t.Errorf("Expected at most %d probes at once, got %d", diagnoseConcurrencyPerCluster, maxRunning)
+	}
+}
@@ -0,0 +1,179 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/submariner/pkg/cidr"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/pkg/discovery/network"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validatePreflightCmd = &cobra.Command{
+	Use:   "preflight <brokerkubeconfig> <memberkubeconfig>",
+	Short: "Check that two clusters are ready for Submariner before joining them",
+	Long: "This command runs the checks which don't require Submariner to be installed against the cluster which will" +
+		" host the broker and a cluster which will join it: it checks that their Kubernetes versions are supported," +
+		" that their CNI network plugins are supported, and that their pod and service CIDRs don't overlap, which" +
+		" would require Globalnet. It reports whether joining the clusters should be safe.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("two kubeconfigs must be specified")
+		}
+		same, err := compareFiles(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if same {
+			return fmt.Errorf("the specified kubeconfig files are the same")
+		}
+		return nil
+	},
+	Run: validatePreflight,
+}
+
+func init() {
+	validateCmd.AddCommand(validatePreflightCmd)
+}
+
+func validatePreflight(cmd *cobra.Command, args []string) {
+	brokerCfg, err := getRestConfig(args[0], "")
+	exitOnError("The provided broker kubeconfig is invalid", err)
+
+	memberCfg, err := getRestConfig(args[1], "")
+	exitOnError("The provided member kubeconfig is invalid", err)
+
+	validationStatus := validatePreflightAcrossClusters(brokerCfg, memberCfg)
+	if validationStatus {
+		diagnosePrintf("The preflight checks passed, joining the clusters should be safe\n")
+	} else {
+		diagnosePrintf("The preflight checks failed, joining the clusters is likely to fail until the issues are fixed\n")
+	}
+
+	finishValidation(validationStatus)
+}
+
+func validatePreflightAcrossClusters(brokerCfg, memberCfg *rest.Config) bool {
+	brokerClients := newCheckClients("broker candidate", brokerCfg, newClusterClients)
+	memberClients := newCheckClients("member candidate", memberCfg, newClusterClients)
+
+	if brokerClients == nil || memberClients == nil {
+		return false
+	}
+
+	validationStatus := true
+
+	validationStatus = validateK8sVersionInCluster(brokerClients, "broker candidate") && validationStatus
+	validationStatus = validateK8sVersionInCluster(memberClients, "member candidate") && validationStatus
+
+	brokerNetwork, ok := discoverPreflightNetwork(brokerClients, "broker candidate")
+	validationStatus = ok && validationStatus
+
+	memberNetwork, ok := discoverPreflightNetwork(memberClients, "member candidate")
+	validationStatus = ok && validationStatus
+
+	if brokerNetwork == nil || memberNetwork == nil {
+		return validationStatus
+	}
+
+	return checkPreflightCIDRs(brokerNetwork, memberNetwork) && validationStatus
+}
+
+// discoverPreflightNetwork discovers the network of the cluster and checks that its CNI network plugin is supported;
+// it returns nil if the network couldn't be discovered
+func discoverPreflightNetwork(clients *clusterClients, clusterName string) (*network.ClusterNetwork, bool) {
+	status.Start(fmt.Sprintf("Checking Submariner support for the CNI network plugin in the %s cluster", clusterName))
+
+	clusterNetwork, err := network.Discover(clients.dynamicClient, clients.kubeClient, nil, OperatorNamespace)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCIDRsNotDiscovered, fmt.Sprintf("Error discovering the cluster network details: %s", err))
+		status.End(cli.Failure)
+		return nil, false
+	}
+
+	if clusterNetwork == nil {
+		status.QueueWarningMessageWithCode(codeCIDRsNotDiscovered, "Unable to discover the cluster network details; the CIDRs"+
+			" will have to be specified when joining")
+		status.End(cli.Warning)
+		return nil, true
+	}
+
+	isSupportedPlugin := false
+	for _, np := range supportedNetworkPlugins {
+		if clusterNetwork.NetworkPlugin == np {
+			isSupportedPlugin = true
+			break
+		}
+	}
+
+	if !isSupportedPlugin {
+		status.QueueFailureMessageWithCode(codeCNIUnsupported,
+			fmt.Sprintf("The detected CNI network plugin (%q) is not supported by Submariner."+
+				" Supported network plugins: %v", clusterNetwork.NetworkPlugin, supportedNetworkPlugins))
+	} else {
+		status.QueueSuccessMessage(fmt.Sprintf("The detected CNI network plugin (%q) is supported by Submariner",
+			clusterNetwork.NetworkPlugin))
+	}
+
+	if !clusterNetwork.IsComplete() {
+		status.QueueWarningMessageWithCode(codeCIDRsNotDiscovered, "The pod and service CIDRs couldn't all be discovered; the"+
+			" missing CIDRs will have to be specified when joining")
+	}
+
+	result := status.ResultFromMessages()
+	status.End(result)
+
+	return clusterNetwork, result != cli.Failure
+}
+
+// checkPreflightCIDRs checks that the pod and service CIDRs of the clusters don't overlap, since the clusters could
+// then only be connected with Globalnet
+func checkPreflightCIDRs(brokerNetwork, memberNetwork *network.ClusterNetwork) bool {
+	status.Start("Checking that the CIDRs of the broker and member candidate clusters don't overlap")
+
+	brokerCIDRs := append(append([]string{}, brokerNetwork.PodCIDRs...), brokerNetwork.ServiceCIDRs...)
+	memberCIDRs := append(append([]string{}, memberNetwork.PodCIDRs...), memberNetwork.ServiceCIDRs...)
+
+	for _, subnet := range memberCIDRs {
+		overlap, err := cidr.IsOverlapping(brokerCIDRs, subnet)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeCIDRInvalid, fmt.Sprintf("Error parsing CIDR in the member candidate cluster: %s", err))
+			continue
+		}
+
+		if overlap {
+			status.QueueFailureMessageWithCode(codePreflightCIDROverlap,
+				fmt.Sprintf("CIDR %q in the member candidate cluster overlaps with the broker"+
+					" candidate cluster (CIDRs: %v); if both clusters join, Globalnet must be enabled when deploying the broker",
+					subnet, brokerCIDRs))
+		}
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("The CIDRs of the clusters don't overlap")
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
@@ -0,0 +1,92 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/submariner-io/admiral/pkg/stringset"
+)
+
+const (
+	fullProfile     = "full"
+	networkProfile  = "network"
+	platformProfile = "platform"
+	securityProfile = "security"
+)
+
+var diagnoseProfile string
+
+// diagnoseProfiles maps the named profiles to the checks they select, by name; the full profile selects every check
+var diagnoseProfiles = map[string][]string{
+	networkProfile: {
+		"cni", "connections", "gateway-ports", "gateway-loadbalancer", "health-check", "overlapping-cidrs",
+		"active-gateways", "globalnet-consistency", "globalnet-utilization", "globalnet-egress-ips", "cidr-drift",
+		"endpoint-subnets", "kube-proxy-mode", "kube-proxy-presence", "firewall-metrics", "firewall-vxlan",
+		"endpoint-ips", "nat-traversal", "gateway-egress", "mtu", "service-discovery", "lighthouse-dns",
+		"service-discovery-consistency", "clusterset-domains", "node-cidr-overlaps",
+	},
+	platformProfile: {
+		"k8s-version", "finalizers", "webhooks", "reconcile", "pods", "operator-leader", "operator-csv",
+		"component-versions", "kernel-modules", "broker-checksum", "gateway-ha-mode",
+	},
+	securityProfile: {
+		"webhooks", "pod-security-labels", "pod-privileges", "certificates", "ipsec-ciphers", "wireguard",
+		"export-scope",
+	},
+}
+
+func addProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&diagnoseProfile, "profile", fullProfile,
+		fmt.Sprintf("only run the checks of the given profile (%s)", strings.Join(diagnoseProfileNames(), ", ")))
+}
+
+// diagnoseProfileNames returns the names of the known profiles, sorted, followed by the full profile
+func diagnoseProfileNames() []string {
+	names := make([]string, 0, len(diagnoseProfiles)+1)
+	for name := range diagnoseProfiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return append(names, fullProfile)
+}
+
+// selectDiagnoseProfile returns the names of the checks selected by the given profile, or nil if it selects every check
+func selectDiagnoseProfile(profile string) (stringset.Interface, error) {
+	if profile == fullProfile {
+		return nil, nil
+	}
+
+	names, ok := diagnoseProfiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q, the known profiles are %s", profile,
+			strings.Join(diagnoseProfileNames(), ", "))
+	}
+
+	return stringset.New(names...), nil
+}
+
+// inDiagnoseProfile returns true if the check is selected by the profile checks, as returned by selectDiagnoseProfile
+func inDiagnoseProfile(check *diagnoseCheck, profileChecks stringset.Interface) bool {
+	return profileChecks == nil || profileChecks.Contains(check.Name)
+}
@@ -0,0 +1,132 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var reconcileTimeout time.Duration
+
+var validateReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Check that the operator reconciles the Submariner resource",
+	Long: "This command compares the generation of the Submariner resource with the generation last observed by the" +
+		" operator in its status. If the operator lags behind, it waits for it to catch up, and warns about the gap if" +
+		" it doesn't within the reconcile timeout.",
+	Run: validateReconcile,
+}
+
+func init() {
+	addReconcileTimeoutFlag(validateReconcileCmd)
+	validateCmd.AddCommand(validateReconcileCmd)
+}
+
+func addReconcileTimeoutFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&reconcileTimeout, "reconcile-timeout", 30*time.Second,
+		"how long to wait for the operator to observe the latest generation of the Submariner resource")
+}
+
+func validateReconcile(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateReconcileInCluster(clients, item.clusterName, submariner) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateReconcileInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking that the operator reconciles the Submariner resource in cluster %q", clusterName))
+
+	if submariner.Status.ObservedGeneration == 0 {
+		status.QueueWarningMessageWithCode(codeObservedGenerationNotReported,
+			"The operator doesn't report the generation of the Submariner resource it last"+
+				" observed; it may be older than this version of subctl")
+		status.End(cli.Warning)
+
+		return true
+	}
+
+	if submariner.Status.ObservedGeneration < submariner.Generation {
+		latest := submariner
+
+		err := wait.PollImmediate(time.Second, reconcileTimeout, func() (bool, error) {
+			current, err := getSubmarinerResourceWithClient(clients.operatorClient)
+			if err != nil {
+				return false, err
+			}
+
+			latest = current
+
+			return current.Status.ObservedGeneration >= current.Generation, nil
+		})
+		if err != nil && err != wait.ErrWaitTimeout {
+			status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the Submariner resource: %s", err))
+			status.End(cli.Failure)
+
+			return false
+		}
+
+		submariner = latest
+	}
+
+	if gap := submariner.Generation - submariner.Status.ObservedGeneration; gap > 0 {
+		status.QueueWarningMessageWithCode(codeReconcileLagging,
+			fmt.Sprintf("The operator appears to be lagging: it has observed generation %d of the"+
+				" Submariner resource, %d behind its generation %d, after waiting %s", submariner.Status.ObservedGeneration, gap,
+				submariner.Generation, reconcileTimeout))
+	} else {
+		status.QueueSuccessMessage(fmt.Sprintf("The operator has observed the latest generation (%d) of the Submariner resource",
+			submariner.Generation))
+	}
+
+	result := status.ResultFromMessages()
+	status.End(result)
+
+	return result != cli.Failure
+}
@@ -0,0 +1,268 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	textOutputFormat     = "text"
+	markdownOutputFormat = "markdown"
+	// only supported with --list-checks
+	jsonOutputFormat = "json"
+
+	acrossClustersGroup = "Across clusters"
+	diagnoseRunGroup    = "Diagnose run"
+)
+
+// The maximum time taken to post the results to the report URL
+const reportPostTimeout = 30 * time.Second
+
+// The environment variable holding the Authorization header to post the results with, when it isn't given as a flag
+const reportAuthorizationEnv = "SUBCTL_REPORT_AUTH"
+
+var resultNames = map[cli.Result]string{
+	cli.Success: "pass", cli.Warning: "warning", cli.Failure: "fail", cli.Skipped: "skipped",
+}
+
+// jsonReport is the structure of the results posted to the report URL
+type jsonReport struct {
+	Passed bool              `json:"passed"`
+	Groups []jsonReportGroup `json:"groups"`
+}
+
+type jsonReportGroup struct {
+	Name   string            `json:"name"`
+	Checks []jsonReportCheck `json:"checks"`
+}
+
+type jsonReportCheck struct {
+	Title     string   `json:"title"`
+	Result    string   `json:"result"`
+	Failures  []string `json:"failures,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Successes []string `json:"successes,omitempty"`
+	// The codes of the failures and warnings, in the same order
+	FailureCodes []string `json:"failureCodes,omitempty"`
+	WarningCodes []string `json:"warningCodes,omitempty"`
+}
+
+// reportGroup is a section of the report, holding the phases run for a cluster
+type reportGroup struct {
+	name   string
+	phases []cli.PhaseRecord
+}
+
+// printMarkdownReport prints the recorded phases as Markdown tables grouped by cluster; the phases from checkPhases
+// on are about the diagnose run itself rather than the clusters
+func printMarkdownReport(phases []cli.PhaseRecord, checkPhases int) {
+	groups := groupPhasesByCluster(phases[:checkPhases])
+	if checkPhases < len(phases) {
+		groups = append(groups, reportGroup{name: diagnoseRunGroup, phases: phases[checkPhases:]})
+	}
+
+	fmt.Println("# Submariner diagnose report")
+
+	for _, group := range groups {
+		fmt.Printf("\n## %s\n\n", group.name)
+		fmt.Println("| Check | Result | Details |")
+		fmt.Println("| --- | --- | --- |")
+
+		for i := range group.phases {
+			phase := &group.phases[i]
+			fmt.Printf("| %s | %s | %s |\n", markdownCell(phase.Title), markdownResult(phase.Result),
+				markdownDetails(phase))
+		}
+	}
+}
+
+// groupPhasesByCluster assigns each phase to the cluster recorded when it started; phases which checked several
+// clusters or none in particular are grouped separately
+func groupPhasesByCluster(phases []cli.PhaseRecord) []reportGroup {
+	groups := []reportGroup{}
+	indexes := map[string]int{}
+
+	for i := range phases {
+		current := acrossClustersGroup
+		if phases[i].Cluster != "" {
+			current = fmt.Sprintf("Cluster %q", phases[i].Cluster)
+		}
+
+		index, ok := indexes[current]
+		if !ok {
+			index = len(groups)
+			indexes[current] = index
+			groups = append(groups, reportGroup{name: current})
+		}
+
+		groups[index].phases = append(groups[index].phases, phases[i])
+	}
+
+	return groups
+}
+
+func markdownResult(result cli.Result) string {
+	switch result {
+	case cli.Failure:
+		return "❌ Fail"
+	case cli.Warning:
+		return "⚠️ Warning"
+	case cli.Skipped:
+		return "➖ Skipped"
+	default:
+		return "✅ Pass"
+	}
+}
+
+// markdownDetails lists the messages of the phase in a single cell, failures first
+func markdownDetails(phase *cli.PhaseRecord) string {
+	details := []string{}
+
+	for i, message := range phase.Failures {
+		details = append(details, "✗ "+markdownCell(cli.WithCode(phase.FailureCodes[i], message)))
+	}
+
+	for i, message := range phase.Warnings {
+		details = append(details, "⚠ "+markdownCell(cli.WithCode(phase.WarningCodes[i], message)))
+	}
+
+	// The messages of a skipped phase explain why it was skipped
+	successPrefix := "✓ "
+	if phase.Result == cli.Skipped {
+		successPrefix = "- "
+	}
+
+	for _, message := range phase.Successes {
+		details = append(details, successPrefix+markdownCell(message))
+	}
+
+	return strings.Join(details, "<br>")
+}
+
+// markdownCell escapes the text so that it fits in a table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "|", "\\|")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+// newJSONReport builds the JSON report of the recorded phases grouped by cluster, like the Markdown report
+func newJSONReport(phases []cli.PhaseRecord, checkPhases int, passed bool) *jsonReport {
+	groups := groupPhasesByCluster(phases[:checkPhases])
+	if checkPhases < len(phases) {
+		groups = append(groups, reportGroup{name: diagnoseRunGroup, phases: phases[checkPhases:]})
+	}
+
+	report := &jsonReport{Passed: passed, Groups: []jsonReportGroup{}}
+
+	for _, group := range groups {
+		jsonGroup := jsonReportGroup{Name: group.name}
+
+		for i := range group.phases {
+			phase := &group.phases[i]
+			jsonGroup.Checks = append(jsonGroup.Checks, jsonReportCheck{
+				Title:     phase.Title,
+				Result:    resultNames[phase.Result],
+				Failures:  phase.Failures,
+				Warnings:  phase.Warnings,
+				Successes: phase.Successes,
+
+				FailureCodes: reportedCodes(phase.FailureCodes, codeUnclassifiedFailure),
+				WarningCodes: reportedCodes(phase.WarningCodes, codeUnclassifiedWarning),
+			})
+		}
+
+		report.Groups = append(report.Groups, jsonGroup)
+	}
+
+	return report
+}
+
+// reportedCodes returns the codes of the messages, with the given code for those which have none
+func reportedCodes(codes []string, unclassified string) []string {
+	reported := make([]string, len(codes))
+	for i, code := range codes {
+		reported[i] = codeOrDefault(code, unclassified)
+	}
+
+	return reported
+}
+
+// reportAuthorization returns the Authorization header to post the results with: the given value, else the contents of
+// the given file, else the value of the reportAuthorizationEnv environment variable
+func reportAuthorization(authorization, authorizationFile string) (string, error) {
+	if authorization != "" && authorizationFile != "" {
+		return "", fmt.Errorf("--report-auth-header and --report-auth-header-file are mutually exclusive")
+	}
+
+	if authorization != "" {
+		return authorization, nil
+	}
+
+	if authorizationFile != "" {
+		contents, err := ioutil.ReadFile(authorizationFile)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(string(contents)), nil
+	}
+
+	return os.Getenv(reportAuthorizationEnv), nil
+}
+
+// postJSONReport posts the report as JSON to the given URL, with the given Authorization header if any
+func postJSONReport(url, authorization string, report *jsonReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	response, err := (&http.Client{Timeout: reportPostTimeout}).Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("the report URL responded with %q", response.Status)
+	}
+
+	return nil
+}
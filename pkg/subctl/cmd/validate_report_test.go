@@ -0,0 +1,119 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+func TestGroupPhasesByCluster(t *testing.T) {
+	// The titles name other clusters, e.g. a cluster whose name is part of another's, which mustn't affect the groups
+	phases := []cli.PhaseRecord{
+		{Title: "Checking the Submariner pods in \"east\"", Cluster: "east"},
+		{Title: "Checking the connection to \"east-2\"", Cluster: "east"},
+		{Title: "Checking the Submariner pods in \"east-2\"", Cluster: "east-2"},
+		{Title: "Checking that the CIDRs of \"east\" and \"east-2\" don't overlap"},
+		{Title: "Checking the gateway", Cluster: "east"},
+	}
+
+	groups := groupPhasesByCluster(phases)
+
+	expected := []struct {
+		name   string
+		titles []string
+	}{
+		{"Cluster \"east\"", []string{phases[0].Title, phases[1].Title, phases[4].Title}},
+		{"Cluster \"east-2\"", []string{phases[2].Title}},
+		{acrossClustersGroup, []string{phases[3].Title}},
+	}
+
+	if len(groups) != len(expected) {
+		t.Fatalf("Expected %d groups, got %d: %+v", len(expected), len(groups), groups)
+	}
+
+	for i := range expected {
+		if groups[i].name != expected[i].name {
+			t.Errorf("Expected group %d to be %q, got %q", i, expected[i].name, groups[i].name)
+			continue
+		}
+
+		if len(groups[i].phases) != len(expected[i].titles) {
+			t.Errorf("Expected %d phases in group %q, got %d", len(expected[i].titles), groups[i].name,
+				len(groups[i].phases))
+			continue
+		}
+
+		for j, title := range expected[i].titles {
+			if groups[i].phases[j].Title != title {
+				t.Errorf("Expected phase %d of group %q to be %q, got %q", j, groups[i].name, title,
+					groups[i].phases[j].Title)
+			}
+		}
+	}
+}
+
+func TestReportAuthorization(t *testing.T) {
+	authorizationFile, err := ioutil.TempFile("", "report-auth")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.Remove(authorizationFile.Name())
+
+	if _, err := authorizationFile.WriteString("Bearer from-file\n"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	authorizationFile.Close()
+
+	defer os.Unsetenv(reportAuthorizationEnv)
+
+	if err := os.Setenv(reportAuthorizationEnv, "Bearer from-env"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		authorization     string
+		authorizationFile string
+		expected          string
+		valid             bool
+	}{
+		{authorization: "Bearer from-flag", expected: "Bearer from-flag", valid: true},
+		{authorizationFile: authorizationFile.Name(), expected: "Bearer from-file", valid: true},
+		{expected: "Bearer from-env", valid: true},
+		{authorization: "Bearer from-flag", authorizationFile: authorizationFile.Name()},
+		{authorizationFile: authorizationFile.Name() + "-missing"},
+	}
+
+	for i := range tests {
+		test := &tests[i]
+
+		authorization, err := reportAuthorization(test.authorization, test.authorizationFile)
+		if test.valid && (err != nil || authorization != test.expected) {
+			t.Errorf("Expected %q from %q and %q, got %q, %v", test.expected, test.authorization, test.authorizationFile,
+				authorization, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("Expected an error from %q and %q, got %q", test.authorization, test.authorizationFile, authorization)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/spf13/cobra"
+	lhconstants "github.com/submariner-io/lighthouse/pkg/constants"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	mcsv1a1 "sigs.k8s.io/mcs-api/pkg/apis/v1alpha1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateServiceDiscoveryCmd = &cobra.Command{
+	Use:   "service-discovery",
+	Short: "Check the service discovery configuration",
+	Long: "This command checks that the service discovery components are configured consistently with the rest of" +
+		" Submariner and, when given several kubecontexts, that a sample of the exported services are imported by" +
+		" the other clusters.",
+	Run: validateServiceDiscovery,
+}
+
+var serviceExportsSampleSize int
+
+// serviceDiscoveryCluster is a cluster with service discovery enabled
+type serviceDiscoveryCluster struct {
+	name      string
+	clusterID string
+	config    *rest.Config
+}
+
+var serviceImportsGVR = schema.GroupVersionResource{
+	Group:    mcsv1a1.GroupName,
+	Version:  mcsv1a1.GroupVersion.Version,
+	Resource: "serviceimports",
+}
+
+var serviceExportsGVR = schema.GroupVersionResource{
+	Group:    mcsv1a1.GroupName,
+	Version:  mcsv1a1.GroupVersion.Version,
+	Resource: "serviceexports",
+}
+
+func init() {
+	validateServiceDiscoveryCmd.Flags().IntVar(&serviceExportsSampleSize, "sample-size", 5,
+		"maximum number of exported services per cluster whose import by the other clusters is checked")
+	validateCmd.AddCommand(validateServiceDiscoveryCmd)
+}
+
+func validateServiceDiscovery(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+	sdClusters := []serviceDiscoveryCluster{}
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateServiceDiscoveryGlobalIPsInCluster(clients, item.clusterName, submariner) && validationStatus
+
+		if submariner.Spec.ServiceDiscoveryEnabled {
+			sdClusters = append(sdClusters, serviceDiscoveryCluster{
+				name:      item.clusterName,
+				clusterID: submariner.Spec.ClusterID,
+				config:    item.config,
+			})
+		}
+	}
+
+	status.SetCluster("")
+
+	if len(sdClusters) > 1 {
+		validationStatus = validateServiceExportsImported(sdClusters) && validationStatus
+	}
+
+	finishValidation(validationStatus)
+}
+
+func validateServiceDiscoveryGlobalIPsInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking that exported services are published with global IPs in cluster %q", clusterName))
+
+	if submariner.Spec.GlobalCIDR == "" || !submariner.Spec.ServiceDiscoveryEnabled {
+		status.QueueSuccessMessage("This check is only necessary when both Globalnet and service discovery are enabled")
+		status.End(cli.Success)
+		return true
+	}
+
+	_, globalCIDR, err := net.ParseCIDR(submariner.Spec.GlobalCIDR)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeGlobalCIDRInvalid, fmt.Sprintf("Error parsing the global CIDR %q: %s",
+			submariner.Spec.GlobalCIDR, err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	dynClient, _ := clients.dynamicClient, clients.kubeClient
+
+	// The ServiceImports published by the local lighthouse agent carry the local cluster ID
+	selector := labels.SelectorFromSet(map[string]string{lhconstants.LabelSourceCluster: submariner.Spec.ClusterID})
+	importList, err := dynClient.Resource(serviceImportsGVR).Namespace(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the ServiceImports: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	for i := range importList.Items {
+		serviceImport := &mcsv1a1.ServiceImport{}
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(importList.Items[i].Object, serviceImport)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeServiceImportMalformed, fmt.Sprintf("Error converting ServiceImport %q: %s",
+				importList.Items[i].GetName(), err))
+			continue
+		}
+
+		if serviceImport.Spec.Type != mcsv1a1.ClusterSetIP {
+			continue
+		}
+
+		serviceName := fmt.Sprintf("%s/%s", serviceImport.Labels[lhconstants.LabelSourceNamespace],
+			serviceImport.Labels[lhconstants.LabelSourceName])
+
+		for _, ip := range serviceImport.Spec.IPs {
+			if !globalCIDR.Contains(net.ParseIP(ip)) {
+				status.QueueFailureMessageWithCode(codeServiceImportNotGlobal, fmt.Sprintf("The exported service %q is"+
+					" published with IP %q which is not a global IP from %q", serviceName, ip, submariner.Spec.GlobalCIDR))
+			}
+		}
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage("All exported services are published with global IPs")
+	status.End(cli.Success)
+	return true
+}
+
+// validateServiceExportsImported checks that the EndpointSlices of a sample of the services exported from each
+// cluster have been synced to the other clusters, which shows that lighthouse is actually syncing
+func validateServiceExportsImported(clusters []serviceDiscoveryCluster) bool {
+	status.Start("Checking that the exported services are imported by the other clusters")
+
+	clientSets := make([]kubernetes.Interface, len(clusters))
+
+	for i := range clusters {
+		clientSet, err := kubernetes.NewForConfig(clusters[i].config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+			status.End(cli.Failure)
+			return false
+		}
+
+		clientSets[i] = clientSet
+	}
+
+	checked := 0
+
+	for i := range clusters {
+		exports, err := sampleServiceExports(clusters[i].config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the ServiceExports in cluster"+
+				" %q: %s", clusters[i].name, err))
+			continue
+		}
+
+		for _, export := range exports {
+			for j := range clusters {
+				if i != j {
+					checkServiceExportImported(clientSets[j], clusters[j].name, clusters[i].clusterID, export)
+					checked++
+				}
+			}
+		}
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("Checked %d service imports", checked))
+	status.End(status.ResultFromMessages())
+	return true
+}
+
+// sampleServiceExports returns, sorted by namespace and name, up to the sample size ServiceExports of a cluster
+func sampleServiceExports(config *rest.Config) ([]types.NamespacedName, error) {
+	dynClient, _, err := getClients(config)
+	if err != nil {
+		return nil, err
+	}
+
+	exportList, err := dynClient.Resource(serviceExportsGVR).Namespace(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	exports := []types.NamespacedName{}
+	for i := range exportList.Items {
+		exports = append(exports, types.NamespacedName{
+			Namespace: exportList.Items[i].GetNamespace(),
+			Name:      exportList.Items[i].GetName(),
+		})
+	}
+
+	sort.Slice(exports, func(i, j int) bool {
+		return exports[i].String() < exports[j].String()
+	})
+
+	if len(exports) > serviceExportsSampleSize {
+		exports = exports[:serviceExportsSampleSize]
+	}
+
+	return exports, nil
+}
+
+func checkServiceExportImported(clientSet kubernetes.Interface, clusterName, sourceClusterID string, export types.NamespacedName) {
+	selector := labels.SelectorFromSet(map[string]string{
+		discoveryv1beta1.LabelManagedBy:  lhconstants.LabelValueManagedBy,
+		lhconstants.LabelSourceName:      export.Name,
+		lhconstants.LabelSourceNamespace: export.Namespace,
+		lhconstants.LabelSourceCluster:   sourceClusterID,
+	})
+
+	slices, err := clientSet.DiscoveryV1beta1().EndpointSlices(metav1.NamespaceAll).List(context.TODO(),
+		metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the EndpointSlices in cluster %q: %s", clusterName, err))
+		return
+	}
+
+	if len(slices.Items) == 0 {
+		status.QueueFailureMessageWithCode(codeServiceNotImported, fmt.Sprintf("The service %q exported from cluster %q has"+
+			" not been imported by cluster %q", export, sourceClusterID, clusterName))
+		return
+	}
+
+	for i := range slices.Items {
+		if len(slices.Items[i].Endpoints) > 0 {
+			return
+		}
+	}
+
+	status.QueueWarningMessageWithCode(codeServiceImportNoEndpoints, fmt.Sprintf("The service %q exported from cluster %q"+
+		" has no endpoints in cluster %q", export, sourceClusterID, clusterName))
+}
@@ -0,0 +1,132 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/datafile"
+)
+
+var validateServiceDiscoveryConsistencyCmd = &cobra.Command{
+	Use:   "service-discovery-consistency",
+	Short: "Check that service discovery is either enabled or disabled in all the clusters",
+	Long: "This command checks that the clusters agree on whether service discovery is enabled, and reports the" +
+		" clusters which differ from the broker setting, if a broker-info.subm file is given, or from the majority.",
+	Run: validateServiceDiscoveryConsistency,
+}
+
+var serviceDiscoveryBrokerInfo string
+
+func init() {
+	addServiceDiscoveryConsistencyFlags(validateServiceDiscoveryConsistencyCmd)
+	validateCmd.AddCommand(validateServiceDiscoveryConsistencyCmd)
+}
+
+func addServiceDiscoveryConsistencyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&serviceDiscoveryBrokerInfo, "broker-info", "",
+		"broker-info.subm file whose service discovery setting is the intended one")
+}
+
+func validateServiceDiscoveryConsistency(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	finishValidation(validateServiceDiscoveryConsistencyAcrossClusters(configs))
+}
+
+// validateServiceDiscoveryConsistencyAcrossClusters compares the ServiceDiscoveryEnabled setting of the clusters with
+// the broker setting, or failing that with the setting of the majority of the clusters
+func validateServiceDiscoveryConsistencyAcrossClusters(configs []restConfig) bool {
+	status.Start("Checking that service discovery is enabled consistently across the clusters")
+
+	enabledClusters := []string{}
+	disabledClusters := []string{}
+
+	for _, item := range configs {
+		submariner, err := retrieveSubmariner(item.config)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeAPIGetFailed, fmt.Sprintf("Error obtaining the Submariner resource from"+
+				" cluster %q: %s", item.clusterName, err))
+			continue
+		}
+
+		if submariner == nil {
+			if requireInstalled {
+				status.QueueFailureMessageWithCode(codeSubmarinerNotInstalled, fmt.Sprintf("Submariner is not installed in"+
+					" cluster %q", item.clusterName))
+			}
+
+			continue
+		}
+
+		if submariner.Spec.ServiceDiscoveryEnabled {
+			enabledClusters = append(enabledClusters, item.clusterName)
+		} else {
+			disabledClusters = append(disabledClusters, item.clusterName)
+		}
+	}
+
+	sort.Strings(enabledClusters)
+	sort.Strings(disabledClusters)
+
+	if serviceDiscoveryBrokerInfo != "" {
+		brokerInfo, err := datafile.NewFromFile(serviceDiscoveryBrokerInfo)
+		if err != nil {
+			status.QueueFailureMessageWithCode(codeInputInvalid, fmt.Sprintf("Error reading the broker info file"+
+				" %q: %s", serviceDiscoveryBrokerInfo, err))
+			status.End(cli.Failure)
+			return false
+		}
+
+		if brokerInfo.IsServiceDiscoveryEnabled() && len(disabledClusters) > 0 {
+			status.QueueFailureMessageWithCode(codeServiceDiscoveryInconsistent,
+				fmt.Sprintf("Service discovery is enabled in the broker but disabled in clusters %v",
+					disabledClusters))
+		} else if !brokerInfo.IsServiceDiscoveryEnabled() && len(enabledClusters) > 0 {
+			status.QueueFailureMessageWithCode(codeServiceDiscoveryInconsistent,
+				fmt.Sprintf("Service discovery is disabled in the broker but enabled in clusters %v",
+					enabledClusters))
+		}
+	} else if len(enabledClusters) > 0 && len(disabledClusters) > 0 {
+		// Point out the smaller group, which is the most likely to be misconfigured
+		if len(enabledClusters) <= len(disabledClusters) {
+			status.QueueFailureMessageWithCode(codeServiceDiscoveryInconsistent,
+				fmt.Sprintf("Service discovery is enabled in clusters %v but disabled in the other"+
+					" clusters %v", enabledClusters, disabledClusters))
+		} else {
+			status.QueueFailureMessageWithCode(codeServiceDiscoveryInconsistent,
+				fmt.Sprintf("Service discovery is disabled in clusters %v but enabled in the other"+
+					" clusters %v", disabledClusters, enabledClusters))
+		}
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage(fmt.Sprintf("All the %d clusters use the same service discovery setting",
+		len(enabledClusters)+len(disabledClusters)))
+	status.End(cli.Success)
+	return true
+}
@@ -0,0 +1,218 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const sourceIPCheckPort = 8080
+
+// tcpdumpSourceIP matches the source address of a packet in the output of tcpdump -n
+var tcpdumpSourceIP = regexp.MustCompile(`IP (\d+\.\d+\.\d+\.\d+)\.\d+ > `)
+
+var validateSourceIPCmd = &cobra.Command{
+	Use:   "source-ip <localkubeconfig> <remotekubeconfig>",
+	Short: "Check that the source IPs of inter-cluster traffic are preserved",
+	Long: "This command connects from a pod in the remote cluster to a pod in the local cluster, and checks that the" +
+		" connection arrives from the remote pod's IP rather than being SNAT'd, e.g. to a node IP, by the CNI or" +
+		" the node's iptables rules. It doesn't apply to clusters using Globalnet, which translates source IPs.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("two kubeconfigs must be specified")
+		}
+		same, err := compareFiles(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		if same {
+			return fmt.Errorf("the specified kubeconfig files are the same")
+		}
+		return nil
+	},
+	Run: validateSourceIP,
+}
+
+func init() {
+	addValidateFWConfigFlags(validateSourceIPCmd)
+	validateSourceIPCmd.Flags().BoolVar(&verboseOutput, "verbose", false,
+		"produce verbose logs during validation")
+	validateCmd.AddCommand(validateSourceIPCmd)
+}
+
+func validateSourceIP(cmd *cobra.Command, args []string) {
+	localCfg, err := getRestConfig(args[0], "")
+	exitOnError("The provided local kubeconfig is invalid", err)
+
+	remoteCfg, err := getRestConfig(args[1], "")
+	exitOnError("The provided remote kubeconfig is invalid", err)
+
+	finishValidation(validateSourceIPAcrossClusters(localCfg, remoteCfg))
+}
+
+func validateSourceIPAcrossClusters(localCfg, remoteCfg *rest.Config) bool {
+	localSubmariner := getSubmarinerResource(localCfg)
+	if localSubmariner == nil {
+		exitWithErrorMsg(submMissingMessage)
+	}
+
+	remoteSubmariner := getSubmarinerResource(remoteCfg)
+	if remoteSubmariner == nil {
+		exitWithErrorMsg(submMissingMessage)
+	}
+
+	status.Start(fmt.Sprintf("Checking that the source IPs of the traffic from cluster %q to cluster %q are preserved",
+		remoteSubmariner.Spec.ClusterID, localSubmariner.Spec.ClusterID))
+
+	if skipInReadOnlyMode("source IP", localSubmariner.Spec.ClusterID) {
+		return true
+	}
+
+	if localSubmariner.Spec.GlobalCIDR != "" || remoteSubmariner.Spec.GlobalCIDR != "" {
+		status.QueueSuccessMessage("Globalnet is enabled, so source IPs are expected to be translated")
+		status.End(cli.Success)
+		return true
+	}
+
+	lClientSet, err := kubernetes.NewForConfig(localCfg)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	rClientSet, err := kubernetes.NewForConfig(remoteCfg)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, fmt.Sprintf("Error creating API server client: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	observed, expected, err := observeSourceIP(lClientSet, rClientSet)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, err.Error())
+		status.End(cli.Failure)
+		return false
+	}
+
+	if observed == "" {
+		status.QueueFailureMessageWithCode(codeSourceIPNotReceived, fmt.Sprintf("The connection from the remote pod %s"+
+			" wasn't received within %d seconds; please check the connectivity between the clusters", expected, validationTimeout))
+		status.End(cli.Failure)
+		return false
+	}
+
+	if observed == expected {
+		status.QueueSuccessMessage(fmt.Sprintf("The connection was received from the remote pod's IP %s", expected))
+		status.End(cli.Success)
+		return true
+	}
+
+	if nodeName := findNodeWithIP(rClientSet, observed); nodeName != "" {
+		status.QueueFailureMessageWithCode(codeSourceIPNATed, fmt.Sprintf("The connection from the remote pod %s was"+
+			" received from %s, the IP of node %q in the remote cluster; the traffic is being SNAT'd", expected, observed, nodeName))
+	} else {
+		status.QueueFailureMessageWithCode(codeSourceIPNATed, fmt.Sprintf("The connection from the remote pod %s was"+
+			" received from %s; the traffic is being SNAT'd", expected, observed))
+	}
+
+	status.End(cli.Failure)
+	return false
+}
+
+// observeSourceIP connects from a pod in the remote cluster to a pod in the local cluster, and returns the source IP
+// seen on the local pod's node along with the remote pod's IP
+func observeSourceIP(lClientSet, rClientSet *kubernetes.Clientset) (observed, expected string, err error) {
+	lPod, err := spawnClientPodOnNonGatewayNode(lClientSet, namespace,
+		fmt.Sprintf("timeout %d nc -l -p %d", validationTimeout, sourceIPCheckPort))
+	if err != nil {
+		return "", "", fmt.Errorf("error while spawning the listening pod on a non-Gateway node: %v", err)
+	}
+
+	defer lPod.DeletePod()
+
+	podCommand := fmt.Sprintf("timeout %d tcpdump -ln -c 1 -i any tcp and dst host %s and dst port %d"+
+		" and 'tcp[tcpflags] == tcp-syn'", validationTimeout, lPod.Pod.Status.PodIP, sourceIPCheckPort)
+	sPod, err := spawnSnifferPodOnNode(lClientSet, lPod.Pod.Spec.NodeName, namespace, podCommand)
+	if err != nil {
+		return "", "", fmt.Errorf("error while spawning the sniffer pod on node %q: %v", lPod.Pod.Spec.NodeName, err)
+	}
+
+	defer sPod.DeletePod()
+
+	cPod, err := spawnClientPodOnNonGatewayNode(rClientSet, namespace,
+		fmt.Sprintf("nc -w %d %s %d </dev/null", validationTimeout/2, lPod.Pod.Status.PodIP, sourceIPCheckPort))
+	if err != nil {
+		return "", "", fmt.Errorf("error while spawning the client pod on a non-Gateway node: %v", err)
+	}
+
+	defer cPod.DeletePod()
+
+	if err = cPod.AwaitPodCompletion(); err != nil {
+		return "", "", fmt.Errorf("error while waiting for the client pod to finish its execution: %v", err)
+	}
+
+	if err = sPod.AwaitPodCompletion(); err != nil {
+		return "", "", fmt.Errorf("error while waiting for the sniffer pod to finish its execution: %v", err)
+	}
+
+	if verboseOutput {
+		status.QueueSuccessMessage("tcpdump output from the sniffer pod")
+		status.QueueSuccessMessage(sPod.PodOutput)
+	}
+
+	return parseTcpdumpSourceIP(sPod.PodOutput), cPod.Pod.Status.PodIP, nil
+}
+
+// parseTcpdumpSourceIP returns the source IP of the first packet in the tcpdump output
+func parseTcpdumpSourceIP(output string) string {
+	match := tcpdumpSourceIP.FindStringSubmatch(output)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
+
+// findNodeWithIP returns the name of the node with the given address, if any
+func findNodeWithIP(clientSet kubernetes.Interface, ip string) string {
+	nodes, err := clientSet.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return ""
+	}
+
+	for i := range nodes.Items {
+		for _, address := range nodes.Items[i].Status.Addresses {
+			if (address.Type == v1.NodeInternalIP || address.Type == v1.NodeExternalIP) && address.Address == ip {
+				return nodes.Items[i].Name
+			}
+		}
+	}
+
+	return ""
+}
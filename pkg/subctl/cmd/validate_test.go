@@ -0,0 +1,70 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDiagnoseSeparatorIsSuppressedWhenQuiet(t *testing.T) {
+	previousOutput, previousQuiet := diagnoseOutput, diagnoseQuiet
+	defer func() { diagnoseOutput, diagnoseQuiet = previousOutput, previousQuiet }()
+
+	var output bytes.Buffer
+	diagnoseOutput = &output
+
+	diagnoseQuiet = true
+	diagnoseSeparator()
+
+	if output.Len() != 0 {
+		t.Errorf("Expected no output in quiet mode, got %q", output.String())
+	}
+
+	diagnoseQuiet = false
+	diagnoseSeparator()
+
+	if output.String() != "\n" {
+		t.Errorf("Expected a blank line, got %q", output.String())
+	}
+}
+
+func TestCloseDiagnoseOutputFile(t *testing.T) {
+	outputFile, err := ioutil.TempFile("", "diagnose-output")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.Remove(outputFile.Name())
+
+	diagnoseOutputFileWriter = outputFile
+
+	closeDiagnoseOutputFile()
+
+	if diagnoseOutputFileWriter != nil {
+		t.Errorf("The output file is still set once closed")
+	}
+
+	if _, err := outputFile.WriteString("checks"); err == nil {
+		t.Errorf("The output file wasn't closed")
+	}
+
+	// Closing it again, e.g. once the command has run after finishing the validation, does nothing
+	closeDiagnoseOutputFile()
+}
@@ -0,0 +1,206 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	maxThrottlingRetries   = 5
+	initialThrottlingDelay = time.Second
+	minThrottlingDelay     = 500 * time.Millisecond
+	maxThrottlingDelay     = 30 * time.Second
+)
+
+// clusterThrottling records how an API server throttled the diagnose requests
+type clusterThrottling struct {
+	// the number of throttled responses, including those which were retried successfully
+	throttled int
+	// the number of requests which were still throttled once the retries were exhausted
+	exhausted int
+	// the longest Retry-After hint returned by the API server
+	maxRetryAfter time.Duration
+}
+
+// throttlingRecorder records the throttled requests of each cluster
+type throttlingRecorder struct {
+	sync.Mutex
+	clusters map[string]*clusterThrottling
+}
+
+// apiThrottling is set by the diagnose commands, to retry and record the requests throttled by the API servers
+var apiThrottling *throttlingRecorder
+
+// throttlingRoundTripper retries the requests which the API server throttles (HTTP 429), waiting as long as the
+// Retry-After hint, or backing off exponentially without one. It replaces the retries of the REST clients, which
+// would otherwise retry each request it gives up on up to ten more times
+type throttlingRoundTripper struct {
+	delegate    http.RoundTripper
+	clusterName string
+	recorder    *throttlingRecorder
+}
+
+func newThrottlingRecorder() *throttlingRecorder {
+	return &throttlingRecorder{clusters: map[string]*clusterThrottling{}}
+}
+
+// watchThrottling makes the clients created from the config retry and record throttled requests, when diagnosing
+func watchThrottling(config *rest.Config, clusterName string) {
+	if apiThrottling == nil {
+		return
+	}
+
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &throttlingRoundTripper{delegate: rt, clusterName: clusterName, recorder: apiThrottling}
+	})
+}
+
+func (t *throttlingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := initialThrottlingDelay
+
+	for retry := 0; ; retry++ {
+		resp, err := t.delegate.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+
+		retryAfter, hinted := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if hinted {
+			delay = retryAfter
+		}
+
+		// Requests with a body can only be retried if the body can be recreated
+		exhausted := retry >= maxThrottlingRetries || (req.Body != nil && req.GetBody == nil)
+		t.recorder.record(t.clusterName, retryAfter, exhausted)
+
+		if exhausted {
+			// Without the hint, the REST client returns the throttling error instead of retrying the request
+			resp.Header.Del("Retry-After")
+			return resp, nil
+		}
+
+		resp.Body.Close()
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		// A zero Retry-After hint mustn't turn the retries into a busy loop
+		if delay < minThrottlingDelay {
+			delay = minThrottlingDelay
+		} else if delay > maxThrottlingDelay {
+			delay = maxThrottlingDelay
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+
+		if !hinted {
+			delay *= 2
+		}
+	}
+}
+
+// parseRetryAfter parses a Retry-After header expressed in seconds
+func parseRetryAfter(header string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+func (r *throttlingRecorder) record(clusterName string, retryAfter time.Duration, exhausted bool) {
+	r.Lock()
+	defer r.Unlock()
+
+	cluster, ok := r.clusters[clusterName]
+	if !ok {
+		cluster = &clusterThrottling{}
+		r.clusters[clusterName] = cluster
+	}
+
+	cluster.throttled++
+
+	if exhausted {
+		cluster.exhausted++
+	}
+
+	if retryAfter > cluster.maxRetryAfter {
+		cluster.maxRetryAfter = retryAfter
+	}
+}
+
+// reportThrottling reports, as warnings distinct from the check results, the clusters whose API server throttled
+// the diagnose requests
+func reportThrottling() {
+	if apiThrottling == nil {
+		return
+	}
+
+	apiThrottling.Lock()
+	defer apiThrottling.Unlock()
+
+	if len(apiThrottling.clusters) == 0 {
+		return
+	}
+
+	status.Start("Checking for API server throttling")
+
+	clusterNames := make([]string, 0, len(apiThrottling.clusters))
+	for clusterName := range apiThrottling.clusters {
+		clusterNames = append(clusterNames, clusterName)
+	}
+
+	sort.Strings(clusterNames)
+
+	for _, clusterName := range clusterNames {
+		cluster := apiThrottling.clusters[clusterName]
+
+		message := fmt.Sprintf("The API server of cluster %q throttled %d requests", clusterName, cluster.throttled)
+		if cluster.maxRetryAfter > 0 {
+			message += fmt.Sprintf(", asking to retry after up to %v", cluster.maxRetryAfter)
+		}
+
+		if cluster.exhausted > 0 {
+			message += fmt.Sprintf("; %d requests were still throttled after %d retries, so the checks which failed"+
+				" with \"too many requests\" errors failed because of the throttling rather than a Submariner issue",
+				cluster.exhausted, maxThrottlingRetries)
+		}
+
+		status.QueueWarningMessageWithCode(codeAPIThrottled, message)
+	}
+
+	status.End(cli.Warning)
+}
@@ -0,0 +1,101 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// throttlingServer answers the given number of requests with HTTP 429 and the Retry-After hint, then with HTTP 200
+type throttlingServer struct {
+	throttled  int
+	retryAfter string
+	requests   int
+}
+
+func (s *throttlingServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests++
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}
+	if s.requests <= s.throttled {
+		resp.StatusCode = http.StatusTooManyRequests
+		resp.Header.Set("Retry-After", s.retryAfter)
+	}
+
+	return resp, nil
+}
+
+func TestThrottlingRoundTripperEnforcesMinimumDelay(t *testing.T) {
+	server := &throttlingServer{throttled: 1, retryAfter: "0"}
+	roundTripper := &throttlingRoundTripper{delegate: server, clusterName: "east", recorder: newThrottlingRecorder()}
+
+	req, err := http.NewRequest(http.MethodGet, "https://east.example.com:6443/api", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	start := time.Now()
+
+	resp, err := roundTripper.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || server.requests != 2 {
+		t.Fatalf("Expected the throttled request to be retried once, got status %d after %d requests", resp.StatusCode,
+			server.requests)
+	}
+
+	if elapsed := time.Since(start); elapsed < minThrottlingDelay {
+		t.Errorf("The request was retried after %v despite the minimum delay of %v", elapsed, minThrottlingDelay)
+	}
+}
+
+func TestThrottlingRoundTripperStopsClientRetries(t *testing.T) {
+	server := &throttlingServer{throttled: 1, retryAfter: "1"}
+	recorder := newThrottlingRecorder()
+	roundTripper := &throttlingRoundTripper{delegate: server, clusterName: "east", recorder: recorder}
+
+	// A request whose body can't be recreated can't be retried
+	req, err := http.NewRequest(http.MethodPost, "https://east.example.com:6443/api", ioutil.NopCloser(strings.NewReader("{}")))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := roundTripper.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests || server.requests != 1 {
+		t.Fatalf("Expected the throttled response without retries, got status %d after %d requests", resp.StatusCode,
+			server.requests)
+	}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		t.Errorf("The exhausted response kept its Retry-After hint %q, so the REST client would retry it", retryAfter)
+	}
+
+	if cluster := recorder.clusters["east"]; cluster == nil || cluster.exhausted != 1 {
+		t.Errorf("The exhausted request wasn't recorded: %+v", cluster)
+	}
+}
@@ -19,7 +19,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -74,9 +73,7 @@ func validateTunnelConfig(cmd *cobra.Command, args []string) {
 
 	validationStatus := validateTunnelConfigAcrossClusters(localCfg, remoteCfg)
 	status.End(status.ResultFromMessages())
-	if !validationStatus {
-		os.Exit(1)
-	}
+	finishValidation(validationStatus)
 }
 
 func validateTunnelConfigAcrossClusters(localCfg, remoteCfg *rest.Config) bool {
@@ -91,15 +88,19 @@ func validateTunnelConfigAcrossClusters(localCfg, remoteCfg *rest.Config) bool {
 	status.Start(fmt.Sprintf("Checking if tunnels can be setup on Gateway node of cluster %q.",
 		submariner.Spec.ClusterID))
 
+	if skipInReadOnlyMode("firewall tunnel", submariner.Spec.ClusterID) {
+		return true
+	}
+
 	localEndpoint := getEndpointResource(localCfg, submariner.Spec.ClusterID)
 	if localEndpoint == nil {
-		status.QueueWarningMessage("Could not find the local cluster Endpoint")
+		status.QueueWarningMessageWithCode(codeLocalEndpointMissing, "Could not find the local cluster Endpoint")
 		return false
 	}
 
 	gwNodeName := getActiveGatewayNodeName(lClientSet, localEndpoint.Spec.Hostname)
 	if gwNodeName == "" {
-		status.QueueWarningMessage("Could not find the active Gateway nodeName in local cluster")
+		status.QueueWarningMessageWithCode(codeNoGateway, "Could not find the active Gateway nodeName in local cluster")
 		return false
 	}
 
@@ -113,21 +114,22 @@ func validateTunnelConfigAcrossClusters(localCfg, remoteCfg *rest.Config) bool {
 		validationTimeout, tunnelPort, clientMessage)
 	sPod, err := spawnSnifferPodOnNode(lClientSet, gwNodeName, namespace, podCommand)
 	if err != nil {
-		status.QueueFailureMessage(fmt.Sprintf("Error while spawning the sniffer pod on the GatewayNode: %v", err))
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while spawning the sniffer pod on the GatewayNode: %v", err))
 		return false
 	}
 	defer sPod.DeletePod()
 
 	gatewayPodIP := getGatewayIP(remoteCfg, submariner.Spec.ClusterID)
 	if gatewayPodIP == "" {
-		status.QueueWarningMessage("Gateway object on remote cluster does not have connection info to local cluster.")
+		status.QueueWarningMessageWithCode(codeConnectionMissing,
+			"Gateway object on remote cluster does not have connection info to local cluster.")
 		return false
 	}
 
 	rClientSet, err := kubernetes.NewForConfig(remoteCfg)
 	if err != nil {
 		message := fmt.Sprintf("Error creating API server client: %s", err)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeAPIClientFailed, message)
 		return false
 	}
 
@@ -137,18 +139,20 @@ func validateTunnelConfigAcrossClusters(localCfg, remoteCfg *rest.Config) bool {
 	// sometimes drop the udp traffic from client pod until the tunnels are properly setup.
 	cPod, err := spawnClientPodOnNonGatewayNode(rClientSet, namespace, podCommand)
 	if err != nil {
-		status.QueueFailureMessage(fmt.Sprintf("Error while spawning the client pod on non-Gateway node: %v", err))
+		status.QueueFailureMessageWithCode(codeCheckPodFailed, fmt.Sprintf("Error while spawning the client pod on non-Gateway node: %v", err))
 		return false
 	}
 
 	defer cPod.DeletePod()
 	if err = cPod.AwaitPodCompletion(); err != nil {
-		status.QueueFailureMessage(fmt.Sprintf("Error while waiting for client pod to be finish its execution: %v", err))
+		status.QueueFailureMessageWithCode(codeCheckPodFailed,
+			fmt.Sprintf("Error while waiting for client pod to be finish its execution: %v", err))
 		return false
 	}
 
 	if err = sPod.AwaitPodCompletion(); err != nil {
-		status.QueueFailureMessage(fmt.Sprintf("Error while waiting for sniffer pod to be finish its execution: %v", err))
+		status.QueueFailureMessageWithCode(codeCheckPodFailed,
+			fmt.Sprintf("Error while waiting for sniffer pod to be finish its execution: %v", err))
 		return false
 	}
 
@@ -167,13 +171,13 @@ func getTunnelPort(submariner *v1alpha1.Submariner, endpoint *subv1.Endpoint) (i
 	case "libreswan", "wireguard":
 		tunnelPort, err = endpoint.Spec.GetBackendPort(subv1.UDPPortConfig, int32(submariner.Spec.CeIPSecNATTPort))
 		if err != nil {
-			status.QueueWarningMessage(fmt.Sprintf("Error reading tunnelPort: %v", err))
+			status.QueueWarningMessageWithCode(codeTunnelPortUnknown, fmt.Sprintf("Error reading tunnelPort: %v", err))
 		}
 		return tunnelPort, nil
 	default:
 		message := fmt.Sprintf("Could not determine the tunnel port for cable driver %q",
 			endpoint.Spec.Backend)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeTunnelPortUnknown, message)
 		return tunnelPort, fmt.Errorf(message)
 	}
 }
@@ -181,7 +185,7 @@ func getTunnelPort(submariner *v1alpha1.Submariner, endpoint *subv1.Endpoint) (i
 func getGatewayIP(remoteCfg *rest.Config, localClusterID string) string {
 	gateways := getGatewaysResource(remoteCfg)
 	if gateways == nil {
-		status.QueueWarningMessage("There are no gateways detected on the remote cluster.")
+		status.QueueWarningMessageWithCode(codeNoGateway, "There are no gateways detected on the remote cluster.")
 		return ""
 	}
 
@@ -206,7 +210,7 @@ func validateSnifferPodOutput(podOutput, clientMessage, hostname string, tunnelP
 		message := fmt.Sprintf("The tcpdump output from the sniffer pod does not include the message"+
 			" sent from client pod. Please check that your firewall configuration allows UDP/%d traffic"+
 			" on the %q node.", tunnelPort, hostname)
-		status.QueueFailureMessage(message)
+		status.QueueFailureMessageWithCode(codeTunnelTrafficBlocked, message)
 		return false
 	}
 
@@ -0,0 +1,144 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+	"github.com/submariner-io/submariner-operator/pkg/names"
+)
+
+// The number of minor versions the Submariner deployments in a clusterset may differ by
+const supportedMinorVersionSkew = 1
+
+// clusterVersions records the Submariner and operator versions deployed in a cluster
+type clusterVersions struct {
+	clusterName       string
+	submarinerVersion string
+	operatorVersion   string
+}
+
+var validateVersionSkewCmd = &cobra.Command{
+	Use:   "version-skew",
+	Short: "Check the Submariner version skew across the clusters",
+	Long: fmt.Sprintf("This command collects the Submariner versions deployed in the clusters and checks that they"+
+		" don't differ by more than %d minor version.", supportedMinorVersionSkew),
+	Run: validateVersionSkew,
+}
+
+func init() {
+	validateCmd.AddCommand(validateVersionSkewCmd)
+}
+
+func validateVersionSkew(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	var deployed []clusterVersions
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving the Submariner versions from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		versions := clusterVersions{clusterName: item.clusterName, submarinerVersion: submariner.Spec.Version,
+			operatorVersion: "unknown"}
+
+		clientSet, err := kubernetes.NewForConfig(item.config)
+		if err == nil {
+			var operatorVersions []versionImageInfo
+			operatorVersions, err = getOperatorVersion(clientSet, operatorVersions)
+			if err == nil {
+				versions.operatorVersion = operatorVersions[0].version
+			}
+		}
+
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeComponentVersionUnknown, fmt.Sprintf("Unable to determine the version of the"+
+				" %s: %s", names.OperatorComponent, err))
+		}
+
+		deployed = append(deployed, versions)
+		status.End(status.ResultFromMessages())
+	}
+
+	status.SetCluster("")
+
+	finishValidation(checkVersionSkew(deployed) && validationStatus)
+}
+
+func checkVersionSkew(deployed []clusterVersions) bool {
+	status.Start("Checking the Submariner version skew across the clusters")
+
+	var oldest, newest *semver.Version
+
+	for _, versions := range deployed {
+		version, err := semver.NewVersion(versions.submarinerVersion)
+		if err != nil {
+			status.QueueWarningMessageWithCode(codeComponentVersionUnknown, fmt.Sprintf("The Submariner version %q of cluster"+
+				" %q can't be compared: %s",
+				versions.submarinerVersion, versions.clusterName, err))
+			continue
+		}
+
+		if oldest == nil || version.LessThan(*oldest) {
+			oldest = version
+		}
+
+		if newest == nil || newest.LessThan(*version) {
+			newest = version
+		}
+	}
+
+	if oldest != nil && (oldest.Major != newest.Major || newest.Minor-oldest.Minor > supportedMinorVersionSkew) {
+		status.QueueWarningMessageWithCode(codeVersionSkew,
+			fmt.Sprintf("The Submariner versions range from %s to %s, beyond the supported skew"+
+				" of %d minor version; upgrade the oldest clusters first", oldest, newest, supportedMinorVersionSkew))
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage("The Submariner versions are within the supported skew")
+	}
+
+	status.End(result)
+
+	printClusterVersions(deployed)
+
+	return true
+}
+
+func printClusterVersions(deployed []clusterVersions) {
+	template := "%-32.31s%-24.23s%-24.23s\n"
+	diagnosePrintf(template, "CLUSTER", "SUBMARINER VERSION", "OPERATOR VERSION")
+
+	for _, versions := range deployed {
+		diagnosePrintf(template, versions.clusterName, versions.submarinerVersion, versions.operatorVersion)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+var validateWebhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Check the Submariner admission webhooks",
+	Long: "This command checks the validating and mutating webhooks served from the Submariner namespace or" +
+		" intercepting Submariner resources: their service must exist and have ready endpoints, and their CA bundle" +
+		" must hold valid certificates. A broken webhook with a Fail policy rejects the changes to the resources it" +
+		" intercepts.",
+	Run: validateWebhooks,
+}
+
+func init() {
+	addCertificateExpiryFlag(validateWebhooksCmd)
+	validateCmd.AddCommand(validateWebhooksCmd)
+}
+
+func validateWebhooks(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateWebhooksInCluster(clients, item.clusterName) && validationStatus
+	}
+
+	status.SetCluster("")
+
+	finishValidation(validationStatus)
+}
+
+func validateWebhooksInCluster(clients *clusterClients, clusterName string) bool {
+	status.Start(fmt.Sprintf("Checking the Submariner admission webhooks in cluster %q", clusterName))
+
+	clientSet := clients.kubeClient
+
+	validating, err := clientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the validating webhook configurations: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	mutating, err := clientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.TODO(),
+		metav1.ListOptions{})
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the mutating webhook configurations: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	found := 0
+
+	for i := range validating.Items {
+		for j := range validating.Items[i].Webhooks {
+			webhook := &validating.Items[i].Webhooks[j]
+			if isSubmarinerWebhook(&webhook.ClientConfig, webhook.Rules) {
+				found++
+				checkWebhook(clientSet, fmt.Sprintf("validating webhook %q", webhook.Name), &webhook.ClientConfig,
+					webhook.FailurePolicy)
+			}
+		}
+	}
+
+	for i := range mutating.Items {
+		for j := range mutating.Items[i].Webhooks {
+			webhook := &mutating.Items[i].Webhooks[j]
+			if isSubmarinerWebhook(&webhook.ClientConfig, webhook.Rules) {
+				found++
+				checkWebhook(clientSet, fmt.Sprintf("mutating webhook %q", webhook.Name), &webhook.ClientConfig,
+					webhook.FailurePolicy)
+			}
+		}
+	}
+
+	if found == 0 {
+		status.QueueSuccessMessage("No Submariner admission webhooks are configured")
+		status.End(cli.Success)
+		return true
+	}
+
+	result := status.ResultFromMessages()
+	if result == cli.Success {
+		status.QueueSuccessMessage(fmt.Sprintf("The %d Submariner admission webhooks are served by ready services", found))
+	}
+
+	status.End(result)
+
+	return result != cli.Failure
+}
+
+// isSubmarinerWebhook returns true if the webhook is served from the Submariner namespace or intercepts Submariner
+// resources
+func isSubmarinerWebhook(clientConfig *admissionv1.WebhookClientConfig, rules []admissionv1.RuleWithOperations) bool {
+	if clientConfig.Service != nil && clientConfig.Service.Namespace == OperatorNamespace {
+		return true
+	}
+
+	for i := range rules {
+		for _, group := range rules[i].APIGroups {
+			if strings.HasSuffix(group, "submariner.io") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checkWebhook checks that the webhook's service has ready endpoints and that its CA bundle holds valid certificates.
+// The problems are failures if the webhook's failure policy rejects the requests, warnings otherwise.
+func checkWebhook(clientSet kubernetes.Interface, webhook string, clientConfig *admissionv1.WebhookClientConfig,
+	failurePolicy *admissionv1.FailurePolicyType) {
+	queueProblem := status.QueueWarningMessageWithCode
+	if failurePolicy == nil || *failurePolicy == admissionv1.Fail {
+		queueProblem = status.QueueFailureMessageWithCode
+	}
+
+	if len(clientConfig.CABundle) == 0 {
+		queueProblem(codeWebhookCABundleMissing, fmt.Sprintf("The %s has no CA bundle", webhook))
+	} else {
+		checkCertificatesExpiry(fmt.Sprintf("the CA bundle of the %s", webhook), clientConfig.CABundle, time.Now())
+	}
+
+	service := clientConfig.Service
+	if service == nil {
+		// Webhooks served from a URL can't be checked from the cluster
+		return
+	}
+
+	_, err := clientSet.CoreV1().Services(service.Namespace).Get(context.TODO(), service.Name, metav1.GetOptions{})
+	if err != nil {
+		queueProblem(codeWebhookServiceMissing, fmt.Sprintf("The service %s/%s of the %s is unavailable: %s", service.Namespace, service.Name,
+			webhook, err))
+		return
+	}
+
+	endpoints, err := clientSet.CoreV1().Endpoints(service.Namespace).Get(context.TODO(), service.Name, metav1.GetOptions{})
+	if err != nil {
+		queueProblem(codeWebhookEndpointsUnavailable, fmt.Sprintf("Error obtaining the endpoints of the service %s/%s of"+
+			" the %s: %s", service.Namespace, service.Name, webhook, err))
+		return
+	}
+
+	for i := range endpoints.Subsets {
+		if len(endpoints.Subsets[i].Addresses) > 0 {
+			return
+		}
+	}
+
+	queueProblem(codeWebhookServiceNotReady, fmt.Sprintf("The service %s/%s of the %s has no ready endpoints",
+		service.Namespace, service.Name, webhook))
+}
@@ -0,0 +1,181 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	subv1 "github.com/submariner-io/submariner/pkg/apis/submariner.io/v1"
+	smClientset "github.com/submariner-io/submariner/pkg/client/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/submariner-io/submariner-operator/apis/submariner/v1alpha1"
+	"github.com/submariner-io/submariner-operator/pkg/internal/cli"
+)
+
+const (
+	wireGuardCableDriver = "wireguard"
+	// The endpoint backend config entry holding the WireGuard public key, as set by the WireGuard cable driver
+	wireGuardPublicKey = "publicKey"
+)
+
+var validateWireGuardCmd = &cobra.Command{
+	Use:   "wireguard",
+	Short: "Check the WireGuard public keys advertised by the Endpoints",
+	Long: "This command checks that every Endpoint advertises a WireGuard public key and, when given several" +
+		" kubecontexts, that all the clusters agree on each Endpoint's key.",
+	Run: validateWireGuard,
+}
+
+func init() {
+	validateCmd.AddCommand(validateWireGuardCmd)
+}
+
+func validateWireGuard(cmd *cobra.Command, args []string) {
+	configs, err := getMultipleRestConfigs(kubeConfig, kubeContexts)
+	exitOnError("Error getting REST config for cluster", err)
+
+	validationStatus := true
+	endpointsByCluster := map[string][]subv1.Endpoint{}
+	localClusterIDs := map[string]string{}
+
+	for _, item := range configs {
+		status.SetCluster(item.clusterName)
+		status.Start(fmt.Sprintf("Retrieving Submariner resource from %q", item.clusterName))
+		submariner, err := retrieveSubmariner(item.config)
+		if submariner == nil {
+			validationStatus = reportUnavailableSubmariner(err) && validationStatus
+			continue
+		}
+
+		status.End(cli.Success)
+
+		clients := newCheckClients(item.clusterName, item.config, newClusterClients)
+		if clients == nil {
+			validationStatus = false
+			continue
+		}
+
+		validationStatus = validateWireGuardKeysInCluster(clients, item.clusterName, submariner) && validationStatus
+
+		if submariner.Spec.CableDriver != wireGuardCableDriver {
+			continue
+		}
+
+		endpoints, err := listEndpoints(clients.submarinerClient)
+		if err == nil {
+			endpointsByCluster[item.clusterName] = endpoints
+			localClusterIDs[item.clusterName] = submariner.Spec.ClusterID
+		}
+	}
+
+	status.SetCluster("")
+
+	if len(endpointsByCluster) > 1 {
+		validationStatus = validateWireGuardKeysAcrossClusters(endpointsByCluster, localClusterIDs) && validationStatus
+	}
+
+	finishValidation(validationStatus)
+}
+
+func validateWireGuardKeysInCluster(clients *clusterClients, clusterName string, submariner *v1alpha1.Submariner) bool {
+	status.Start(fmt.Sprintf("Checking the WireGuard public keys of the Endpoints in cluster %q", clusterName))
+
+	if submariner.Spec.CableDriver != wireGuardCableDriver {
+		status.QueueSuccessMessage(fmt.Sprintf("This check is only necessary for the %q cable driver", wireGuardCableDriver))
+		status.End(cli.Success)
+		return true
+	}
+
+	endpoints, err := listEndpoints(clients.submarinerClient)
+	if err != nil {
+		status.QueueFailureMessageWithCode(codeAPIListFailed, fmt.Sprintf("Error listing the Submariner endpoints: %s", err))
+		status.End(cli.Failure)
+		return false
+	}
+
+	for i := range endpoints {
+		if endpoints[i].Spec.BackendConfig[wireGuardPublicKey] == "" {
+			status.QueueFailureMessageWithCode(codeWireGuardKeyMissing, fmt.Sprintf("The Endpoint %q for cluster %q has no"+
+				" WireGuard public key",
+				endpoints[i].Name, endpoints[i].Spec.ClusterID))
+		}
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage("All the Endpoints advertise a WireGuard public key")
+	status.End(cli.Success)
+	return true
+}
+
+// validateWireGuardKeysAcrossClusters checks that the copies of each Endpoint seen in the other clusters carry the
+// public key advertised by the cluster owning the Endpoint
+func validateWireGuardKeysAcrossClusters(endpointsByCluster map[string][]subv1.Endpoint, localClusterIDs map[string]string) bool {
+	status.Start("Checking that the WireGuard public keys are consistent across the clusters")
+
+	advertisedKeys := map[string]string{}
+
+	for clusterName, endpoints := range endpointsByCluster {
+		for i := range endpoints {
+			if endpoints[i].Spec.ClusterID == localClusterIDs[clusterName] {
+				advertisedKeys[endpoints[i].Spec.CableName] = endpoints[i].Spec.BackendConfig[wireGuardPublicKey]
+			}
+		}
+	}
+
+	for clusterName, endpoints := range endpointsByCluster {
+		for i := range endpoints {
+			endpoint := &endpoints[i]
+			advertised, found := advertisedKeys[endpoint.Spec.CableName]
+
+			if !found || endpoint.Spec.ClusterID == localClusterIDs[clusterName] {
+				continue
+			}
+
+			if key := endpoint.Spec.BackendConfig[wireGuardPublicKey]; key != advertised {
+				status.QueueFailureMessageWithCode(codeWireGuardKeyMismatch, fmt.Sprintf("Cluster %q has the WireGuard public"+
+					" key %q for the Endpoint of cluster %q, which advertises %q", clusterName, key, endpoint.Spec.ClusterID,
+					advertised))
+			}
+		}
+	}
+
+	if status.HasFailureMessages() {
+		status.End(cli.Failure)
+		return false
+	}
+
+	status.QueueSuccessMessage("The clusters agree on the WireGuard public keys")
+	status.End(cli.Success)
+	return true
+}
+
+func listEndpoints(submarinerClient smClientset.Interface) ([]subv1.Endpoint, error) {
+	endpoints, err := submarinerClient.SubmarinerV1().Endpoints(OperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoints.Items, nil
+}
@@ -0,0 +1,44 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/submariner-io/submariner-operator/pkg/broker"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/common/operatorpod"
+)
+
+var (
+	waitInterval           time.Duration
+	operatorWaitTimeout    time.Duration
+	brokerTokenWaitTimeout time.Duration
+)
+
+// addWaitFlags adds the flags controlling how often subctl checks the resources it waits for, and how long it waits
+func addWaitFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.DurationVar(&waitInterval, "wait-interval", operatorpod.DefaultDeploymentWaitInterval,
+		"interval between the checks of the resources being waited for")
+	flags.DurationVar(&operatorWaitTimeout, "operator-wait-timeout", operatorpod.DefaultDeploymentWaitTimeout,
+		"maximum time to wait for the operator deployment to be ready")
+	flags.DurationVar(&brokerTokenWaitTimeout, "broker-token-wait-timeout", broker.DefaultClientTokenWaitTimeout,
+		"maximum time to wait for the token of the broker service account to be ready")
+}
@@ -130,18 +130,28 @@ func newFromCluster(clientSet clientset.Interface, brokerNamespace, ipsecSubmFil
 		return nil, err
 	}
 
-	if ipsecSubmFile != "" {
-		datafile, err := NewFromFile(ipsecSubmFile)
-		if err != nil {
-			return nil, fmt.Errorf("error happened trying to import IPsec PSK from subm file: %s: %s", ipsecSubmFile,
-				err.Error())
-		}
-		subctlData.IPSecPSK = datafile.IPSecPSK
-		return subctlData, err
-	} else {
-		subctlData.IPSecPSK, err = newIPSECPSKSecret()
-		return subctlData, err
+	subctlData.IPSecPSK, err = NewIPSecPSKSecret(ipsecSubmFile)
+	if err != nil {
+		return nil, err
 	}
+
+	return subctlData, nil
+}
+
+// NewIPSecPSKSecret returns the IPsec PSK secret imported from the given broker information file, or a newly
+// generated one if no file is given
+func NewIPSecPSKSecret(ipsecSubmFile string) (*v1.Secret, error) {
+	if ipsecSubmFile == "" {
+		return newIPSECPSKSecret()
+	}
+
+	datafile, err := NewFromFile(ipsecSubmFile)
+	if err != nil {
+		return nil, fmt.Errorf("error happened trying to import IPsec PSK from subm file: %s: %s", ipsecSubmFile,
+			err.Error())
+	}
+
+	return datafile.IPSecPSK, nil
 }
 
 func (data *SubctlData) GetBrokerAdministratorConfig() (*rest.Config, error) {
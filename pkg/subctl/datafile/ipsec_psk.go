@@ -21,6 +21,7 @@ package datafile
 import (
 	"context"
 	"crypto/rand"
+	"io"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,15 +31,19 @@ import (
 const ipsecPSKSecretName = "submariner-ipsec-psk"
 const ipsecSecretLength = 48
 
-// generateRandomPSK returns securely generated n-byte array.
-func generateRandomPSK(n int) ([]byte, error) {
+// PSKEntropySource is the source of the random bytes used for the IPsec PSK, crypto/rand by default.
+// It can be replaced, e.g. to source the entropy from an HSM.
+var PSKEntropySource io.Reader = rand.Reader
+
+// generateRandomPSK returns an n-byte array read from the given entropy source.
+func generateRandomPSK(n int, entropy io.Reader) ([]byte, error) {
 	psk := make([]byte, n)
-	_, err := rand.Read(psk)
+	_, err := io.ReadFull(entropy, psk)
 	return psk, err
 }
 
 func newIPSECPSKSecret() (*v1.Secret, error) {
-	psk, err := generateRandomPSK(ipsecSecretLength)
+	psk, err := generateRandomPSK(ipsecSecretLength, PSKEntropySource)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +61,17 @@ func newIPSECPSKSecret() (*v1.Secret, error) {
 	return pskSecret, nil
 }
 
+// NewIPSecPSKSecretPlaceholder returns the IPsec PSK secret with the given placeholder instead of its key, to describe
+// the secret without disclosing any key material
+func NewIPSecPSKSecretPlaceholder(placeholder string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ipsecPSKSecretName,
+		},
+		StringData: map[string]string{"psk": placeholder},
+	}
+}
+
 func GetIPSECPSKSecret(clientSet clientset.Interface, namespace string) (*v1.Secret, error) {
 	return clientSet.CoreV1().Secrets(namespace).Get(context.TODO(), ipsecPSKSecretName, metav1.GetOptions{})
 }
@@ -19,6 +19,10 @@ limitations under the License.
 package datafile
 
 import (
+	"bytes"
+	"crypto/rand"
+	"io"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -26,10 +30,22 @@ import (
 var _ = Describe("ipsec_psk handling", func() {
 	When("generateRandonPSK is called", func() {
 		It("should return the amount of entropy requested", func() {
-			psk, err := generateRandomPSK(ipsecSecretLength)
+			psk, err := generateRandomPSK(ipsecSecretLength, rand.Reader)
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(psk).To(HaveLen(ipsecSecretLength))
 		})
+
+		It("should return the bytes read from the entropy source", func() {
+			entropy := bytes.Repeat([]byte{0x5a}, ipsecSecretLength)
+			psk, err := generateRandomPSK(ipsecSecretLength, bytes.NewReader(entropy))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(psk).To(Equal(entropy))
+		})
+
+		It("should fail when the entropy source runs out", func() {
+			_, err := generateRandomPSK(ipsecSecretLength, bytes.NewReader(make([]byte, ipsecSecretLength-1)))
+			Expect(err).Should(HaveOccurred())
+		})
 	})
 
 	When("NewBrokerPSKSecret is called", func() {
@@ -40,6 +56,17 @@ var _ = Describe("ipsec_psk handling", func() {
 			Expect(secret.Data).To(HaveKey("psk"))
 			Expect(secret.Data["psk"]).To(HaveLen(ipsecSecretLength))
 		})
+
+		It("should use the configured entropy source", func() {
+			defer func(source io.Reader) { PSKEntropySource = source }(PSKEntropySource)
+
+			entropy := bytes.Repeat([]byte{0xa5}, ipsecSecretLength)
+			PSKEntropySource = bytes.NewReader(entropy)
+
+			secret, err := newIPSECPSKSecret()
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(secret.Data["psk"]).To(Equal(entropy))
+		})
 	})
 
 })
@@ -35,13 +35,23 @@ const (
 	BrokerName = "submariner-broker"
 )
 
-func Ensure(config *rest.Config, namespace string, brokerSpec submariner.BrokerSpec) error {
-	brokerCR := &submariner.Broker{
+// New returns the Broker resource for the given spec
+func New(namespace string, brokerSpec submariner.BrokerSpec) *submariner.Broker {
+	return &submariner.Broker{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: submariner.SchemeGroupVersion.String(),
+			Kind:       "Broker",
+		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: BrokerName,
+			Name:      BrokerName,
+			Namespace: namespace,
 		},
 		Spec: brokerSpec,
 	}
+}
+
+func Ensure(config *rest.Config, namespace string, brokerSpec submariner.BrokerSpec) error {
+	brokerCR := New(namespace, brokerSpec)
 
 	client, err := submarinerClientset.NewForConfig(config)
 	if err != nil {
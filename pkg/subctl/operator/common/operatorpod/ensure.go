@@ -33,16 +33,35 @@ import (
 	"github.com/submariner-io/submariner-operator/pkg/utils"
 )
 
-const deploymentCheckInterval = 5 * time.Second
-const deploymentWaitTime = 10 * time.Minute
+// Default interval between the checks of the operator deployment, and time to wait for it to be ready
+const (
+	DefaultDeploymentWaitInterval = 5 * time.Second
+	DefaultDeploymentWaitTimeout  = 10 * time.Minute
+)
 
-// Ensure the operator is deployed, and running
-func Ensure(restConfig *rest.Config, namespace, operatorName, image string, debug bool) (bool, error) {
+// Ensure the operator is deployed, and running; its deployment is checked every waitInterval until it is ready or
+// waitTimeout expires
+func Ensure(restConfig *rest.Config, namespace, operatorName, image string, debug bool, waitInterval,
+	waitTimeout time.Duration) (bool, error) {
 	clientSet, err := clientset.NewForConfig(restConfig)
 	if err != nil {
 		return false, err
 	}
 
+	deployment := NewDeployment(namespace, operatorName, image, debug)
+
+	created, err := utils.CreateOrUpdateDeployment(context.TODO(), clientSet, namespace, deployment)
+	if err != nil {
+		return false, err
+	}
+
+	err = deployments.WaitForReady(clientSet, namespace, deployment.Name, waitInterval, waitTimeout)
+
+	return created, err
+}
+
+// NewDeployment returns the deployment of the operator which Ensure creates or updates
+func NewDeployment(namespace, operatorName, image string, debug bool) *appsv1.Deployment {
 	replicas := int32(1)
 	imagePullPolicy := v1.PullAlways
 	// If we are running with a local development image, don't try to pull from registry
@@ -57,7 +76,7 @@ func Ensure(restConfig *rest.Config, namespace, operatorName, image string, debu
 		command = append(command, "-v=1")
 	}
 
-	deployment := &appsv1.Deployment{
+	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
 			Name:      operatorName,
@@ -100,13 +119,4 @@ func Ensure(restConfig *rest.Config, namespace, operatorName, image string, debu
 			},
 		},
 	}
-
-	created, err := utils.CreateOrUpdateDeployment(context.TODO(), clientSet, namespace, deployment)
-	if err != nil {
-		return false, err
-	}
-
-	err = deployments.WaitForReady(clientSet, namespace, deployment.Name, deploymentCheckInterval, deploymentWaitTime)
-
-	return created, err
 }
@@ -26,6 +26,25 @@ import (
 	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/common/embeddedyamls"
 )
 
+// The embedded YAMLs of the Lighthouse components' service accounts and cluster roles, in the order they are created
+// by Ensure
+var (
+	ServiceAccounts = []string{
+		embeddedyamls.Config_rbac_lighthouse_agent_service_account_yaml,
+		embeddedyamls.Config_rbac_lighthouse_coredns_service_account_yaml,
+	}
+
+	ClusterRoles = []string{
+		embeddedyamls.Config_rbac_lighthouse_agent_cluster_role_yaml,
+		embeddedyamls.Config_rbac_lighthouse_coredns_cluster_role_yaml,
+	}
+
+	ClusterRoleBindings = []string{
+		embeddedyamls.Config_rbac_lighthouse_agent_cluster_role_binding_yaml,
+		embeddedyamls.Config_rbac_lighthouse_coredns_cluster_role_binding_yaml,
+	}
+)
+
 // Ensure functions updates or installs the operator CRDs in the cluster
 func Ensure(restConfig *rest.Config, namespace string) (bool, error) {
 	clientSet, err := clientset.NewForConfig(restConfig)
@@ -52,48 +71,46 @@ func Ensure(restConfig *rest.Config, namespace string) (bool, error) {
 }
 
 func ensureServiceAccounts(clientSet *clientset.Clientset, namespace string) (bool, error) {
-	createdAgentSA, err := serviceaccount.Ensure(clientSet, namespace,
-		embeddedyamls.Config_rbac_lighthouse_agent_service_account_yaml)
-	if err != nil {
-		return false, err
-	}
+	created := false
 
-	createdCoreDNSSA, err := serviceaccount.Ensure(clientSet, namespace,
-		embeddedyamls.Config_rbac_lighthouse_coredns_service_account_yaml)
-	if err != nil {
-		return false, err
+	for _, yaml := range ServiceAccounts {
+		createdSA, err := serviceaccount.Ensure(clientSet, namespace, yaml)
+		if err != nil {
+			return false, err
+		}
+
+		created = created || createdSA
 	}
-	return createdAgentSA || createdCoreDNSSA, err
+
+	return created, nil
 }
 
 func ensureClusterRoles(clientSet *clientset.Clientset) (bool, error) {
-	createdAgentCR, err := serviceaccount.EnsureClusterRole(clientSet,
-		embeddedyamls.Config_rbac_lighthouse_agent_cluster_role_yaml)
-	if err != nil {
-		return false, err
-	}
+	created := false
 
-	createdCoreDNSCR, err := serviceaccount.EnsureClusterRole(clientSet,
-		embeddedyamls.Config_rbac_lighthouse_coredns_cluster_role_yaml)
-	if err != nil {
-		return false, err
+	for _, yaml := range ClusterRoles {
+		createdCR, err := serviceaccount.EnsureClusterRole(clientSet, yaml)
+		if err != nil {
+			return false, err
+		}
+
+		created = created || createdCR
 	}
 
-	return createdAgentCR || createdCoreDNSCR, err
+	return created, nil
 }
 
 func ensureClusterRoleBindings(clientSet *clientset.Clientset, namespace string) (bool, error) {
-	createdAgentCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_lighthouse_agent_cluster_role_binding_yaml)
-	if err != nil {
-		return false, err
-	}
+	created := false
 
-	createdCoreDNSCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_lighthouse_coredns_cluster_role_binding_yaml)
-	if err != nil {
-		return false, err
+	for _, yaml := range ClusterRoleBindings {
+		createdCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace, yaml)
+		if err != nil {
+			return false, err
+		}
+
+		created = created || createdCRB
 	}
 
-	return createdAgentCRB || createdCoreDNSCRB, err
+	return created, nil
 }
@@ -15,7 +15,6 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
-
 package crds
 
 import (
@@ -28,6 +27,13 @@ import (
 	crdutils "github.com/submariner-io/submariner-operator/pkg/utils/crds"
 )
 
+// CRDs are the embedded YAMLs of the operator CRDs, in the order they are created by Ensure
+var CRDs = []string{
+	embeddedyamls.Deploy_crds_submariner_io_submariners_yaml,
+	embeddedyamls.Deploy_crds_submariner_io_servicediscoveries_yaml,
+	embeddedyamls.Deploy_crds_submariner_io_brokers_yaml,
+}
+
 // Ensure functions updates or installs the operator CRDs in the cluster
 func Ensure(restConfig *rest.Config) (bool, error) {
 	crdUpdater, err := crdutils.NewFromRestConfig(restConfig)
@@ -38,17 +44,16 @@ func Ensure(restConfig *rest.Config) (bool, error) {
 	// Attempt to update or create the CRD definitions
 	// TODO(majopela): In the future we may want to report when we have updated the existing
 	//                 CRD definition with new versions
-	submarinerCreated, err := utils.CreateOrUpdateEmbeddedCRD(context.TODO(), crdUpdater,
-		embeddedyamls.Deploy_crds_submariner_io_submariners_yaml)
-	if err != nil {
-		return false, err
-	}
-	serviceDiscoveryCreated, err := utils.CreateOrUpdateEmbeddedCRD(context.TODO(), crdUpdater,
-		embeddedyamls.Deploy_crds_submariner_io_servicediscoveries_yaml)
-	if err != nil {
-		return false, err
+	created := false
+
+	for _, crd := range CRDs {
+		createdCRD, err := utils.CreateOrUpdateEmbeddedCRD(context.TODO(), crdUpdater, crd)
+		if err != nil {
+			return false, err
+		}
+
+		created = created || createdCRD
 	}
-	brokerCreated, err := utils.CreateOrUpdateEmbeddedCRD(context.TODO(), crdUpdater,
-		embeddedyamls.Deploy_crds_submariner_io_brokers_yaml)
-	return submarinerCreated || serviceDiscoveryCreated || brokerCreated, err
+
+	return created, nil
 }
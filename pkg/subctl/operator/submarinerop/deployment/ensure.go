@@ -19,12 +19,14 @@ limitations under the License.
 package deployment
 
 import (
+	"time"
+
 	"github.com/submariner-io/submariner-operator/pkg/names"
 	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/common/operatorpod"
 	"k8s.io/client-go/rest"
 )
 
 // Ensure the operator is deployed, and running
-func Ensure(restConfig *rest.Config, namespace, image string, debug bool) (bool, error) {
-	return operatorpod.Ensure(restConfig, namespace, names.OperatorComponent, image, debug)
+func Ensure(restConfig *rest.Config, namespace, image string, debug bool, waitInterval, waitTimeout time.Duration) (bool, error) {
+	return operatorpod.Ensure(restConfig, namespace, names.OperatorComponent, image, debug, waitInterval, waitTimeout)
 }
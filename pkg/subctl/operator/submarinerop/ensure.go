@@ -20,6 +20,7 @@ package submarinerop
 
 import (
 	"fmt"
+	"time"
 
 	"k8s.io/client-go/rest"
 
@@ -32,7 +33,10 @@ import (
 	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/submarinerop/serviceaccount"
 )
 
-func Ensure(status *cli.Status, config *rest.Config, operatorNamespace, operatorImage string, debug bool) error {
+// Ensure deploys the operator and its requirements, then waits for the operator to be ready, checking it every
+// waitInterval until waitTimeout expires
+func Ensure(status *cli.Status, config *rest.Config, operatorNamespace, operatorImage string, debug bool, waitInterval,
+	waitTimeout time.Duration) error {
 	if created, err := crds.Ensure(config); err != nil {
 		return err
 	} else if created {
@@ -63,7 +67,7 @@ func Ensure(status *cli.Status, config *rest.Config, operatorNamespace, operator
 		status.QueueSuccessMessage("Created Lighthouse service accounts and roles")
 	}
 
-	if created, err := deployment.Ensure(config, operatorNamespace, operatorImage, debug); err != nil {
+	if created, err := deployment.Ensure(config, operatorNamespace, operatorImage, debug, waitInterval, waitTimeout); err != nil {
 		return err
 	} else if created {
 		status.QueueSuccessMessage("Deployed the operator successfully")
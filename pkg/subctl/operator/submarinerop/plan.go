@@ -0,0 +1,128 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package submarinerop
+
+import (
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/submariner-io/submariner-operator/pkg/names"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/common/embeddedyamls"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/common/operatorpod"
+	lighthousesa "github.com/submariner-io/submariner-operator/pkg/subctl/operator/lighthouse/serviceaccount"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/submarinerop/crds"
+	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/submarinerop/serviceaccount"
+)
+
+// Plan returns the resources which Ensure creates or updates to deploy the operator, without creating them. The
+// objects have their type and namespace set so they can be serialized and applied as-is. The privileged SCC, which
+// Ensure only updates on OpenShift, isn't included.
+func Plan(operatorNamespace, operatorImage string, debug bool) ([]runtime.Object, error) {
+	objs, err := appendEmbedded(nil, crds.CRDs, "", func() runtime.Object {
+		return &apiextensions.CustomResourceDefinition{}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The CRD type isn't registered in the client scheme
+	for _, obj := range objs {
+		obj.GetObjectKind().SetGroupVersionKind(apiextensions.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+	}
+
+	objs = append(objs, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: operatorNamespace}})
+
+	embedded := []struct {
+		yamls      []string
+		namespaced bool
+		newObj     func() runtime.Object
+	}{
+		{serviceaccount.ServiceAccounts, true, func() runtime.Object { return &v1.ServiceAccount{} }},
+		{serviceaccount.Roles, true, func() runtime.Object { return &rbacv1.Role{} }},
+		{serviceaccount.RoleBindings, true, func() runtime.Object { return &rbacv1.RoleBinding{} }},
+		{serviceaccount.ClusterRoles, false, func() runtime.Object { return &rbacv1.ClusterRole{} }},
+		{serviceaccount.ClusterRoleBindings, false, func() runtime.Object { return &rbacv1.ClusterRoleBinding{} }},
+		{lighthousesa.ServiceAccounts, true, func() runtime.Object { return &v1.ServiceAccount{} }},
+		{lighthousesa.ClusterRoles, false, func() runtime.Object { return &rbacv1.ClusterRole{} }},
+		{lighthousesa.ClusterRoleBindings, false, func() runtime.Object { return &rbacv1.ClusterRoleBinding{} }},
+	}
+
+	for i := range embedded {
+		namespace := ""
+		if embedded[i].namespaced {
+			namespace = operatorNamespace
+		}
+
+		objs, err = appendEmbedded(objs, embedded[i].yamls, namespace, embedded[i].newObj)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	objs = append(objs, operatorpod.NewDeployment(operatorNamespace, names.OperatorComponent, operatorImage, debug))
+
+	for _, obj := range objs {
+		// The service accounts bound cluster-wide are those in the operator namespace, as set by Ensure
+		if clusterRoleBinding, ok := obj.(*rbacv1.ClusterRoleBinding); ok {
+			clusterRoleBinding.Subjects[0].Namespace = operatorNamespace
+		}
+
+		// The embedded YAMLs specify their type
+		if !obj.GetObjectKind().GroupVersionKind().Empty() {
+			continue
+		}
+
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+
+	return objs, nil
+}
+
+// appendEmbedded appends the objects of the given embedded YAMLs, in the given namespace if any
+func appendEmbedded(objs []runtime.Object, yamls []string, namespace string, newObj func() runtime.Object) (
+	[]runtime.Object, error) {
+	for _, yaml := range yamls {
+		obj := newObj()
+		if err := embeddedyamls.GetObject(yaml, obj); err != nil {
+			return nil, err
+		}
+
+		if namespace != "" {
+			objMeta, err := meta.Accessor(obj)
+			if err != nil {
+				return nil, err
+			}
+
+			objMeta.SetNamespace(namespace)
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
@@ -26,6 +26,48 @@ import (
 	"github.com/submariner-io/submariner-operator/pkg/subctl/operator/common/embeddedyamls"
 )
 
+// The embedded YAMLs of the operator and Submariner components' service accounts and roles, in the order they are
+// created by Ensure
+var (
+	ServiceAccounts = []string{
+		embeddedyamls.Config_rbac_submariner_operator_service_account_yaml,
+		embeddedyamls.Config_rbac_submariner_gateway_service_account_yaml,
+		embeddedyamls.Config_rbac_submariner_route_agent_service_account_yaml,
+		embeddedyamls.Config_rbac_submariner_globalnet_service_account_yaml,
+		embeddedyamls.Config_rbac_networkplugin_syncer_service_account_yaml,
+	}
+
+	Roles = []string{
+		embeddedyamls.Config_rbac_submariner_operator_role_yaml,
+		embeddedyamls.Config_rbac_submariner_gateway_role_yaml,
+		embeddedyamls.Config_rbac_submariner_route_agent_role_yaml,
+		embeddedyamls.Config_rbac_submariner_globalnet_role_yaml,
+	}
+
+	RoleBindings = []string{
+		embeddedyamls.Config_rbac_submariner_operator_role_binding_yaml,
+		embeddedyamls.Config_rbac_submariner_gateway_role_binding_yaml,
+		embeddedyamls.Config_rbac_submariner_route_agent_role_binding_yaml,
+		embeddedyamls.Config_rbac_submariner_globalnet_role_binding_yaml,
+	}
+
+	ClusterRoles = []string{
+		embeddedyamls.Config_rbac_submariner_operator_cluster_role_yaml,
+		embeddedyamls.Config_rbac_submariner_gateway_cluster_role_yaml,
+		embeddedyamls.Config_rbac_submariner_route_agent_cluster_role_yaml,
+		embeddedyamls.Config_rbac_submariner_globalnet_cluster_role_yaml,
+		embeddedyamls.Config_rbac_networkplugin_syncer_cluster_role_yaml,
+	}
+
+	ClusterRoleBindings = []string{
+		embeddedyamls.Config_rbac_submariner_operator_cluster_role_binding_yaml,
+		embeddedyamls.Config_rbac_submariner_gateway_cluster_role_binding_yaml,
+		embeddedyamls.Config_rbac_submariner_route_agent_cluster_role_binding_yaml,
+		embeddedyamls.Config_rbac_submariner_globalnet_cluster_role_binding_yaml,
+		embeddedyamls.Config_rbac_networkplugin_syncer_cluster_role_binding_yaml,
+	}
+)
+
 // Ensure functions updates or installs the operator CRDs in the cluster
 func Ensure(restConfig *rest.Config, namespace string) (bool, error) {
 	clientSet, err := clientset.NewForConfig(restConfig)
@@ -62,147 +104,76 @@ func Ensure(restConfig *rest.Config, namespace string) (bool, error) {
 }
 
 func ensureServiceAccounts(clientSet *clientset.Clientset, namespace string) (bool, error) {
-	createdOperatorSA, err := serviceaccount.Ensure(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_operator_service_account_yaml)
-	if err != nil {
-		return false, err
-	}
-
-	createdSubmarinerSA, err := serviceaccount.Ensure(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_gateway_service_account_yaml)
-	if err != nil {
-		return false, err
-	}
+	created := false
 
-	createdRouteAgentSA, err := serviceaccount.Ensure(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_route_agent_service_account_yaml)
-	if err != nil {
-		return false, err
-	}
+	for _, yaml := range ServiceAccounts {
+		createdSA, err := serviceaccount.Ensure(clientSet, namespace, yaml)
+		if err != nil {
+			return false, err
+		}
 
-	createdGlobalnetSA, err := serviceaccount.Ensure(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_globalnet_service_account_yaml)
-	if err != nil {
-		return false, err
+		created = created || createdSA
 	}
 
-	createdNPSyncerSA, err := serviceaccount.Ensure(clientSet, namespace,
-		embeddedyamls.Config_rbac_networkplugin_syncer_service_account_yaml)
-	return createdOperatorSA || createdSubmarinerSA || createdRouteAgentSA || createdGlobalnetSA || createdNPSyncerSA, err
+	return created, nil
 }
 
 func ensureClusterRoles(clientSet *clientset.Clientset) (bool, error) {
-	createdOperatorCR, err := serviceaccount.EnsureClusterRole(clientSet,
-		embeddedyamls.Config_rbac_submariner_operator_cluster_role_yaml)
-	if err != nil {
-		return false, err
-	}
-
-	createdSubmarinerCR, err := serviceaccount.EnsureClusterRole(clientSet,
-		embeddedyamls.Config_rbac_submariner_gateway_cluster_role_yaml)
-	if err != nil {
-		return false, err
-	}
+	created := false
 
-	createdRouteAgentCR, err := serviceaccount.EnsureClusterRole(clientSet,
-		embeddedyamls.Config_rbac_submariner_route_agent_cluster_role_yaml)
-	if err != nil {
-		return false, err
-	}
+	for _, yaml := range ClusterRoles {
+		createdCR, err := serviceaccount.EnsureClusterRole(clientSet, yaml)
+		if err != nil {
+			return false, err
+		}
 
-	createdGlobalnetCR, err := serviceaccount.EnsureClusterRole(clientSet,
-		embeddedyamls.Config_rbac_submariner_globalnet_cluster_role_yaml)
-	if err != nil {
-		return false, err
+		created = created || createdCR
 	}
 
-	createdNPSyncerCR, err := serviceaccount.EnsureClusterRole(clientSet,
-		embeddedyamls.Config_rbac_networkplugin_syncer_cluster_role_yaml)
-	return createdOperatorCR || createdSubmarinerCR || createdRouteAgentCR || createdGlobalnetCR || createdNPSyncerCR, err
+	return created, nil
 }
 
 func ensureClusterRoleBindings(clientSet *clientset.Clientset, namespace string) (bool, error) {
-	createdOperatorCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_operator_cluster_role_binding_yaml)
-	if err != nil {
-		return false, err
-	}
+	created := false
 
-	createdSubmarinerCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_gateway_cluster_role_binding_yaml)
-	if err != nil {
-		return false, err
-	}
-
-	createdRouteAgentCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_route_agent_cluster_role_binding_yaml)
-	if err != nil {
-		return false, err
-	}
+	for _, yaml := range ClusterRoleBindings {
+		createdCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace, yaml)
+		if err != nil {
+			return false, err
+		}
 
-	createdGlobalnetCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_globalnet_cluster_role_binding_yaml)
-	if err != nil {
-		return false, err
+		created = created || createdCRB
 	}
 
-	createdNPSyncerCRB, err := serviceaccount.EnsureClusterRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_networkplugin_syncer_cluster_role_binding_yaml)
-	return createdOperatorCRB || createdSubmarinerCRB || createdRouteAgentCRB || createdGlobalnetCRB || createdNPSyncerCRB, err
+	return created, nil
 }
 
 func ensureRoles(clientSet *clientset.Clientset, namespace string) (bool, error) {
-	createdOperatorRole, err := serviceaccount.EnsureRole(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_operator_role_yaml)
-	if err != nil {
-		return false, err
-	}
+	created := false
 
-	createdSubmarinerRole, err := serviceaccount.EnsureRole(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_gateway_role_yaml)
-	if err != nil {
-		return false, err
-	}
+	for _, yaml := range Roles {
+		createdRole, err := serviceaccount.EnsureRole(clientSet, namespace, yaml)
+		if err != nil {
+			return false, err
+		}
 
-	createdRouteAgentRole, err := serviceaccount.EnsureRole(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_route_agent_role_yaml)
-	if err != nil {
-		return false, err
+		created = created || createdRole
 	}
 
-	createdGlobalnetRole, err := serviceaccount.EnsureRole(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_globalnet_role_yaml)
-	if err != nil {
-		return false, err
-	}
-
-	return createdOperatorRole || createdSubmarinerRole || createdRouteAgentRole || createdGlobalnetRole, err
+	return created, nil
 }
 
 func ensureRoleBindings(clientSet *clientset.Clientset, namespace string) (bool, error) {
-	createdOperatorRB, err := serviceaccount.EnsureRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_operator_role_binding_yaml)
-	if err != nil {
-		return false, err
-	}
+	created := false
 
-	createdSubmarinerRB, err := serviceaccount.EnsureRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_gateway_role_binding_yaml)
-	if err != nil {
-		return false, err
-	}
+	for _, yaml := range RoleBindings {
+		createdRB, err := serviceaccount.EnsureRoleBinding(clientSet, namespace, yaml)
+		if err != nil {
+			return false, err
+		}
 
-	createdRouteAgentRB, err := serviceaccount.EnsureRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_route_agent_role_binding_yaml)
-	if err != nil {
-		return false, err
-	}
-
-	createdGlobalnetRB, err := serviceaccount.EnsureRoleBinding(clientSet, namespace,
-		embeddedyamls.Config_rbac_submariner_globalnet_role_binding_yaml)
-	if err != nil {
-		return false, err
+		created = created || createdRB
 	}
 
-	return createdOperatorRB || createdSubmarinerRB || createdRouteAgentRB || createdGlobalnetRB, err
+	return created, nil
 }
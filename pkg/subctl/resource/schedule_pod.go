@@ -48,11 +48,15 @@ type PodScheduling struct {
 	ScheduleOn schedulingType
 	NodeName   string
 	Networking networkingType
+	// Tolerations replace the default toleration of all the taints when set
+	Tolerations []v1.Toleration
+	// NodeSelector further restricts the nodes the pod is scheduled on, unless it's scheduled on a CustomNode
+	NodeSelector map[string]string
 }
 
 type PodConfig struct {
 	Name       string
-	ClientSet  *kubernetes.Clientset
+	ClientSet  kubernetes.Interface
 	Scheduling PodScheduling
 	Namespace  string
 	Command    string
@@ -133,6 +137,10 @@ func (np *NetworkPod) schedulePod() error {
 		},
 	}
 
+	if len(np.Config.Scheduling.Tolerations) > 0 {
+		networkPod.Spec.Tolerations = np.Config.Scheduling.Tolerations
+	}
+
 	if np.Config.Scheduling.Networking == HostNetworking {
 		networkPod.Spec.Containers[0].SecurityContext = &v1.SecurityContext{
 			Capabilities: &v1.Capabilities{
@@ -146,6 +154,7 @@ func (np *NetworkPod) schedulePod() error {
 		networkPod.Spec.NodeName = np.Config.Scheduling.NodeName
 	} else {
 		networkPod.Spec.Affinity = nodeAffinity(np.Config.Scheduling.ScheduleOn)
+		networkPod.Spec.NodeSelector = np.Config.Scheduling.NodeSelector
 	}
 
 	pc := np.Config.ClientSet.CoreV1().Pods(np.Config.Namespace)
@@ -0,0 +1,106 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const defaultRolloutInterval = 2 * time.Second
+
+// RolloutWait configures how long the rollout checks wait for a workload to become ready; with a zero Timeout, the
+// workload is only checked once
+type RolloutWait struct {
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+// CheckDeploymentRollout returns nil once all the replicas of the Deployment are available, or an error describing
+// why it isn't ready when the wait times out
+func CheckDeploymentRollout(ctx context.Context, clientSet clientset.Interface, namespace, name string,
+	rolloutWait RolloutWait) error {
+	return pollRollout(rolloutWait, func() error {
+		deployment, err := clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error obtaining Deployment %q: %v", name, err)
+		}
+
+		var replicas int32 = 1
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+
+		if deployment.Status.AvailableReplicas != replicas {
+			return fmt.Errorf("the desired number of replicas for Deployment %q (%d) does not match the actual number"+
+				" running (%d)", name, replicas, deployment.Status.AvailableReplicas)
+		}
+
+		return nil
+	})
+}
+
+// CheckDaemonSetRollout returns nil once the DaemonSet's pods are scheduled on all the desired nodes, or an error
+// describing why it isn't ready when the wait times out
+func CheckDaemonSetRollout(ctx context.Context, clientSet clientset.Interface, namespace, name string,
+	rolloutWait RolloutWait) error {
+	return pollRollout(rolloutWait, func() error {
+		daemonSet, err := clientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error obtaining DaemonSet %q: %v", name, err)
+		}
+
+		if daemonSet.Status.CurrentNumberScheduled != daemonSet.Status.DesiredNumberScheduled {
+			return fmt.Errorf("the desired number of running pods for DaemonSet %q (%d) does not match the actual"+
+				" number (%d)", name, daemonSet.Status.DesiredNumberScheduled, daemonSet.Status.CurrentNumberScheduled)
+		}
+
+		return nil
+	})
+}
+
+// pollRollout runs the check until it succeeds or the wait times out, and returns the check's last error
+func pollRollout(rolloutWait RolloutWait, check func() error) error {
+	if rolloutWait.Timeout <= 0 {
+		return check()
+	}
+
+	interval := rolloutWait.Interval
+	if interval <= 0 {
+		interval = defaultRolloutInterval
+	}
+
+	var lastErr error
+
+	err := wait.PollImmediate(interval, rolloutWait.Timeout, func() (bool, error) {
+		lastErr = check()
+		return lastErr == nil, nil
+	})
+
+	if err == wait.ErrWaitTimeout && lastErr != nil {
+		return lastErr
+	}
+
+	return err
+}
@@ -0,0 +1,142 @@
+/*
+SPDX-License-Identifier: Apache-2.0
+
+Copyright Contributors to the Submariner project.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const rolloutNamespace = "submariner-operator"
+
+var rolloutWait = RolloutWait{Timeout: 2 * time.Second, Interval: 10 * time.Millisecond}
+
+// becomeReadyAfter makes the fake client report the workload as ready from the given number of gets on
+func becomeReadyAfter(client *fakeclientset.Clientset, resource string, gets int, ready func(runtime.Object)) {
+	count := 0
+
+	client.PrependReactor("get", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		count++
+		if count < gets {
+			return false, nil, nil
+		}
+
+		obj, err := client.Tracker().Get(action.GetResource(), action.GetNamespace(),
+			action.(k8stesting.GetAction).GetName())
+		if err != nil {
+			return true, nil, err
+		}
+
+		ready(obj)
+
+		return true, obj, nil
+	})
+}
+
+var _ = Describe("CheckDeploymentRollout", func() {
+	var (
+		client *fakeclientset.Clientset
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		replicas := int32(2)
+		client = fakeclientset.NewSimpleClientset(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "submariner-lighthouse-agent", Namespace: rolloutNamespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+		})
+		ctx = context.TODO()
+	})
+
+	When("not waiting", func() {
+		It("Should fail if the replicas aren't available", func() {
+			err := CheckDeploymentRollout(ctx, client, rolloutNamespace, "submariner-lighthouse-agent", RolloutWait{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not match the actual number running (1)"))
+		})
+
+		It("Should fail if the Deployment doesn't exist", func() {
+			err := CheckDeploymentRollout(ctx, client, rolloutNamespace, "missing", RolloutWait{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("waiting and the replicas eventually become available", func() {
+		It("Should succeed", func() {
+			becomeReadyAfter(client, "deployments", 3, func(obj runtime.Object) {
+				obj.(*appsv1.Deployment).Status.AvailableReplicas = 2
+			})
+
+			Expect(CheckDeploymentRollout(ctx, client, rolloutNamespace, "submariner-lighthouse-agent",
+				rolloutWait)).To(Succeed())
+		})
+	})
+
+	When("waiting and the replicas never become available", func() {
+		It("Should return the last reason once timed out", func() {
+			err := CheckDeploymentRollout(ctx, client, rolloutNamespace, "submariner-lighthouse-agent",
+				RolloutWait{Timeout: 50 * time.Millisecond, Interval: 10 * time.Millisecond})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not match the actual number running (1)"))
+		})
+	})
+})
+
+var _ = Describe("CheckDaemonSetRollout", func() {
+	var (
+		client *fakeclientset.Clientset
+		ctx    context.Context
+	)
+
+	BeforeEach(func() {
+		client = fakeclientset.NewSimpleClientset(&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "submariner-routeagent", Namespace: rolloutNamespace},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, CurrentNumberScheduled: 2},
+		})
+		ctx = context.TODO()
+	})
+
+	When("not waiting", func() {
+		It("Should fail if the pods aren't all scheduled", func() {
+			err := CheckDaemonSetRollout(ctx, client, rolloutNamespace, "submariner-routeagent", RolloutWait{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not match the actual number (2)"))
+		})
+	})
+
+	When("waiting and the pods are eventually all scheduled", func() {
+		It("Should succeed", func() {
+			becomeReadyAfter(client, "daemonsets", 3, func(obj runtime.Object) {
+				obj.(*appsv1.DaemonSet).Status.CurrentNumberScheduled = 3
+			})
+
+			Expect(CheckDaemonSetRollout(ctx, client, rolloutNamespace, "submariner-routeagent", rolloutWait)).To(Succeed())
+		})
+	})
+})